@@ -0,0 +1,140 @@
+package alert
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// State is the state of the alert of a rule.
+type State string
+
+const (
+	// Inactive alerts have a condition that does not hold.
+	Inactive State = "inactive"
+	// Pending alerts have a condition that holds, but not for as long as the
+	// rule requires yet.
+	Pending State = "pending"
+	// Firing alerts have a condition that has held for as long as the rule
+	// requires.
+	Firing State = "firing"
+	// Resolved is the state sinks are notified of when a firing alert's
+	// condition stops holding. The alert is inactive afterwards.
+	Resolved State = "resolved"
+)
+
+// Alert describes the alert of a rule, as sinks are notified of it.
+type Alert struct {
+	Rule  string            `json:"rule"`
+	Expr  string            `json:"expr"`
+	State State             `json:"state"`
+	Value float64           `json:"value"`
+	Since time.Time         `json:"since"`
+	Time  time.Time         `json:"time"`
+	Tags  map[string]string `json:"tags,omitempty"`
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Sink
+type Sink interface {
+	Notify(alerts []Alert) error
+}
+
+type ruleState struct {
+	expr  string
+	state State
+	since time.Time
+}
+
+// Engine keeps the state of the alert of every rule between cycles.
+type Engine struct {
+	sinks []Sink
+
+	mu    sync.Mutex
+	rules map[string]*ruleState
+}
+
+func NewEngine(sinks ...Sink) *Engine {
+	return &Engine{sinks: sinks, rules: make(map[string]*ruleState)}
+}
+
+// Evaluate updates the alerts of rules with the values of a cycle, keyed by
+// metric key, and notifies every sink of the alerts that became pending,
+// fired or resolved. It returns the state of the alert of every rule.
+//
+// A rule whose metric has no value in the cycle keeps its state. The state of
+// a rule that is no longer evaluated, or whose expression changed, is
+// discarded without notifying the sinks.
+func (e *Engine) Evaluate(rules []Rule, values map[string]float64, tags map[string]string, now time.Time) (map[string]State, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	states := make(map[string]State, len(rules))
+	evaluated := make(map[string]*ruleState, len(rules))
+	var notifications []Alert
+
+	for _, rule := range rules {
+		expr := rule.Condition.String()
+		current, ok := e.rules[rule.Name]
+		if !ok || current.expr != expr {
+			current = &ruleState{expr: expr, state: Inactive}
+		}
+		evaluated[rule.Name] = current
+
+		value, ok := values[rule.Condition.Key]
+		if ok {
+			if transition := current.update(rule.Condition, value, now); transition != "" {
+				notifications = append(notifications, Alert{
+					Rule:  rule.Name,
+					Expr:  expr,
+					State: transition,
+					Value: value,
+					Since: current.since,
+					Time:  now,
+					Tags:  tags,
+				})
+			}
+		}
+		states[rule.Name] = current.state
+	}
+	e.rules = evaluated
+
+	if len(notifications) == 0 {
+		return states, nil
+	}
+
+	var errs []error
+	for _, sink := range e.sinks {
+		if err := sink.Notify(notifications); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return states, errors.Join(errs...)
+}
+
+// update moves the alert to the state value puts it in, returning the state
+// sinks are to be notified of, if any. An alert whose condition stops holding
+// before it fires becomes inactive without a notification.
+func (s *ruleState) update(condition Condition, value float64, now time.Time) State {
+	if !condition.Holds(value) {
+		previous := s.state
+		s.state = Inactive
+		if previous == Firing {
+			return Resolved
+		}
+		return ""
+	}
+
+	var transition State
+	if s.state == Inactive {
+		s.state = Pending
+		s.since = now
+		transition = Pending
+	}
+	if s.state == Pending && now.Sub(s.since) >= condition.For {
+		s.state = Firing
+		transition = Firing
+	}
+
+	return transition
+}
@@ -0,0 +1,125 @@
+package alert_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/alert"
+	"github.com/cloudfoundry/mysql-metrics/alert/alertfakes"
+)
+
+var _ = Describe("Engine", func() {
+	var (
+		sink   *alertfakes.FakeSink
+		engine *alert.Engine
+		rules  []alert.Rule
+		start  time.Time
+		tags   map[string]string
+	)
+
+	rule := func(name, expr string) alert.Rule {
+		parsed, err := alert.ParseRule(name, expr)
+		Expect(err).NotTo(HaveOccurred())
+		return parsed
+	}
+
+	// evaluate evaluates the rules with value for disk usage at offset from
+	// start.
+	evaluate := func(offset time.Duration, value float64) map[string]alert.State {
+		states, err := engine.Evaluate(rules, map[string]float64{"system/persistent_disk_used_percent": value}, tags, start.Add(offset))
+		Expect(err).NotTo(HaveOccurred())
+		return states
+	}
+
+	// notified returns the states of every alert the sink was notified of.
+	notified := func() []alert.State {
+		var states []alert.State
+		for i := range sink.NotifyCallCount() {
+			for _, a := range sink.NotifyArgsForCall(i) {
+				states = append(states, a.State)
+			}
+		}
+		return states
+	}
+
+	BeforeEach(func() {
+		sink = new(alertfakes.FakeSink)
+		engine = alert.NewEngine(sink)
+		rules = []alert.Rule{rule("disk_full", "system/persistent_disk_used_percent > 90 for 5m")}
+		start = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		tags = map[string]string{"az": "z1"}
+	})
+
+	It("is inactive while the condition does not hold", func() {
+		Expect(evaluate(0, 50)).To(Equal(map[string]alert.State{"disk_full": alert.Inactive}))
+		Expect(sink.NotifyCallCount()).To(Equal(0))
+	})
+
+	It("is pending until the condition held for the duration of the rule, then fires", func() {
+		Expect(evaluate(0, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Pending}))
+		Expect(evaluate(4*time.Minute, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Pending}))
+		Expect(evaluate(5*time.Minute, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Firing}))
+		Expect(evaluate(6*time.Minute, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Firing}))
+
+		Expect(notified()).To(Equal([]alert.State{alert.Pending, alert.Firing}))
+		firing := sink.NotifyArgsForCall(1)[0]
+		Expect(firing).To(Equal(alert.Alert{
+			Rule:  "disk_full",
+			Expr:  "system/persistent_disk_used_percent > 90 for 5m0s",
+			State: alert.Firing,
+			Value: 95,
+			Since: start,
+			Time:  start.Add(5 * time.Minute),
+			Tags:  map[string]string{"az": "z1"},
+		}))
+	})
+
+	It("fires right away for rules without a duration", func() {
+		rules = []alert.Rule{rule("disk_full", "system/persistent_disk_used_percent > 90")}
+
+		Expect(evaluate(0, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Firing}))
+		Expect(notified()).To(Equal([]alert.State{alert.Firing}))
+	})
+
+	It("resolves once the condition of a firing alert stops holding", func() {
+		evaluate(0, 95)
+		evaluate(5*time.Minute, 95)
+		Expect(evaluate(6*time.Minute, 50)).To(Equal(map[string]alert.State{"disk_full": alert.Inactive}))
+
+		Expect(notified()).To(Equal([]alert.State{alert.Pending, alert.Firing, alert.Resolved}))
+	})
+
+	It("becomes inactive without a notification when a pending alert stops holding", func() {
+		evaluate(0, 95)
+		evaluate(time.Minute, 50)
+		Expect(evaluate(2*time.Minute, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Pending}))
+		Expect(evaluate(6*time.Minute, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Pending}))
+
+		Expect(notified()).To(Equal([]alert.State{alert.Pending, alert.Pending}))
+	})
+
+	It("keeps the state of rules whose metric was not emitted", func() {
+		evaluate(0, 95)
+
+		states, err := engine.Evaluate(rules, map[string]float64{}, tags, start.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(states).To(Equal(map[string]alert.State{"disk_full": alert.Pending}))
+	})
+
+	It("discards the state of rules whose expression changed", func() {
+		evaluate(0, 95)
+		rules = []alert.Rule{rule("disk_full", "system/persistent_disk_used_percent > 99 for 5m")}
+
+		Expect(evaluate(time.Minute, 95)).To(Equal(map[string]alert.State{"disk_full": alert.Inactive}))
+	})
+
+	It("returns the errors of sinks that could not be notified", func() {
+		sink.NotifyReturns(errors.New("webhook unavailable"))
+
+		_, err := engine.Evaluate(rules, map[string]float64{"system/persistent_disk_used_percent": 95}, tags, start)
+		Expect(err).To(MatchError("webhook unavailable"))
+	})
+})
@@ -0,0 +1,96 @@
+// Package alert evaluates threshold rules over the metrics of every cycle and
+// notifies sinks as the alerts of those rules become pending, fire and
+// resolve.
+package alert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Condition compares the value of a metric with a threshold. A condition with
+// a duration only fires once it has held for that long.
+type Condition struct {
+	Key       string
+	Operator  string
+	Threshold float64
+	For       time.Duration
+}
+
+var operators = map[string]func(value, threshold float64) bool{
+	">":  func(value, threshold float64) bool { return value > threshold },
+	">=": func(value, threshold float64) bool { return value >= threshold },
+	"<":  func(value, threshold float64) bool { return value < threshold },
+	"<=": func(value, threshold float64) bool { return value <= threshold },
+	"==": func(value, threshold float64) bool { return value == threshold },
+	"!=": func(value, threshold float64) bool { return value != threshold },
+}
+
+// ParseCondition parses an expression such as
+// "system/persistent_disk_used_percent > 90 for 5m": a metric key, a
+// comparison operator, a threshold and optionally "for" and a duration.
+func ParseCondition(expr string) (Condition, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 3 && len(fields) != 5 {
+		return Condition{}, fmt.Errorf("expected \"<metric> <operator> <threshold> [for <duration>]\", got %q", expr)
+	}
+
+	condition := Condition{Key: strings.Trim(fields[0], "/"), Operator: fields[1]}
+	if condition.Key == "" {
+		return Condition{}, fmt.Errorf("missing metric in %q", expr)
+	}
+	if _, ok := operators[condition.Operator]; !ok {
+		return Condition{}, fmt.Errorf("unknown operator %q in %q", condition.Operator, expr)
+	}
+
+	threshold, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return Condition{}, fmt.Errorf("invalid threshold %q in %q", fields[2], expr)
+	}
+	condition.Threshold = threshold
+
+	if len(fields) == 5 {
+		if fields[3] != "for" {
+			return Condition{}, fmt.Errorf("expected \"for\" after the threshold in %q", expr)
+		}
+		condition.For, err = time.ParseDuration(fields[4])
+		if err != nil || condition.For < 0 {
+			return Condition{}, fmt.Errorf("invalid duration %q in %q", fields[4], expr)
+		}
+	}
+
+	return condition, nil
+}
+
+// Holds reports whether value meets the condition, regardless of how long it
+// has.
+func (c Condition) Holds(value float64) bool {
+	return operators[c.Operator](value, c.Threshold)
+}
+
+func (c Condition) String() string {
+	expr := fmt.Sprintf("%s %s %s", c.Key, c.Operator, strconv.FormatFloat(c.Threshold, 'g', -1, 64))
+	if c.For > 0 {
+		expr += " for " + c.For.String()
+	}
+
+	return expr
+}
+
+// Rule is a named condition.
+type Rule struct {
+	Name      string
+	Condition Condition
+}
+
+// ParseRule parses the expression of the rule called name.
+func ParseRule(name, expr string) (Rule, error) {
+	condition, err := ParseCondition(expr)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	return Rule{Name: name, Condition: condition}, nil
+}
@@ -0,0 +1,58 @@
+package alert_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/alert"
+)
+
+var _ = Describe("ParseCondition", func() {
+	It("parses a comparison with a duration", func() {
+		condition, err := alert.ParseCondition("system/persistent_disk_used_percent > 90 for 5m")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(Equal(alert.Condition{
+			Key:       "system/persistent_disk_used_percent",
+			Operator:  ">",
+			Threshold: 90,
+			For:       5 * time.Minute,
+		}))
+		Expect(condition.String()).To(Equal("system/persistent_disk_used_percent > 90 for 5m0s"))
+	})
+
+	It("parses a comparison without a duration", func() {
+		condition, err := alert.ParseCondition("/galera/wsrep_cluster_size < 3")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(condition).To(Equal(alert.Condition{Key: "galera/wsrep_cluster_size", Operator: "<", Threshold: 3}))
+		Expect(condition.String()).To(Equal("galera/wsrep_cluster_size < 3"))
+	})
+
+	DescribeTable("evaluates every operator",
+		func(operator string, value float64, holds bool) {
+			condition, err := alert.ParseCondition("available " + operator + " 1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(condition.Holds(value)).To(Equal(holds))
+		},
+		Entry(">", ">", 2.0, true),
+		Entry(">=", ">=", 1.0, true),
+		Entry("<", "<", 1.0, false),
+		Entry("<=", "<=", 0.5, true),
+		Entry("==", "==", 1.0, true),
+		Entry("!=", "!=", 1.0, false),
+	)
+
+	DescribeTable("rejects invalid expressions",
+		func(expr, message string) {
+			_, err := alert.ParseCondition(expr)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("too few fields", "available < ", "expected"),
+		Entry("unknown operator", "available =~ 1", "unknown operator"),
+		Entry("invalid threshold", "available < one", "invalid threshold"),
+		Entry("missing for", "available < 1 during 5m", `expected "for"`),
+		Entry("invalid duration", "available < 1 for soon", "invalid duration"),
+		Entry("missing metric", "/ < 1", "missing metric"),
+	)
+})
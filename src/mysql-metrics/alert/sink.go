@@ -0,0 +1,80 @@
+package alert
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// WebhookSink posts the alerts of every notification as a JSON object with an
+// "alerts" list.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: webhookTimeout}}
+}
+
+func (s *WebhookSink) Notify(alerts []Alert) error {
+	body, err := json.Marshal(struct {
+		Alerts []Alert `json:"alerts"`
+	}{alerts})
+	if err != nil {
+		return err
+	}
+
+	response, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("posting alerts to webhook: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("posting alerts to webhook: unexpected status %s", response.Status)
+	}
+
+	return nil
+}
+
+// FileSink appends every alert it is notified of to a file as a line of JSON.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Notify(alerts []Alert) error {
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, alert := range alerts {
+		if err := encoder.Encode(alert); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return fmt.Errorf("writing alerts: %w", err)
+	}
+
+	if _, err := file.Write(lines.Bytes()); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("writing alerts: %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,94 @@
+package alert_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/alert"
+)
+
+var _ = Describe("Sinks", func() {
+	var alerts []alert.Alert
+
+	BeforeEach(func() {
+		alerts = []alert.Alert{{
+			Rule:  "cluster_size",
+			Expr:  "galera/wsrep_cluster_size < 3",
+			State: alert.Firing,
+			Value: 2,
+			Since: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+			Time:  time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+		}}
+	})
+
+	Describe("WebhookSink", func() {
+		It("posts the alerts as JSON", func() {
+			var body []byte
+			var contentType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ = io.ReadAll(r.Body)
+			}))
+			DeferCleanup(server.Close)
+
+			Expect(alert.NewWebhookSink(server.URL).Notify(alerts)).To(Succeed())
+
+			Expect(contentType).To(Equal("application/json"))
+			Expect(body).To(MatchJSON(`{"alerts": [{
+				"rule": "cluster_size",
+				"expr": "galera/wsrep_cluster_size < 3",
+				"state": "firing",
+				"value": 2,
+				"since": "2026-10-17T12:00:00Z",
+				"time": "2026-10-17T12:00:00Z"
+			}]}`))
+		})
+
+		It("fails when the webhook does not accept the alerts", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}))
+			DeferCleanup(server.Close)
+
+			err := alert.NewWebhookSink(server.URL).Notify(alerts)
+			Expect(err).To(MatchError(ContainSubstring("unexpected status 502 Bad Gateway")))
+		})
+	})
+
+	Describe("FileSink", func() {
+		It("appends every alert as a line of JSON", func() {
+			path := filepath.Join(GinkgoT().TempDir(), "alerts.log")
+			sink := alert.NewFileSink(path)
+
+			Expect(sink.Notify(alerts)).To(Succeed())
+			alerts[0].State = alert.Resolved
+			Expect(sink.Notify(alerts)).To(Succeed())
+
+			contents, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+
+			lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+			Expect(lines).To(HaveLen(2))
+
+			var written alert.Alert
+			Expect(json.Unmarshal([]byte(lines[1]), &written)).To(Succeed())
+			Expect(written.Rule).To(Equal("cluster_size"))
+			Expect(written.State).To(Equal(alert.Resolved))
+		})
+
+		It("fails when the file cannot be written", func() {
+			sink := alert.NewFileSink(filepath.Join(GinkgoT().TempDir(), "missing", "alerts.log"))
+
+			Expect(sink.Notify(alerts)).To(MatchError(ContainSubstring("writing alerts")))
+		})
+	})
+})
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/cloudfoundry/mysql-metrics/alert"
+)
+
+// AlertRule raises an alert while the condition of its expression holds, such
+// as "galera/wsrep_cluster_size < 3" or
+// "system/persistent_disk_used_percent > 90 for 5m".
+type AlertRule struct {
+	Name string `yaml:"name"`
+	Expr string `yaml:"expr"`
+}
+
+var alertRuleName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// Rules returns the alert rules of the config. It fails on the first rule
+// whose expression cannot be parsed, which Validate reports.
+func (c *Config) Rules() ([]alert.Rule, error) {
+	rules := make([]alert.Rule, 0, len(c.AlertRules))
+	for _, rule := range c.AlertRules {
+		parsed, err := alert.ParseRule(rule.Name, rule.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("alert rule %s: %w", rule.Name, err)
+		}
+		rules = append(rules, parsed)
+	}
+
+	return rules, nil
+}
+
+// AlertSinkConfigured reports whether the alerts of the rules go anywhere.
+func (c *Config) AlertSinkConfigured() bool {
+	return c.AlertWebhookURL != "" || c.AlertFilePath != "" || c.EmitAlertMetrics
+}
+
+func (c *Config) validateAlerts(v *validator) {
+	names := make(map[string]bool, len(c.AlertRules))
+	for i, rule := range c.AlertRules {
+		field := fmt.Sprintf("alert_rules[%d]", i)
+		v.required(field+".name", rule.Name)
+		if rule.Name != "" && !alertRuleName.MatchString(rule.Name) {
+			v.fail(field+".name", ErrInvalid, fmt.Sprintf("%q may only contain letters, digits, '_' and '-'", rule.Name))
+		} else if rule.Name != "" && names[rule.Name] {
+			v.fail(field+".name", ErrInvalid, fmt.Sprintf("%q is used by another rule", rule.Name))
+		}
+		names[rule.Name] = true
+
+		v.required(field+".expr", rule.Expr)
+		if rule.Expr != "" {
+			if _, err := alert.ParseCondition(rule.Expr); err != nil {
+				v.fail(field+".expr", ErrInvalid, err.Error())
+			}
+		}
+	}
+
+	if len(c.AlertRules) > 0 && !c.AlertSinkConfigured() {
+		v.fail("alert_rules", ErrInvalid, "alerts go nowhere without alert_webhook_url, alert_file_path or emit_alert_metrics")
+	}
+
+	if c.AlertWebhookURL != "" {
+		if u, err := url.Parse(c.AlertWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.fail("alert_webhook_url", ErrInvalid, "must be an http or https URL")
+		}
+	}
+}
@@ -2,31 +2,171 @@ package config
 
 import (
 	"os"
+	"slices"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+const (
+	SenderLoggregator = "loggregator"
+	SenderPrometheus  = "prometheus"
+	SenderOTLP        = "otlp"
+
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
 type Config struct {
-	MetricsFrequency          int    `yaml:"metrics_frequency"`
-	Host                      string `yaml:"host"`
-	Port                      int    `yaml:"port"`
-	Password                  string `yaml:"password"`
-	Username                  string `yaml:"username"`
-	InstanceID                string `yaml:"instance_id"`
-	Origin                    string `yaml:"origin"`
-	SourceID                  string `yaml:"source_id"`
-	EmitCPUMetrics            bool   `yaml:"emit_cpu_metrics"`
-	EmitMysqlMetrics          bool   `yaml:"emit_mysql_metrics"`
-	EmitLeaderFollowerMetrics bool   `yaml:"emit_leader_follower_metrics"`
-	EmitGaleraMetrics         bool   `yaml:"emit_galera_metrics"`
-	EmitDiskMetrics           bool   `yaml:"emit_disk_metrics"`
-	EmitBrokerMetrics         bool   `yaml:"emit_broker_metrics"`
-	EmitBackupMetrics         bool   `yaml:"emit_backup_metrics"`
-	HeartbeatDatabase         string `yaml:"heartbeat_database"`
-	HeartbeatTable            string `yaml:"heartbeat_table"`
-	LoggregatorCAPath         string `yaml:"loggregator_ca_path"`
-	LoggregatorClientCertPath string `yaml:"loggregator_client_cert_path"`
-	LoggregatorClientKeyPath  string `yaml:"loggregator_client_key_path"`
+	MetricsFrequency          int             `yaml:"metrics_frequency"`
+	QueryTimeout              int             `yaml:"query_timeout"`
+	CollectorTimeout          int             `yaml:"collector_timeout"`
+	Host                      string          `yaml:"host"`
+	Port                      int             `yaml:"port"`
+	Password                  string          `yaml:"password"`
+	Username                  string          `yaml:"username"`
+	Socket                    string          `yaml:"socket"`
+	TLSEnabled                bool            `yaml:"tls_enabled"`
+	TLSCAPath                 string          `yaml:"tls_ca_path"`
+	TLSServerName             string          `yaml:"tls_server_name"`
+	TLSClientCertPath         string          `yaml:"tls_client_cert_path"`
+	TLSClientKeyPath          string          `yaml:"tls_client_key_path"`
+	TLSInsecureSkipVerify     bool            `yaml:"tls_insecure_skip_verify"`
+	InstanceID                string          `yaml:"instance_id"`
+	AZ                        string          `yaml:"az"`
+	Deployment                string          `yaml:"deployment"`
+	NodeRole                  string          `yaml:"node_role"`
+	Origin                    string          `yaml:"origin"`
+	SourceID                  string          `yaml:"source_id"`
+	EmitCPUMetrics            bool            `yaml:"emit_cpu_metrics"`
+	EmitCPUCoreMetrics        bool            `yaml:"emit_cpu_core_metrics"`
+	EmitMemoryMetrics         bool            `yaml:"emit_memory_metrics"`
+	MysqldPidFile             string          `yaml:"mysqld_pid_file"`
+	EmitMysqlMetrics          bool            `yaml:"emit_mysql_metrics"`
+	EmitLeaderFollowerMetrics bool            `yaml:"emit_leader_follower_metrics"`
+	EmitGaleraMetrics         bool            `yaml:"emit_galera_metrics"`
+	EmitDiskMetrics           bool            `yaml:"emit_disk_metrics"`
+	EmitBrokerMetrics         bool            `yaml:"emit_broker_metrics"`
+	EmitBackupMetrics         bool            `yaml:"emit_backup_metrics"`
+	EmitDigestMetrics         bool            `yaml:"emit_digest_metrics"`
+	DigestTopN                int             `yaml:"digest_top_n"`
+	DigestReportPath          string          `yaml:"digest_report_path"`
+	DigestReportInterval      int             `yaml:"digest_report_interval"`
+	EmitTableSizeMetrics      bool            `yaml:"emit_table_size_metrics"`
+	TableSizeInterval         int             `yaml:"table_size_interval"`
+	TableSizeTimeout          int             `yaml:"table_size_timeout"`
+	TableSizeTopN             int             `yaml:"table_size_top_n"`
+	TableSizeIncludeSchemas   []string        `yaml:"table_size_include_schemas"`
+	TableSizeExcludeSchemas   []string        `yaml:"table_size_exclude_schemas"`
+	TableSizeFileSizes        bool            `yaml:"table_size_file_sizes"`
+	EmitTransactionMetrics    bool            `yaml:"emit_transaction_metrics"`
+	TransactionAgeThresholds  []int           `yaml:"transaction_age_thresholds"`
+	EmitInnoDBStatusMetrics   bool            `yaml:"emit_innodb_status_metrics"`
+	Collectors                map[string]bool `yaml:"collectors"`
+	HeartbeatDatabase         string          `yaml:"heartbeat_database"`
+	HeartbeatTable            string          `yaml:"heartbeat_table"`
+	LoggregatorCAPath         string          `yaml:"loggregator_ca_path"`
+	LoggregatorClientCertPath string          `yaml:"loggregator_client_cert_path"`
+	LoggregatorClientKeyPath  string          `yaml:"loggregator_client_key_path"`
+	LoggregatorBatch          bool            `yaml:"loggregator_batch"`
+	LoggregatorEnvelopeSize   int             `yaml:"loggregator_envelope_size"`
+	Senders                   []string        `yaml:"senders"`
+	PrometheusListenAddress   string          `yaml:"prometheus_listen_address"`
+	OTLPEndpoint              string          `yaml:"otlp_endpoint"`
+	OTLPProtocol              string          `yaml:"otlp_protocol"`
+	OTLPInsecure              bool            `yaml:"otlp_insecure"`
+	StatusListenAddress       string          `yaml:"status_listen_address"`
+	UnhealthyCycles           int             `yaml:"unhealthy_cycles"`
+	MetricMappingPath         string          `yaml:"metric_mapping_path"`
+	CustomQueries             []CustomQuery   `yaml:"custom_queries"`
+	AlertRules                []AlertRule     `yaml:"alert_rules"`
+	AlertWebhookURL           string          `yaml:"alert_webhook_url"`
+	AlertFilePath             string          `yaml:"alert_file_path"`
+	EmitAlertMetrics          bool            `yaml:"emit_alert_metrics"`
+	Targets                   []Target        `yaml:"targets"`
+
+	// Tags are sent with every metric, in addition to the instance, az,
+	// deployment and node role.
+	Tags map[string]string `yaml:"tags"`
+
+	// TargetName and TargetTags identify the target a config returned by
+	// TargetConfigs is for.
+	TargetName string            `yaml:"-"`
+	TargetTags map[string]string `yaml:"-"`
+}
+
+const (
+	defaultQueryTimeout         = 10 * time.Second
+	defaultCollectorTimeout     = 10 * time.Second
+	defaultCustomQueryTimeout   = 10 * time.Second
+	defaultDigestTopN           = 10
+	defaultDigestReportInterval = 5 * time.Minute
+	defaultTableSizeInterval    = 5 * time.Minute
+	defaultTableSizeTimeout     = time.Minute
+	defaultTableSizeTopN        = 10
+	defaultUnhealthyCycles      = 3
+
+	// valueExpiryIntervals is how many of the longest collector intervals a
+	// value is kept for after it was last collected, so that a scheduled
+	// collector may fail once without its values going away.
+	valueExpiryIntervals = 2
+)
+
+// defaultTransactionAgeThresholds are the ages, in seconds, for which the
+// number of older open transactions is emitted.
+var defaultTransactionAgeThresholds = []int{60, 300, 3600}
+
+// defaultTableSizeExcludeSchemas are the system schemas left out of the table
+// size metrics unless table_size_exclude_schemas is set.
+var defaultTableSizeExcludeSchemas = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+// BuiltinCollectors are the names of the collectors that run for every config,
+// in the order they run. Every custom query adds a collector of its own.
+var BuiltinCollectors = []string{
+	"disk_usage", "disk_performance", "broker", "cpu", "memory", "backup", "mysql",
+	"leader_follower", "transactions", "innodb_status", "digest", "table_size",
+}
+
+// CustomQuery is an operator defined SQL query whose result columns are
+// emitted as metrics. Every row produces one metric per value column, tagged
+// with the values of the label columns under their lowercased column names.
+type CustomQuery struct {
+	Name         string              `yaml:"name"`
+	Query        string              `yaml:"query"`
+	Interval     int                 `yaml:"interval"`
+	Timeout      int                 `yaml:"timeout"`
+	ValueColumns []CustomQueryColumn `yaml:"value_columns"`
+	LabelColumns []string            `yaml:"label_columns"`
+}
+
+type CustomQueryColumn struct {
+	Column string `yaml:"column"`
+	Key    string `yaml:"key"`
+	Unit   string `yaml:"unit"`
+}
+
+// IntervalDuration returns how often the query should run, falling back to
+// defaultInterval when no interval is configured.
+func (q CustomQuery) IntervalDuration(defaultInterval time.Duration) time.Duration {
+	if q.Interval <= 0 {
+		return defaultInterval
+	}
+
+	return time.Duration(q.Interval) * time.Second
+}
+
+// CollectorName returns the name of the collector that runs the query, by
+// which it can be enabled or disabled under collectors.
+func (q CustomQuery) CollectorName() string {
+	return "custom_query/" + q.Name
+}
+
+func (q CustomQuery) TimeoutDuration() time.Duration {
+	if q.Timeout <= 0 {
+		return defaultCustomQueryTimeout
+	}
+
+	return time.Duration(q.Timeout) * time.Second
 }
 
 func LoadFromFile(filepath string, cfg *Config) error {
@@ -41,3 +181,158 @@ func LoadFromFile(filepath string, cfg *Config) error {
 
 	return err
 }
+
+// SenderEnabled reports whether metrics should be emitted through the named
+// sender. When no senders are configured only loggregator is enabled.
+func (c *Config) SenderEnabled(name string) bool {
+	if len(c.Senders) == 0 {
+		return name == SenderLoggregator
+	}
+
+	return slices.Contains(c.Senders, name)
+}
+
+// CollectorNames returns the names of the collectors of c: the built-in ones
+// followed by one for every custom query.
+func (c *Config) CollectorNames() []string {
+	names := slices.Clone(BuiltinCollectors)
+	for _, query := range c.CustomQueries {
+		names = append(names, query.CollectorName())
+	}
+
+	return names
+}
+
+// CollectorEnabled reports whether the named collector should run. Entries of
+// collectors take precedence over the emit_* flag the collector defaults to.
+func (c *Config) CollectorEnabled(name string, enabledByDefault bool) bool {
+	if enabled, ok := c.Collectors[name]; ok {
+		return enabled
+	}
+
+	return enabledByDefault
+}
+
+// QueryTimeoutDuration returns how long a single query against the database
+// may run before it is cancelled.
+func (c *Config) QueryTimeoutDuration() time.Duration {
+	if c.QueryTimeout <= 0 {
+		return defaultQueryTimeout
+	}
+
+	return time.Duration(c.QueryTimeout) * time.Second
+}
+
+// CollectorTimeoutDuration returns how long a single category of metrics may
+// take to collect, which defaults to the metrics frequency.
+func (c *Config) CollectorTimeoutDuration() time.Duration {
+	if c.CollectorTimeout > 0 {
+		return time.Duration(c.CollectorTimeout) * time.Second
+	}
+	if c.MetricsFrequency > 0 {
+		return time.Duration(c.MetricsFrequency) * time.Second
+	}
+
+	return defaultCollectorTimeout
+}
+
+// DigestLimit returns how many statement digests are emitted for every
+// ranking.
+func (c *Config) DigestLimit() int {
+	if c.DigestTopN <= 0 {
+		return defaultDigestTopN
+	}
+
+	return c.DigestTopN
+}
+
+func (c *Config) DigestReportIntervalDuration() time.Duration {
+	if c.DigestReportInterval <= 0 {
+		return defaultDigestReportInterval
+	}
+
+	return time.Duration(c.DigestReportInterval) * time.Second
+}
+
+func (c *Config) TableSizeIntervalDuration() time.Duration {
+	if c.TableSizeInterval <= 0 {
+		return defaultTableSizeInterval
+	}
+
+	return time.Duration(c.TableSizeInterval) * time.Second
+}
+
+// LongestCollectorInterval returns the longest interval at which a collector
+// enabled for any target runs: the metrics frequency, the table size interval
+// or the interval of a custom query.
+func (c *Config) LongestCollectorInterval() time.Duration {
+	metricsInterval := time.Duration(c.MetricsFrequency) * time.Second
+	longest := metricsInterval
+
+	for _, target := range c.TargetConfigs() {
+		if target.CollectorEnabled("table_size", target.EmitTableSizeMetrics) {
+			longest = max(longest, target.TableSizeIntervalDuration())
+		}
+	}
+	for _, query := range c.CustomQueries {
+		longest = max(longest, query.IntervalDuration(metricsInterval))
+	}
+
+	return longest
+}
+
+// ValueExpiry returns how long the last value of a metric is still served,
+// by the prometheus sender and in the status, after it was last collected.
+func (c *Config) ValueExpiry() time.Duration {
+	return valueExpiryIntervals * c.LongestCollectorInterval()
+}
+
+// TableSizeTimeoutDuration returns how long reading the size of every table
+// may take, which is longer than other queries as information_schema.TABLES
+// opens every table.
+func (c *Config) TableSizeTimeoutDuration() time.Duration {
+	if c.TableSizeTimeout <= 0 {
+		return defaultTableSizeTimeout
+	}
+
+	return time.Duration(c.TableSizeTimeout) * time.Second
+}
+
+// TableSizeLimit returns how many of the largest tables are emitted.
+func (c *Config) TableSizeLimit() int {
+	if c.TableSizeTopN <= 0 {
+		return defaultTableSizeTopN
+	}
+
+	return c.TableSizeTopN
+}
+
+// TableSizeExcludedSchemas returns the schema patterns left out of the table
+// size metrics, which are the system schemas when none are configured.
+func (c *Config) TableSizeExcludedSchemas() []string {
+	if c.TableSizeExcludeSchemas == nil {
+		return defaultTableSizeExcludeSchemas
+	}
+
+	return c.TableSizeExcludeSchemas
+}
+
+// UnhealthyCycleLimit returns how many cycles in a row must fail before
+// mysql-metrics reports itself unhealthy.
+func (c *Config) UnhealthyCycleLimit() int {
+	if c.UnhealthyCycles <= 0 {
+		return defaultUnhealthyCycles
+	}
+
+	return c.UnhealthyCycles
+}
+
+// TransactionAgeThresholdSeconds returns the ages for which the number of
+// older open transactions is emitted.
+func (c *Config) TransactionAgeThresholdSeconds() []int {
+	if len(c.TransactionAgeThresholds) == 0 {
+		return defaultTransactionAgeThresholds
+	}
+
+	return c.TransactionAgeThresholds
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	. "github.com/cloudfoundry/mysql-metrics/config"
 
@@ -66,6 +67,8 @@ var _ = Describe("Config", func() {
 				"username":"%s",
 				"password":"%s",
 				"metrics_frequency":%d,
+				"query_timeout":5,
+				"collector_timeout":20,
 				"source_id":"%s",
 				"origin": "%s",
 				"emit_broker_metrics":%t,
@@ -74,8 +77,42 @@ var _ = Describe("Config", func() {
 				"emit_galera_metrics":%t,
 				"emit_disk_metrics":%t,
 				"emit_backup_metrics":%t,
+				"emit_digest_metrics":true,
+				"digest_top_n":5,
+				"digest_report_path":"/var/vcap/sys/log/mysql-metrics/digests.json",
+				"digest_report_interval":600,
+				"emit_table_size_metrics":true,
+				"table_size_interval":900,
+				"table_size_timeout":120,
+				"table_size_top_n":20,
+				"table_size_include_schemas":["cf_*"],
+				"table_size_exclude_schemas":["cf_metadata"],
+				"table_size_file_sizes":true,
+				"emit_transaction_metrics":true,
+				"transaction_age_thresholds":[30,7200],
+				"emit_innodb_status_metrics":true,
+				"emit_memory_metrics":true,
+				"emit_cpu_core_metrics":true,
+				"mysqld_pid_file":"/var/vcap/sys/run/pxc-mysql/mysqld.pid",
+				"collectors":{"cpu":false,"innodb_status":true},
 				"heartbeat_database":"%s",
-				"heartbeat_table":"%s"
+				"heartbeat_table":"%s",
+				"senders":["loggregator","prometheus"],
+				"prometheus_listen_address":"127.0.0.1:9104",
+				"otlp_endpoint":"collector:4317",
+				"otlp_protocol":"grpc",
+				"otlp_insecure":true,
+				"status_listen_address":"127.0.0.1:9105",
+				"unhealthy_cycles":60,
+				"metric_mapping_path":"/path/to/mappings.yml",
+				"custom_queries":[{
+					"name":"queue_depth",
+					"query":"SELECT queue, COUNT(*) AS pending FROM app.jobs GROUP BY queue",
+					"interval":60,
+					"timeout":5,
+					"value_columns":[{"column":"pending","key":"app/queue_pending","unit":"number"}],
+					"label_columns":["queue"]
+				}]
 			}`, instanceId, host, username, password, metricFrequency, sourceId, origin, emitBrokerMetrics, emitMysqlMetrics, emitLeaderFollowerMetrics, emitGaleraMetrics, emitDiskMetrics, emitBackupMetrics, heartbeatDatabase, heartbeatTable)
 
 			err = os.WriteFile(configFilepath, []byte(configString), os.ModePerm)
@@ -99,6 +136,8 @@ var _ = Describe("Config", func() {
 			Expect(config.Username).To(Equal(username))
 			Expect(config.Password).To(Equal(password))
 			Expect(config.MetricsFrequency).To(Equal(metricFrequency))
+			Expect(config.QueryTimeoutDuration()).To(Equal(5 * time.Second))
+			Expect(config.CollectorTimeoutDuration()).To(Equal(20 * time.Second))
 			Expect(config.SourceID).To(Equal(sourceId))
 			Expect(config.Origin).To(Equal(origin))
 			Expect(config.EmitBrokerMetrics).To(Equal(emitBrokerMetrics))
@@ -107,8 +146,44 @@ var _ = Describe("Config", func() {
 			Expect(config.EmitGaleraMetrics).To(Equal(emitGaleraMetrics))
 			Expect(config.EmitDiskMetrics).To(Equal(emitDiskMetrics))
 			Expect(config.EmitBackupMetrics).To(Equal(emitBackupMetrics))
+			Expect(config.EmitDigestMetrics).To(BeTrue())
+			Expect(config.DigestTopN).To(Equal(5))
+			Expect(config.DigestReportPath).To(Equal("/var/vcap/sys/log/mysql-metrics/digests.json"))
+			Expect(config.DigestReportInterval).To(Equal(600))
+			Expect(config.EmitTableSizeMetrics).To(BeTrue())
+			Expect(config.TableSizeInterval).To(Equal(900))
+			Expect(config.TableSizeTimeout).To(Equal(120))
+			Expect(config.TableSizeTopN).To(Equal(20))
+			Expect(config.TableSizeIncludeSchemas).To(Equal([]string{"cf_*"}))
+			Expect(config.TableSizeExcludeSchemas).To(Equal([]string{"cf_metadata"}))
+			Expect(config.TableSizeFileSizes).To(BeTrue())
+			Expect(config.EmitTransactionMetrics).To(BeTrue())
+			Expect(config.TransactionAgeThresholds).To(Equal([]int{30, 7200}))
+			Expect(config.EmitInnoDBStatusMetrics).To(BeTrue())
+			Expect(config.EmitMemoryMetrics).To(BeTrue())
+			Expect(config.EmitCPUCoreMetrics).To(BeTrue())
+			Expect(config.MysqldPidFile).To(Equal("/var/vcap/sys/run/pxc-mysql/mysqld.pid"))
+			Expect(config.Collectors).To(Equal(map[string]bool{"cpu": false, "innodb_status": true}))
 			Expect(config.HeartbeatDatabase).To(Equal(heartbeatDatabase))
 			Expect(config.HeartbeatTable).To(Equal(heartbeatTable))
+			Expect(config.Senders).To(Equal([]string{"loggregator", "prometheus"}))
+			Expect(config.PrometheusListenAddress).To(Equal("127.0.0.1:9104"))
+			Expect(config.OTLPEndpoint).To(Equal("collector:4317"))
+			Expect(config.OTLPProtocol).To(Equal("grpc"))
+			Expect(config.OTLPInsecure).To(BeTrue())
+			Expect(config.StatusListenAddress).To(Equal("127.0.0.1:9105"))
+			Expect(config.UnhealthyCycleLimit()).To(Equal(60))
+			Expect(config.MetricMappingPath).To(Equal("/path/to/mappings.yml"))
+			Expect(config.CustomQueries).To(Equal([]CustomQuery{
+				{
+					Name:         "queue_depth",
+					Query:        "SELECT queue, COUNT(*) AS pending FROM app.jobs GROUP BY queue",
+					Interval:     60,
+					Timeout:      5,
+					ValueColumns: []CustomQueryColumn{{Column: "pending", Key: "app/queue_pending", Unit: "number"}},
+					LabelColumns: []string{"queue"},
+				},
+			}))
 		})
 	})
 
@@ -145,4 +220,153 @@ var _ = Describe("Config", func() {
 			Expect(err).To(HaveOccurred())
 		})
 	})
+
+	Describe("SenderEnabled", func() {
+		It("enables only loggregator when no senders are configured", func() {
+			Expect(config.SenderEnabled(SenderLoggregator)).To(BeTrue())
+			Expect(config.SenderEnabled(SenderPrometheus)).To(BeFalse())
+		})
+
+		It("enables only the configured senders", func() {
+			config.Senders = []string{SenderPrometheus}
+
+			Expect(config.SenderEnabled(SenderLoggregator)).To(BeFalse())
+			Expect(config.SenderEnabled(SenderPrometheus)).To(BeTrue())
+		})
+	})
+
+	Describe("CollectorEnabled", func() {
+		It("defaults to the given emit flag", func() {
+			Expect(config.CollectorEnabled("cpu", true)).To(BeTrue())
+			Expect(config.CollectorEnabled("cpu", false)).To(BeFalse())
+		})
+
+		It("uses the configured collectors over the emit flag", func() {
+			config.Collectors = map[string]bool{"cpu": false, "broker": true}
+
+			Expect(config.CollectorEnabled("cpu", true)).To(BeFalse())
+			Expect(config.CollectorEnabled("broker", false)).To(BeTrue())
+			Expect(config.CollectorEnabled("disk_usage", true)).To(BeTrue())
+		})
+	})
+
+	Describe("CustomQuery", func() {
+		It("defaults the interval and timeout", func() {
+			query := CustomQuery{}
+
+			Expect(query.IntervalDuration(30 * time.Second)).To(Equal(30 * time.Second))
+			Expect(query.TimeoutDuration()).To(Equal(10 * time.Second))
+		})
+
+		It("uses the configured interval and timeout", func() {
+			query := CustomQuery{Interval: 60, Timeout: 5}
+
+			Expect(query.IntervalDuration(30 * time.Second)).To(Equal(60 * time.Second))
+			Expect(query.TimeoutDuration()).To(Equal(5 * time.Second))
+		})
+	})
+
+	Describe("UnhealthyCycleLimit", func() {
+		It("defaults to three cycles", func() {
+			Expect(config.UnhealthyCycleLimit()).To(Equal(3))
+		})
+	})
+
+	Describe("QueryTimeoutDuration", func() {
+		It("defaults to ten seconds", func() {
+			Expect(config.QueryTimeoutDuration()).To(Equal(10 * time.Second))
+		})
+	})
+
+	Describe("CollectorTimeoutDuration", func() {
+		It("defaults to the metrics frequency", func() {
+			config.MetricsFrequency = 30
+			Expect(config.CollectorTimeoutDuration()).To(Equal(30 * time.Second))
+		})
+
+		It("defaults to ten seconds without a metrics frequency", func() {
+			config.MetricsFrequency = 0
+			Expect(config.CollectorTimeoutDuration()).To(Equal(10 * time.Second))
+		})
+	})
+
+	Describe("digest settings", func() {
+		It("defaults the top N and report interval", func() {
+			Expect(config.DigestLimit()).To(Equal(10))
+			Expect(config.DigestReportIntervalDuration()).To(Equal(5 * time.Minute))
+		})
+
+		It("uses the configured top N and report interval", func() {
+			config.DigestTopN = 3
+			config.DigestReportInterval = 60
+
+			Expect(config.DigestLimit()).To(Equal(3))
+			Expect(config.DigestReportIntervalDuration()).To(Equal(time.Minute))
+		})
+	})
+
+	Describe("table size settings", func() {
+		It("defaults the interval, timeout, top N and excluded schemas", func() {
+			Expect(config.TableSizeIntervalDuration()).To(Equal(5 * time.Minute))
+			Expect(config.TableSizeTimeoutDuration()).To(Equal(time.Minute))
+			Expect(config.TableSizeLimit()).To(Equal(10))
+			Expect(config.TableSizeExcludedSchemas()).To(ConsistOf("information_schema", "mysql", "performance_schema", "sys"))
+		})
+
+		It("uses the configured interval, timeout, top N and excluded schemas", func() {
+			config.TableSizeInterval = 900
+			config.TableSizeTimeout = 120
+			config.TableSizeTopN = 20
+			config.TableSizeExcludeSchemas = []string{}
+
+			Expect(config.TableSizeIntervalDuration()).To(Equal(15 * time.Minute))
+			Expect(config.TableSizeTimeoutDuration()).To(Equal(2 * time.Minute))
+			Expect(config.TableSizeLimit()).To(Equal(20))
+			Expect(config.TableSizeExcludedSchemas()).To(BeEmpty())
+		})
+	})
+
+	Describe("LongestCollectorInterval", func() {
+		BeforeEach(func() {
+			config.MetricsFrequency = 30
+		})
+
+		It("is the metrics frequency when no collector runs less often", func() {
+			Expect(config.LongestCollectorInterval()).To(Equal(30 * time.Second))
+		})
+
+		It("is the table size interval when table sizes are enabled", func() {
+			config.EmitTableSizeMetrics = true
+			Expect(config.LongestCollectorInterval()).To(Equal(5 * time.Minute))
+		})
+
+		It("is the table size interval when a target enables table sizes", func() {
+			config.Targets = []Target{{Name: "primary"}, {Name: "replica", Collectors: map[string]bool{"table_size": true}}}
+			Expect(config.LongestCollectorInterval()).To(Equal(5 * time.Minute))
+		})
+
+		It("is the interval of a custom query that runs less often", func() {
+			config.CustomQueries = []CustomQuery{{Name: "queue_depth", Interval: 600}}
+			Expect(config.LongestCollectorInterval()).To(Equal(10 * time.Minute))
+		})
+	})
+
+	Describe("ValueExpiry", func() {
+		It("is twice the longest collector interval", func() {
+			config.MetricsFrequency = 30
+			config.EmitTableSizeMetrics = true
+			Expect(config.ValueExpiry()).To(Equal(10 * time.Minute))
+		})
+	})
+
+	Describe("TransactionAgeThresholdSeconds", func() {
+		It("defaults to one minute, five minutes and one hour", func() {
+			Expect(config.TransactionAgeThresholdSeconds()).To(Equal([]int{60, 300, 3600}))
+		})
+
+		It("uses the configured thresholds", func() {
+			config.TransactionAgeThresholds = []int{30, 7200}
+			Expect(config.TransactionAgeThresholdSeconds()).To(Equal([]int{30, 7200}))
+		})
+	})
 })
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// restartSettings are the settings that are only read when the process
+// starts, by the yaml key they are configured with.
+var restartSettings = []struct {
+	key   string
+	value func(*Config) any
+}{
+	{"metrics_frequency", func(c *Config) any { return c.MetricsFrequency }},
+	{"origin", func(c *Config) any { return c.Origin }},
+	{"source_id", func(c *Config) any { return c.SourceID }},
+	{"instance_id", func(c *Config) any { return c.InstanceID }},
+	{"loggregator_ca_path", func(c *Config) any { return c.LoggregatorCAPath }},
+	{"loggregator_client_cert_path", func(c *Config) any { return c.LoggregatorClientCertPath }},
+	{"loggregator_client_key_path", func(c *Config) any { return c.LoggregatorClientKeyPath }},
+	{"loggregator_batch", func(c *Config) any { return c.LoggregatorBatch }},
+	{"loggregator_envelope_size", func(c *Config) any { return c.LoggregatorEnvelopeSize }},
+	{"senders", func(c *Config) any { return c.Senders }},
+	{"prometheus_listen_address", func(c *Config) any { return c.PrometheusListenAddress }},
+	{"otlp_endpoint", func(c *Config) any { return c.OTLPEndpoint }},
+	{"otlp_protocol", func(c *Config) any { return c.OTLPProtocol }},
+	{"otlp_insecure", func(c *Config) any { return c.OTLPInsecure }},
+	{"status_listen_address", func(c *Config) any { return c.StatusListenAddress }},
+	{"alert_webhook_url", func(c *Config) any { return c.AlertWebhookURL }},
+	{"alert_file_path", func(c *Config) any { return c.AlertFilePath }},
+	{"metric_mapping_path", func(c *Config) any { return c.MetricMappingPath }},
+	{"targets", func(c *Config) any { return c.targetNames() }},
+}
+
+// Reload reads and validates the config at filepath to replace current, which
+// the process is running with. It also fails if a setting that is only read at
+// startup has changed, so that the process never runs with a config it does
+// not apply.
+func Reload(filepath string, current *Config) (*Config, error) {
+	cfg := &Config{}
+	if err := LoadFromFile(filepath, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for _, setting := range restartSettings {
+		if !reflect.DeepEqual(setting.value(current), setting.value(cfg)) {
+			changed = append(changed, setting.key)
+		}
+	}
+	if len(changed) > 0 {
+		return nil, fmt.Errorf("settings that require a restart have changed: %s", strings.Join(changed, ", "))
+	}
+
+	return cfg, nil
+}
+
+// SameServer reports whether other connects to the same MySQL server as c, in
+// which case the counters read from it remain valid.
+func (c *Config) SameServer(other *Config) bool {
+	return c.Host == other.Host && c.Port == other.Port && c.Socket == other.Socket
+}
+
+// SameConnection reports whether a connection opened with c can be used for
+// other.
+func (c *Config) SameConnection(other *Config) bool {
+	return c.SameServer(other) &&
+		c.Username == other.Username &&
+		c.Password == other.Password &&
+		c.TLSEnabled == other.TLSEnabled &&
+		c.TLSCAPath == other.TLSCAPath &&
+		c.TLSServerName == other.TLSServerName &&
+		c.TLSClientCertPath == other.TLSClientCertPath &&
+		c.TLSClientKeyPath == other.TLSClientKeyPath &&
+		c.TLSInsecureSkipVerify == other.TLSInsecureSkipVerify
+}
+
+// SameCollectors reports whether the collectors built from c can be used for
+// other. Collectors read every other setting on each cycle.
+func (c *Config) SameCollectors(other *Config) bool {
+	return reflect.DeepEqual(c.CustomQueries, other.CustomQueries) &&
+		c.DigestReportPath == other.DigestReportPath &&
+		c.DigestReportIntervalDuration() == other.DigestReportIntervalDuration()
+}
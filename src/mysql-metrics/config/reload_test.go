@@ -0,0 +1,170 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/cloudfoundry/mysql-metrics/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Reload", func() {
+	var (
+		current        *Config
+		configFilepath string
+	)
+
+	BeforeEach(func() {
+		current = &Config{
+			Host:             "localhost",
+			Port:             3306,
+			Username:         "user",
+			Password:         "secret",
+			MetricsFrequency: 30,
+			Origin:           "origin",
+			Senders:          []string{"prometheus"},
+		}
+
+		configFilepath = filepath.Join(GinkgoT().TempDir(), "mysql-config.yml")
+	})
+
+	writeConfig := func(contents string) {
+		Expect(os.WriteFile(configFilepath, []byte(contents), 0600)).To(Succeed())
+	}
+
+	It("returns the new config", func() {
+		writeConfig(`{
+			"host":"localhost",
+			"port":3306,
+			"username":"user",
+			"password":"rotated",
+			"metrics_frequency":30,
+			"origin":"origin",
+			"senders":["prometheus"],
+			"emit_leader_follower_metrics":true,
+			"heartbeat_database":"someDatabase",
+			"heartbeat_table":"someTable"
+		}`)
+
+		cfg, err := Reload(configFilepath, current)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Password).To(Equal("rotated"))
+		Expect(cfg.EmitLeaderFollowerMetrics).To(BeTrue())
+		Expect(current.Password).To(Equal("secret"))
+	})
+
+	It("fails when settings that require a restart have changed", func() {
+		writeConfig(`{
+			"host":"localhost",
+			"port":3306,
+			"username":"user",
+			"password":"secret",
+			"metrics_frequency":10,
+			"origin":"origin",
+			"senders":["prometheus"],
+			"prometheus_listen_address":":9105"
+		}`)
+
+		_, err := Reload(configFilepath, current)
+		Expect(err).To(MatchError("settings that require a restart have changed: metrics_frequency, prometheus_listen_address"))
+	})
+
+	It("fails when targets are added or removed", func() {
+		current.Targets = []Target{{Name: "primary"}}
+		writeConfig(`{
+			"host":"localhost",
+			"port":3306,
+			"username":"user",
+			"password":"secret",
+			"metrics_frequency":30,
+			"origin":"origin",
+			"senders":["prometheus"],
+			"targets":[{"name":"primary","password":"rotated"},{"name":"replica"}]
+		}`)
+
+		_, err := Reload(configFilepath, current)
+		Expect(err).To(MatchError("settings that require a restart have changed: targets"))
+	})
+
+	It("fails when the new config is not valid", func() {
+		writeConfig(`{
+			"host":"localhost",
+			"port":3306,
+			"username":"user",
+			"metrics_frequency":30,
+			"origin":"origin",
+			"senders":["prometheus"]
+		}`)
+
+		_, err := Reload(configFilepath, current)
+		Expect(err).To(MatchError(ErrRequired))
+		Expect(err).To(MatchError(ContainSubstring("password is required")))
+	})
+
+	It("fails when the config file is not valid", func() {
+		writeConfig(`{"host":`)
+
+		_, err := Reload(configFilepath, current)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("fails when the config file does not exist", func() {
+		_, err := Reload(filepath.Join(filepath.Dir(configFilepath), "missing.yml"), current)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("comparing configs", func() {
+	var current, other *Config
+
+	BeforeEach(func() {
+		current = &Config{Host: "localhost", Port: 3306, Username: "user", Password: "secret"}
+		other = &Config{Host: "localhost", Port: 3306, Username: "user", Password: "secret"}
+	})
+
+	It("uses the same server and connection when they are unchanged", func() {
+		Expect(current.SameServer(other)).To(BeTrue())
+		Expect(current.SameConnection(other)).To(BeTrue())
+	})
+
+	It("uses the same server but a new connection when the credentials change", func() {
+		other.Password = "rotated"
+
+		Expect(current.SameServer(other)).To(BeTrue())
+		Expect(current.SameConnection(other)).To(BeFalse())
+	})
+
+	It("uses a new server when the host or port change", func() {
+		other.Port = 3307
+
+		Expect(current.SameServer(other)).To(BeFalse())
+		Expect(current.SameConnection(other)).To(BeFalse())
+	})
+
+	It("uses a new server when the socket changes", func() {
+		other.Socket = "/var/vcap/sys/run/mysql/mysqld.sock"
+
+		Expect(current.SameServer(other)).To(BeFalse())
+	})
+
+	It("uses the same server but a new connection when the TLS settings change", func() {
+		other.TLSEnabled = true
+
+		Expect(current.SameServer(other)).To(BeTrue())
+		Expect(current.SameConnection(other)).To(BeFalse())
+	})
+
+	It("uses new collectors only when the custom queries or digest report change", func() {
+		other.EmitCPUMetrics = true
+		Expect(current.SameCollectors(other)).To(BeTrue())
+
+		other.CustomQueries = []CustomQuery{{Name: "q", Query: "SELECT 1"}}
+		Expect(current.SameCollectors(other)).To(BeFalse())
+
+		other.CustomQueries = nil
+		other.DigestReportPath = "/tmp/digests.json"
+		Expect(current.SameCollectors(other)).To(BeFalse())
+	})
+})
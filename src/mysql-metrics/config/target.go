@@ -0,0 +1,103 @@
+package config
+
+import (
+	"maps"
+)
+
+// Target is one of several MySQL servers monitored by the same process. The
+// connection settings it leaves unset are taken from the top-level config, and
+// its collectors take precedence over the top-level ones. The top-level TLS
+// server name names the top-level server, so a target that sets its own host
+// or socket only verifies against the server name it sets itself.
+type Target struct {
+	Name          string            `yaml:"name"`
+	Host          string            `yaml:"host"`
+	Port          int               `yaml:"port"`
+	Socket        string            `yaml:"socket"`
+	Username      string            `yaml:"username"`
+	Password      string            `yaml:"password"`
+	TLSServerName string            `yaml:"tls_server_name"`
+	Collectors    map[string]bool   `yaml:"collectors"`
+	Tags          map[string]string `yaml:"tags"`
+}
+
+// TargetConfigs returns a config for every target, which is c itself when no
+// targets are configured.
+func (c *Config) TargetConfigs() []*Config {
+	if len(c.Targets) == 0 {
+		return []*Config{c}
+	}
+
+	configs := make([]*Config, 0, len(c.Targets))
+	for _, target := range c.Targets {
+		cfg := *c
+		cfg.Targets = nil
+		cfg.TargetName = target.Name
+		cfg.TargetTags = target.Tags
+
+		if target.Host != "" || target.Socket != "" {
+			cfg.Host = target.Host
+			cfg.Socket = target.Socket
+			cfg.TLSServerName = ""
+		}
+		if target.Port != 0 {
+			cfg.Port = target.Port
+		}
+		if target.Username != "" {
+			cfg.Username = target.Username
+		}
+		if target.Password != "" {
+			cfg.Password = target.Password
+		}
+		if target.TLSServerName != "" {
+			cfg.TLSServerName = target.TLSServerName
+		}
+
+		if len(target.Collectors) > 0 {
+			cfg.Collectors = make(map[string]bool, len(c.Collectors)+len(target.Collectors))
+			maps.Copy(cfg.Collectors, c.Collectors)
+			maps.Copy(cfg.Collectors, target.Collectors)
+		}
+
+		configs = append(configs, &cfg)
+	}
+
+	return configs
+}
+
+// MetricTags returns the tags every metric is sent with: the instance, az,
+// deployment and node role when they are set, the configured tags and, for a
+// target, its name and tags. Tags of the target take precedence.
+// Without a configured node role, the processor tags metrics with the role
+// read from the leader/follower metrics of the cycle.
+func (c *Config) MetricTags() map[string]string {
+	tags := make(map[string]string)
+	for name, value := range map[string]string{
+		"instance_id": c.InstanceID,
+		"az":          c.AZ,
+		"deployment":  c.Deployment,
+		"node_role":   c.NodeRole,
+		"target":      c.TargetName,
+	} {
+		if value != "" {
+			tags[name] = value
+		}
+	}
+	maps.Copy(tags, c.Tags)
+	maps.Copy(tags, c.TargetTags)
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return tags
+}
+
+func (c *Config) targetNames() []string {
+	names := make([]string, len(c.Targets))
+	for i, target := range c.Targets {
+		names[i] = target.Name
+	}
+
+	return names
+}
@@ -0,0 +1,141 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/cloudfoundry/mysql-metrics/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TargetConfigs", func() {
+	var cfg *Config
+
+	BeforeEach(func() {
+		cfg = &Config{
+			Host:             "localhost",
+			Port:             3306,
+			Username:         "user",
+			Password:         "secret",
+			EmitMysqlMetrics: true,
+			Collectors:       map[string]bool{"cpu": false},
+		}
+	})
+
+	It("returns the config itself when no targets are configured", func() {
+		configs := cfg.TargetConfigs()
+		Expect(configs).To(HaveLen(1))
+		Expect(configs[0]).To(BeIdenticalTo(cfg))
+		Expect(configs[0].MetricTags()).To(BeNil())
+	})
+
+	It("tags metrics with the instance, az, deployment, node role and configured tags", func() {
+		cfg.InstanceID = "vm-123456"
+		cfg.AZ = "z1"
+		cfg.Deployment = "pxc"
+		cfg.NodeRole = "leader"
+		cfg.Tags = map[string]string{"team": "data", "az": "override"}
+
+		Expect(cfg.MetricTags()).To(Equal(map[string]string{
+			"instance_id": "vm-123456",
+			"az":          "override",
+			"deployment":  "pxc",
+			"node_role":   "leader",
+			"team":        "data",
+		}))
+	})
+
+	It("reads the targets from the config file", func() {
+		configFilepath := filepath.Join(GinkgoT().TempDir(), "mysql-config.yml")
+		Expect(os.WriteFile(configFilepath, []byte(`{
+			"targets": [
+				{
+					"name": "replica",
+					"host": "10.0.0.2",
+					"port": 3307,
+					"username": "replica-user",
+					"password": "replica-secret",
+					"collectors": {"leader_follower": true},
+					"tags": {"role": "replica"}
+				}
+			]
+		}`), 0600)).To(Succeed())
+
+		Expect(LoadFromFile(configFilepath, cfg)).To(Succeed())
+		Expect(cfg.Targets).To(Equal([]Target{{
+			Name:       "replica",
+			Host:       "10.0.0.2",
+			Port:       3307,
+			Username:   "replica-user",
+			Password:   "replica-secret",
+			Collectors: map[string]bool{"leader_follower": true},
+			Tags:       map[string]string{"role": "replica"},
+		}}))
+	})
+
+	Context("with targets", func() {
+		BeforeEach(func() {
+			cfg.Targets = []Target{
+				{Name: "primary"},
+				{
+					Name:       "replica",
+					Host:       "10.0.0.2",
+					Port:       3307,
+					Username:   "replica-user",
+					Password:   "replica-secret",
+					Collectors: map[string]bool{"leader_follower": true},
+					Tags:       map[string]string{"role": "replica"},
+				},
+				{Name: "local", Socket: "/var/vcap/sys/run/mysql/mysqld.sock"},
+			}
+		})
+
+		It("falls back to the top-level settings a target leaves unset", func() {
+			primary := cfg.TargetConfigs()[0]
+			Expect(primary.TargetName).To(Equal("primary"))
+			Expect(primary.Targets).To(BeNil())
+			Expect(primary.Host).To(Equal("localhost"))
+			Expect(primary.Port).To(Equal(3306))
+			Expect(primary.Username).To(Equal("user"))
+			Expect(primary.Password).To(Equal("secret"))
+			Expect(primary.EmitMysqlMetrics).To(BeTrue())
+			Expect(primary.Collectors).To(Equal(map[string]bool{"cpu": false}))
+		})
+
+		It("uses the settings of the target", func() {
+			replica := cfg.TargetConfigs()[1]
+			Expect(replica.Host).To(Equal("10.0.0.2"))
+			Expect(replica.Port).To(Equal(3307))
+			Expect(replica.Username).To(Equal("replica-user"))
+			Expect(replica.Password).To(Equal("replica-secret"))
+			Expect(replica.Collectors).To(Equal(map[string]bool{"cpu": false, "leader_follower": true}))
+			Expect(cfg.Collectors).To(Equal(map[string]bool{"cpu": false}))
+		})
+
+		It("connects through the socket of a target instead of the top-level host", func() {
+			local := cfg.TargetConfigs()[2]
+			Expect(local.Socket).To(Equal("/var/vcap/sys/run/mysql/mysqld.sock"))
+			Expect(local.Host).To(BeEmpty())
+		})
+
+		It("verifies only a target on the top-level server against the top-level TLS server name", func() {
+			cfg.TLSServerName = "mysql.internal"
+			cfg.Targets[2].TLSServerName = "local.internal"
+
+			configs := cfg.TargetConfigs()
+			Expect(configs[0].TLSServerName).To(Equal("mysql.internal"))
+			Expect(configs[1].TLSServerName).To(BeEmpty())
+			Expect(configs[2].TLSServerName).To(Equal("local.internal"))
+		})
+
+		It("tags the metrics of a target with its name and tags", func() {
+			cfg.Tags = map[string]string{"role": "primary", "team": "data"}
+
+			configs := cfg.TargetConfigs()
+			Expect(configs[0].MetricTags()).To(Equal(map[string]string{"target": "primary", "role": "primary", "team": "data"}))
+			Expect(configs[1].MetricTags()).To(Equal(map[string]string{"target": "replica", "role": "replica", "team": "data"}))
+		})
+	})
+})
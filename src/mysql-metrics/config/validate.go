@@ -0,0 +1,235 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrRequired   = errors.New("is required")
+	ErrOutOfRange = errors.New("is out of range")
+	ErrInvalid    = errors.New("is not valid")
+	ErrUnreadable = errors.New("is not readable")
+)
+
+// FieldError is a problem with a single setting, named by its yaml key.
+type FieldError struct {
+	Field  string
+	Err    error
+	Detail string
+}
+
+func (e *FieldError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s %s", e.Field, e.Err)
+	}
+
+	return fmt.Sprintf("%s %s: %s", e.Field, e.Err, e.Detail)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationError lists every problem found in a config.
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		problems[i] = err.Error()
+	}
+
+	return "invalid config: " + strings.Join(problems, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+
+	return errs
+}
+
+type validator struct {
+	errs []*FieldError
+}
+
+func (v *validator) fail(field string, err error, detail string) {
+	v.errs = append(v.errs, &FieldError{Field: field, Err: err, Detail: detail})
+}
+
+func (v *validator) required(field, value string) {
+	if value == "" {
+		v.fail(field, ErrRequired, "")
+	}
+}
+
+func (v *validator) atLeast(field string, value, minimum int) {
+	if value < minimum {
+		v.fail(field, ErrOutOfRange, fmt.Sprintf("%d is less than %d", value, minimum))
+	}
+}
+
+func (v *validator) knownCollectors(field string, collectors map[string]bool, names []string) {
+	for _, name := range slices.Sorted(maps.Keys(collectors)) {
+		if !slices.Contains(names, name) {
+			v.fail(field, ErrInvalid, fmt.Sprintf("unknown collector %q, valid collectors are %s", name, strings.Join(names, ", ")))
+		}
+	}
+}
+
+func (v *validator) readable(field, path string) {
+	if path == "" {
+		v.fail(field, ErrRequired, "")
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		v.fail(field, ErrUnreadable, err.Error())
+		return
+	}
+	_ = f.Close()
+}
+
+// Validate checks that the config can be run with, returning a
+// *ValidationError that lists every problem found.
+func (c *Config) Validate() error {
+	v := &validator{}
+
+	v.atLeast("metrics_frequency", c.MetricsFrequency, 1)
+	v.atLeast("query_timeout", c.QueryTimeout, 0)
+	v.atLeast("collector_timeout", c.CollectorTimeout, 0)
+	v.atLeast("unhealthy_cycles", c.UnhealthyCycles, 0)
+	v.atLeast("digest_top_n", c.DigestTopN, 0)
+	v.atLeast("digest_report_interval", c.DigestReportInterval, 0)
+	v.atLeast("table_size_interval", c.TableSizeInterval, 0)
+	v.atLeast("table_size_timeout", c.TableSizeTimeout, 0)
+	v.atLeast("table_size_top_n", c.TableSizeTopN, 0)
+	v.atLeast("loggregator_envelope_size", c.LoggregatorEnvelopeSize, 0)
+	for i, threshold := range c.TransactionAgeThresholds {
+		v.atLeast(fmt.Sprintf("transaction_age_thresholds[%d]", i), threshold, 1)
+	}
+
+	if len(c.Targets) == 0 {
+		c.validateTarget(v, "")
+	} else {
+		names := make(map[string]bool, len(c.Targets))
+		for i, target := range c.Targets {
+			field := fmt.Sprintf("targets[%d].name", i)
+			v.required(field, target.Name)
+			if target.Name != "" && names[target.Name] {
+				v.fail(field, ErrInvalid, fmt.Sprintf("%q is used by another target", target.Name))
+			}
+			names[target.Name] = true
+		}
+
+		for i, target := range c.TargetConfigs() {
+			target.validateTarget(v, fmt.Sprintf("targets[%d].", i))
+		}
+	}
+
+	if c.TLSEnabled {
+		if c.TLSCAPath != "" {
+			v.readable("tls_ca_path", c.TLSCAPath)
+		}
+		if c.TLSClientCertPath != "" || c.TLSClientKeyPath != "" {
+			v.readable("tls_client_cert_path", c.TLSClientCertPath)
+			v.readable("tls_client_key_path", c.TLSClientKeyPath)
+		}
+		for i, target := range c.TargetConfigs() {
+			if target.Socket == "" || target.TLSServerName != "" || c.TLSInsecureSkipVerify {
+				continue
+			}
+
+			field := "tls_server_name"
+			if len(c.Targets) > 0 {
+				field = fmt.Sprintf("targets[%d].tls_server_name", i)
+			}
+			v.fail(field, ErrRequired, "the server of a socket cannot be verified without it")
+		}
+	} else if c.TLSCAPath != "" || c.TLSServerName != "" || c.TLSClientCertPath != "" || c.TLSClientKeyPath != "" || c.TLSInsecureSkipVerify {
+		v.fail("tls_enabled", ErrRequired, "tls settings are ignored unless it is set")
+	}
+
+	for _, sender := range c.Senders {
+		switch sender {
+		case SenderLoggregator, SenderPrometheus, SenderOTLP:
+		default:
+			v.fail("senders", ErrInvalid, fmt.Sprintf("unknown sender %q", sender))
+		}
+	}
+	if c.SenderEnabled(SenderLoggregator) {
+		v.readable("loggregator_ca_path", c.LoggregatorCAPath)
+		v.readable("loggregator_client_cert_path", c.LoggregatorClientCertPath)
+		v.readable("loggregator_client_key_path", c.LoggregatorClientKeyPath)
+	}
+	if c.SenderEnabled(SenderOTLP) {
+		v.required("otlp_endpoint", c.OTLPEndpoint)
+		switch c.OTLPProtocol {
+		case "", OTLPProtocolGRPC, OTLPProtocolHTTP:
+		default:
+			v.fail("otlp_protocol", ErrInvalid, fmt.Sprintf("unsupported protocol %q", c.OTLPProtocol))
+		}
+	}
+
+	if c.MetricMappingPath != "" {
+		v.readable("metric_mapping_path", c.MetricMappingPath)
+	}
+
+	names := make(map[string]bool, len(c.CustomQueries))
+	for i, query := range c.CustomQueries {
+		field := fmt.Sprintf("custom_queries[%d]", i)
+		v.required(field+".name", query.Name)
+		v.required(field+".query", query.Query)
+		if len(query.ValueColumns) == 0 {
+			v.fail(field+".value_columns", ErrRequired, "")
+		}
+		if query.Name != "" && names[query.Name] {
+			v.fail(field+".name", ErrInvalid, fmt.Sprintf("%q is used by another query", query.Name))
+		}
+		names[query.Name] = true
+	}
+
+	collectorNames := c.CollectorNames()
+	v.knownCollectors("collectors", c.Collectors, collectorNames)
+	for i, target := range c.Targets {
+		v.knownCollectors(fmt.Sprintf("targets[%d].collectors", i), target.Collectors, collectorNames)
+	}
+
+	c.validateAlerts(v)
+
+	if len(v.errs) > 0 {
+		return &ValidationError{Errors: v.errs}
+	}
+
+	return nil
+}
+
+// validateTarget checks the settings that can differ between targets, naming
+// them with prefix.
+func (c *Config) validateTarget(v *validator, prefix string) {
+	if c.Socket == "" {
+		v.required(prefix+"host", c.Host)
+		if c.Port < 1 || c.Port > 65535 {
+			v.fail(prefix+"port", ErrOutOfRange, fmt.Sprintf("%d is not between 1 and 65535", c.Port))
+		}
+	} else if c.Host != "" {
+		v.fail(prefix+"socket", ErrInvalid, "cannot be set together with host")
+	}
+	v.required(prefix+"username", c.Username)
+	v.required(prefix+"password", c.Password)
+
+	if c.CollectorEnabled("leader_follower", c.EmitLeaderFollowerMetrics) {
+		v.required(prefix+"heartbeat_database", c.HeartbeatDatabase)
+		v.required(prefix+"heartbeat_table", c.HeartbeatTable)
+	}
+}
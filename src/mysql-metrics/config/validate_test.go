@@ -0,0 +1,232 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/cloudfoundry/mysql-metrics/config"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validate", func() {
+	var (
+		cfg     *Config
+		certDir string
+	)
+
+	// fields returns the settings named by the problems found in cfg.
+	fields := func() []string {
+		err := cfg.Validate()
+		var validationErr *ValidationError
+		Expect(errors.As(err, &validationErr)).To(BeTrue(), "expected a *ValidationError, got %v", err)
+
+		var fields []string
+		for _, fieldErr := range validationErr.Errors {
+			fields = append(fields, fieldErr.Field)
+		}
+		return fields
+	}
+
+	BeforeEach(func() {
+		certDir = GinkgoT().TempDir()
+		for _, name := range []string{"ca.pem", "cert.pem", "key.pem"} {
+			Expect(os.WriteFile(filepath.Join(certDir, name), []byte("pem"), 0600)).To(Succeed())
+		}
+
+		cfg = &Config{
+			Host:                      "localhost",
+			Port:                      3306,
+			Username:                  "user",
+			Password:                  "secret",
+			MetricsFrequency:          30,
+			LoggregatorCAPath:         filepath.Join(certDir, "ca.pem"),
+			LoggregatorClientCertPath: filepath.Join(certDir, "cert.pem"),
+			LoggregatorClientKeyPath:  filepath.Join(certDir, "key.pem"),
+		}
+	})
+
+	It("accepts a complete config", func() {
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("lists every required setting that is missing", func() {
+		cfg.Host = ""
+		cfg.Port = 0
+		cfg.Username = ""
+		cfg.Password = ""
+		cfg.MetricsFrequency = 0
+
+		Expect(fields()).To(Equal([]string{"metrics_frequency", "host", "port", "username", "password"}))
+		Expect(cfg.Validate()).To(MatchError(ErrRequired))
+		Expect(cfg.Validate()).To(MatchError(ErrOutOfRange))
+	})
+
+	It("rejects settings that are out of range", func() {
+		cfg.Port = 70000
+		cfg.QueryTimeout = -1
+		cfg.LoggregatorEnvelopeSize = -1
+		cfg.TransactionAgeThresholds = []int{60, 0}
+
+		Expect(fields()).To(Equal([]string{"query_timeout", "loggregator_envelope_size", "transaction_age_thresholds[1]", "port"}))
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring("port is out of range: 70000 is not between 1 and 65535")))
+	})
+
+	It("connects through a socket instead of a host and port", func() {
+		cfg.Host = ""
+		cfg.Port = 0
+		cfg.Socket = "/var/vcap/sys/run/mysql/mysqld.sock"
+		Expect(cfg.Validate()).To(Succeed())
+
+		cfg.Host = "localhost"
+		Expect(fields()).To(Equal([]string{"socket"}))
+	})
+
+	It("requires readable TLS files and a server name to verify a socket against", func() {
+		cfg.Host = ""
+		cfg.Socket = "/var/vcap/sys/run/mysql/mysqld.sock"
+		cfg.TLSEnabled = true
+		cfg.TLSCAPath = filepath.Join(certDir, "missing.pem")
+		cfg.TLSClientCertPath = filepath.Join(certDir, "cert.pem")
+
+		Expect(fields()).To(Equal([]string{"tls_ca_path", "tls_client_key_path", "tls_server_name"}))
+
+		cfg.TLSCAPath = filepath.Join(certDir, "ca.pem")
+		cfg.TLSClientKeyPath = filepath.Join(certDir, "key.pem")
+		cfg.TLSServerName = "mysql.internal"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("requires a server name to verify the socket of every target against", func() {
+		cfg.TLSEnabled = true
+		cfg.TLSServerName = "mysql.internal"
+		cfg.Targets = []Target{
+			{Name: "primary"},
+			{Name: "local", Socket: "/var/vcap/sys/run/mysql/mysqld.sock"},
+		}
+
+		Expect(fields()).To(Equal([]string{"targets[1].tls_server_name"}))
+
+		cfg.Targets[1].TLSServerName = "local.internal"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("rejects TLS settings when TLS is not enabled", func() {
+		cfg.TLSInsecureSkipVerify = true
+
+		Expect(fields()).To(Equal([]string{"tls_enabled"}))
+	})
+
+	It("validates the connection of every target instead of the top-level one", func() {
+		cfg.Host = ""
+		cfg.Password = ""
+		cfg.Targets = []Target{
+			{Name: "primary", Host: "10.0.0.1", Password: "secret"},
+			{Name: "primary", Port: 70000},
+			{Host: "10.0.0.3", Password: "secret", Collectors: map[string]bool{"leader_follower": true}},
+		}
+
+		Expect(fields()).To(Equal([]string{
+			"targets[1].name",
+			"targets[2].name",
+			"targets[1].host",
+			"targets[1].port",
+			"targets[1].password",
+			"targets[2].heartbeat_database",
+			"targets[2].heartbeat_table",
+		}))
+	})
+
+	It("requires the loggregator certificates to be readable when loggregator is enabled", func() {
+		cfg.LoggregatorCAPath = filepath.Join(certDir, "missing.pem")
+		cfg.LoggregatorClientKeyPath = ""
+
+		Expect(fields()).To(Equal([]string{"loggregator_ca_path", "loggregator_client_key_path"}))
+		Expect(cfg.Validate()).To(MatchError(ErrUnreadable))
+
+		cfg.Senders = []string{SenderPrometheus}
+		Expect(cfg.Validate()).To(Succeed())
+	})
+
+	It("requires the heartbeat table when leader-follower metrics are enabled", func() {
+		cfg.EmitLeaderFollowerMetrics = true
+		Expect(fields()).To(Equal([]string{"heartbeat_database", "heartbeat_table"}))
+
+		cfg.HeartbeatDatabase = "someDatabase"
+		cfg.HeartbeatTable = "someTable"
+		Expect(cfg.Validate()).To(Succeed())
+
+		cfg.EmitLeaderFollowerMetrics = false
+		cfg.HeartbeatTable = ""
+		cfg.Collectors = map[string]bool{"leader_follower": true}
+		Expect(fields()).To(Equal([]string{"heartbeat_table"}))
+	})
+
+	It("rejects unknown senders and an incomplete otlp sender", func() {
+		cfg.Senders = []string{SenderOTLP, "statsd"}
+		cfg.OTLPProtocol = "udp"
+
+		Expect(fields()).To(Equal([]string{"senders", "otlp_endpoint", "otlp_protocol"}))
+	})
+
+	It("requires the metric mapping file to be readable when set", func() {
+		cfg.MetricMappingPath = filepath.Join(certDir, "missing.yml")
+
+		Expect(fields()).To(Equal([]string{"metric_mapping_path"}))
+	})
+
+	It("requires custom queries to be complete and uniquely named", func() {
+		cfg.CustomQueries = []CustomQuery{
+			{Name: "sessions", Query: "SELECT 1 AS n", ValueColumns: []CustomQueryColumn{{Column: "n"}}},
+			{Name: "sessions", Query: "SELECT 2 AS n", ValueColumns: []CustomQueryColumn{{Column: "n"}}},
+			{},
+		}
+
+		Expect(fields()).To(Equal([]string{
+			"custom_queries[1].name",
+			"custom_queries[2].name",
+			"custom_queries[2].query",
+			"custom_queries[2].value_columns",
+		}))
+	})
+
+	It("rejects unknown collectors, listing the valid ones", func() {
+		cfg.CustomQueries = []CustomQuery{
+			{Name: "sessions", Query: "SELECT 1 AS n", ValueColumns: []CustomQueryColumn{{Column: "n"}}},
+		}
+		cfg.Collectors = map[string]bool{"cpu": false, "custom_query/sessions": true, "innodb": true}
+		cfg.Targets = []Target{{Name: "primary", Collectors: map[string]bool{"table_sizes": true}}}
+
+		Expect(fields()).To(Equal([]string{"collectors", "targets[0].collectors"}))
+		Expect(cfg.Validate()).To(MatchError(ContainSubstring(
+			`collectors is not valid: unknown collector "innodb", valid collectors are disk_usage, disk_performance, broker, cpu, memory, backup, mysql, leader_follower, transactions, innodb_status, digest, table_size, custom_query/sessions`,
+		)))
+	})
+
+	It("requires alert rules to be uniquely named, parseable and sent somewhere", func() {
+		cfg.AlertRules = []AlertRule{
+			{Name: "disk_full", Expr: "system/persistent_disk_used_percent > 90 for 5m"},
+			{Name: "disk_full", Expr: "galera/wsrep_cluster_size < 3"},
+			{Name: "cluster size", Expr: "galera/wsrep_cluster_size <"},
+			{},
+		}
+
+		Expect(fields()).To(Equal([]string{
+			"alert_rules[1].name",
+			"alert_rules[2].name",
+			"alert_rules[2].expr",
+			"alert_rules[3].name",
+			"alert_rules[3].expr",
+			"alert_rules",
+		}))
+
+		cfg.AlertRules = cfg.AlertRules[:1]
+		cfg.AlertWebhookURL = "alerts.example.com"
+		Expect(fields()).To(Equal([]string{"alert_webhook_url"}))
+
+		cfg.AlertWebhookURL = "https://alerts.example.com/hook"
+		Expect(cfg.Validate()).To(Succeed())
+	})
+})
@@ -23,6 +23,15 @@ func NewCPUStat(stats string) (CPUStat, error) {
 	if cpuStatsStrings[0] != "cpu" {
 		return CPUStat{}, errors.New("/proc/stat file does not contain data as expected")
 	}
+	return parseCPUStat(cpuStatsStrings)
+}
+
+// parseCPUStat parses the fields of a cpu line of /proc/stat, the first of
+// which is the name of the cpu.
+func parseCPUStat(cpuStatsStrings []string) (CPUStat, error) {
+	if len(cpuStatsStrings) < 9 {
+		return CPUStat{}, fmt.Errorf("/proc/stat %s line does not contain data as expected", cpuStatsStrings[0])
+	}
 	user, err := strconv.ParseInt(cpuStatsStrings[1], 10, 64)
 	if err != nil {
 		return CPUStat{}, fmt.Errorf("error parsing user value: %v", err)
@@ -65,5 +74,59 @@ func NewCPUStat(stats string) (CPUStat, error) {
 		SoftIRQ: uint64(softirq),
 		Steal:   uint64(steal),
 	}, nil
+}
+
+// Total returns the time spent in every mode.
+func (s CPUStat) Total() uint64 {
+	return s.User + s.Nice + s.System + s.Idle + s.IOWait + s.IRQ + s.SoftIRQ + s.Steal
+}
+
+// Percentages is the share of the time between two samples of a cpu spent in
+// every mode. Utilization is the share spent neither idle nor waiting for I/O.
+type Percentages struct {
+	Utilization float64
+	User        float64
+	Nice        float64
+	System      float64
+	Idle        float64
+	IOWait      float64
+	IRQ         float64
+	SoftIRQ     float64
+	Steal       float64
+}
+
+// Percentages returns the share of the time since previous spent in every
+// mode. All are 0 when no time has passed.
+func (s CPUStat) Percentages(previous CPUStat) Percentages {
+	total := since(s.Total(), previous.Total())
+	if total == 0 {
+		return Percentages{}
+	}
+
+	percent := func(current, previous uint64) float64 {
+		return float64(since(current, previous)) / float64(total) * 100
+	}
+	idle := since(s.Idle+s.IOWait, previous.Idle+previous.IOWait)
+
+	return Percentages{
+		Utilization: float64(total-min(idle, total)) / float64(total) * 100,
+		User:        percent(s.User, previous.User),
+		Nice:        percent(s.Nice, previous.Nice),
+		System:      percent(s.System, previous.System),
+		Idle:        percent(s.Idle, previous.Idle),
+		IOWait:      percent(s.IOWait, previous.IOWait),
+		IRQ:         percent(s.IRQ, previous.IRQ),
+		SoftIRQ:     percent(s.SoftIRQ, previous.SoftIRQ),
+		Steal:       percent(s.Steal, previous.Steal),
+	}
+}
+
+// since returns how much a counter grew, which is 0 when it went backwards, as
+// the iowait time of a cpu may.
+func since(current, previous uint64) uint64 {
+	if current < previous {
+		return 0
+	}
 
+	return current - previous
 }
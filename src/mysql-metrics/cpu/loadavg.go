@@ -0,0 +1,52 @@
+package cpu
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadAverage is the number of tasks running, runnable or waiting for I/O,
+// averaged over 1, 5 and 15 minutes.
+type LoadAverage struct {
+	Load1  float64
+	Load5  float64
+	Load15 float64
+}
+
+// ParseLoadAverage parses the contents of /proc/loadavg.
+func ParseLoadAverage(r io.Reader) (LoadAverage, error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return LoadAverage{}, err
+	}
+
+	fields := strings.Fields(string(contents))
+	if len(fields) < 3 {
+		return LoadAverage{}, fmt.Errorf("/proc/loadavg does not contain data as expected")
+	}
+
+	var loads [3]float64
+	for i, name := range []string{"1 minute", "5 minute", "15 minute"} {
+		loads[i], err = strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return LoadAverage{}, fmt.Errorf("error parsing %s load average: %v", name, err)
+		}
+	}
+
+	return LoadAverage{Load1: loads[0], Load5: loads[1], Load15: loads[2]}, nil
+}
+
+// ReadLoadAverage reads the load average from the loadavg file at path, which
+// is /proc/loadavg on the host.
+func ReadLoadAverage(path string) (LoadAverage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return LoadAverage{}, err
+	}
+	defer file.Close()
+
+	return ParseLoadAverage(file)
+}
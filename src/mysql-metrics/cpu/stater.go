@@ -4,52 +4,110 @@ import (
 	"bufio"
 	"errors"
 	"io"
+	"strings"
 )
 
 var ErrNoPreviousData = errors.New("No Previous Data")
 
+// Sample is the share of the time since the previous sample that all cpus
+// together, and every one of them, spent in every mode.
+type Sample struct {
+	Total Percentages
+
+	// Cores are keyed by the number of the cpu. A cpu that came online since
+	// the previous sample is left out.
+	Cores map[string]Percentages
+}
+
 type Stater struct {
-	procStatFile io.ReadSeeker
-	previousStat CPUStat
+	procStatFile  io.ReadSeeker
+	loadavgPath   string
+	previousStat  CPUStat
+	previousCores map[string]CPUStat
 }
 
 func New(procStatFile io.ReadSeeker) Stater {
 	return Stater{
 		procStatFile: procStatFile,
+		loadavgPath:  "/proc/loadavg",
 	}
 }
 
+// LoadAverage reads the load average of the host.
+func (s *Stater) LoadAverage() (LoadAverage, error) {
+	return ReadLoadAverage(s.loadavgPath)
+}
+
 func (s *Stater) GetPercentage() (int, error) {
+	sample, err := s.Sample()
+	if err != nil {
+		return -1, err
+	}
+
+	return int(sample.Total.Utilization), nil
+}
+
+// Sample reads /proc/stat and returns the share of the time since the previous
+// call spent in every mode. The first call returns ErrNoPreviousData.
+func (s *Stater) Sample() (Sample, error) {
+	currentCPUStats, currentCores, err := s.read()
+	if err != nil {
+		return Sample{}, err
+	}
+
+	previousCPUStats, previousCores := s.previousStat, s.previousCores
+	s.previousStat, s.previousCores = currentCPUStats, currentCores
+
+	if previousCPUStats == (CPUStat{}) {
+		return Sample{}, ErrNoPreviousData
+	}
+
+	sample := Sample{
+		Total: currentCPUStats.Percentages(previousCPUStats),
+		Cores: make(map[string]Percentages, len(currentCores)),
+	}
+	for core, stat := range currentCores {
+		if previous, ok := previousCores[core]; ok {
+			sample.Cores[core] = stat.Percentages(previous)
+		}
+	}
+
+	return sample, nil
+}
+
+// read parses the line of all cpus together, which comes first in /proc/stat,
+// and the "cpu<N>" lines that follow it.
+func (s *Stater) read() (CPUStat, map[string]CPUStat, error) {
 	if _, err := s.procStatFile.Seek(0, 0); err != nil {
-		return 0, err
+		return CPUStat{}, nil, err
 	}
 
 	scanner := bufio.NewScanner(s.procStatFile)
 	ok := scanner.Scan()
 
 	if !ok {
-		return -1, errors.New("failed to read /proc/stat file")
+		return CPUStat{}, nil, errors.New("failed to read /proc/stat file")
 	}
 
 	text := scanner.Text()
 	currentCPUStats, err := NewCPUStat(text)
 	if err != nil {
-		return -1, err
+		return CPUStat{}, nil, err
 	}
 
-	previousCPUStats := s.previousStat
-	s.previousStat = currentCPUStats
+	cores := make(map[string]CPUStat)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "cpu") {
+			break
+		}
 
-	if previousCPUStats == (CPUStat{}) {
-		return -1, ErrNoPreviousData
+		stat, err := parseCPUStat(fields)
+		if err != nil {
+			return CPUStat{}, nil, err
+		}
+		cores[strings.TrimPrefix(fields[0], "cpu")] = stat
 	}
 
-	prevTotal := previousCPUStats.User + previousCPUStats.System + previousCPUStats.Idle + previousCPUStats.Nice + previousCPUStats.IOWait + previousCPUStats.IRQ + previousCPUStats.SoftIRQ + previousCPUStats.Steal
-	currTotal := currentCPUStats.User + currentCPUStats.System + currentCPUStats.Idle + currentCPUStats.Nice + currentCPUStats.IOWait + currentCPUStats.IRQ + currentCPUStats.SoftIRQ + currentCPUStats.Steal
-
-	totald := currTotal - prevTotal
-	idled := (currentCPUStats.Idle + currentCPUStats.IOWait) - (previousCPUStats.Idle + previousCPUStats.IOWait)
-	cpuPercentageTotal := float64(totald-idled) / float64(totald)
-
-	return int(cpuPercentageTotal * 100), nil
+	return currentCPUStats, cores, nil
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -126,4 +127,106 @@ var _ = Describe("Stater", func() {
 		})
 
 	})
+
+	Describe("Sample", func() {
+		var tmpFile *os.File
+
+		BeforeEach(func() {
+			var err error
+			tmpFile, err = os.CreateTemp("", "procStatFile")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = tmpFile.WriteString("cpu  1229059 1 838002 14163742 781998 0 34211 10040\n" +
+				"cpu0 614000 0 420000 7080000 391000 0 20000 5000\n" +
+				"cpu1 615059 1 418002 7083742 390998 0 14211 5040\n" +
+				"intr 204578381 9 0 0 0\n")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.Remove(tmpFile.Name())
+		})
+
+		writeFixture := func() {
+			contents, err := os.ReadFile("../fixtures/proc/stat")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tmpFile.Truncate(0)).To(Succeed())
+			_, err = tmpFile.WriteAt(contents, 0)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		It("provides the percentage of time spent in every mode by all cores and every core", func() {
+			stater := cpu.New(tmpFile)
+
+			_, err := stater.Sample()
+			Expect(err).To(Equal(cpu.ErrNoPreviousData))
+
+			writeFixture()
+
+			sample, err := stater.Sample()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sample.Total.Utilization).To(BeNumerically("~", 16.03, 0.01))
+			Expect(sample.Total.User).To(BeNumerically("~", 6.61, 0.01))
+			Expect(sample.Total.Nice).To(BeZero())
+			Expect(sample.Total.System).To(BeNumerically("~", 8.23, 0.01))
+			Expect(sample.Total.Idle).To(BeNumerically("~", 74.39, 0.01))
+			Expect(sample.Total.IOWait).To(BeNumerically("~", 9.58, 0.01))
+			Expect(sample.Total.IRQ).To(BeZero())
+			Expect(sample.Total.SoftIRQ).To(BeNumerically("~", 0.39, 0.01))
+			Expect(sample.Total.Steal).To(BeNumerically("~", 0.80, 0.01))
+
+			Expect(sample.Cores).To(HaveLen(2))
+			Expect(sample.Cores["0"].Utilization).To(BeNumerically("~", 16.25, 0.01))
+			Expect(sample.Cores["0"].IOWait).To(BeNumerically("~", 9.25, 0.01))
+			Expect(sample.Cores["1"].Utilization).To(BeNumerically("~", 15.79, 0.01))
+			Expect(sample.Cores["1"].Steal).To(BeNumerically("~", 0.82, 0.01))
+		})
+
+		It("leaves out cores that came online since the previous sample", func() {
+			Expect(tmpFile.Truncate(0)).To(Succeed())
+			_, err := tmpFile.WriteAt([]byte("cpu  1229059 1 838002 14163742 781998 0 34211 10040\ncpu0 614000 0 420000 7080000 391000 0 20000 5000\n"), 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			stater := cpu.New(tmpFile)
+			_, err = stater.Sample()
+			Expect(err).To(Equal(cpu.ErrNoPreviousData))
+
+			writeFixture()
+
+			sample, err := stater.Sample()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(sample.Cores).To(HaveKey("0"))
+			Expect(sample.Cores).NotTo(HaveKey("1"))
+		})
+
+		It("returns an error when a core line is truncated", func() {
+			Expect(tmpFile.Truncate(0)).To(Succeed())
+			_, err := tmpFile.WriteAt([]byte("cpu  1229059 1 838002 14163742 781998 0 34211 10040\ncpu0 614000 0 420000\n"), 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			stater := cpu.New(tmpFile)
+			_, err = stater.Sample()
+			Expect(err).To(MatchError("/proc/stat cpu0 line does not contain data as expected"))
+		})
+	})
+
+	Describe("ParseLoadAverage", func() {
+		It("parses the load averages", func() {
+			loadAverage, err := cpu.ReadLoadAverage("../fixtures/proc/loadavg")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(loadAverage).To(Equal(cpu.LoadAverage{Load1: 1.52, Load5: 0.97, Load15: 0.61}))
+		})
+
+		It("returns an error when a load average is not a number", func() {
+			_, err := cpu.ParseLoadAverage(strings.NewReader("1.52 % 0.61 3/412 28714\n"))
+			Expect(err).To(MatchError(`error parsing 5 minute load average: strconv.ParseFloat: parsing "%": invalid syntax`))
+		})
+
+		It("returns an error when the file is truncated", func() {
+			_, err := cpu.ParseLoadAverage(strings.NewReader("1.52\n"))
+			Expect(err).To(MatchError("/proc/loadavg does not contain data as expected"))
+		})
+	})
 })
@@ -0,0 +1,102 @@
+package database_client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/go-sql-driver/mysql"
+
+	configPackage "github.com/cloudfoundry/mysql-metrics/config"
+)
+
+// TLSConfigName is the name the TLS config built from the config is
+// registered with the mysql driver under. The TLS config of a target is
+// registered under this name followed by the name of the target.
+const TLSConfigName = "mysql-metrics"
+
+// Open opens a connection to the database described by config.
+func Open(config *configPackage.Config) (*sql.DB, error) {
+	dsn, err := DSN(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.Open("mysql", dsn)
+}
+
+// DSN returns the data source name of the database described by config. When
+// TLS is enabled, the TLS config it refers to is registered with the mysql
+// driver, replacing the one registered before.
+//
+// Without TLS enabled, connections over TCP use TLS only when the server
+// offers it and connections over a socket never do.
+func DSN(config *configPackage.Config) (string, error) {
+	dsn := mysql.NewConfig()
+	dsn.User = config.Username
+	dsn.Passwd = config.Password
+
+	if config.Socket != "" {
+		dsn.Net = "unix"
+		dsn.Addr = config.Socket
+		dsn.TLSConfig = "false"
+	} else {
+		dsn.Net = "tcp"
+		dsn.Addr = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+		dsn.TLSConfig = "preferred"
+	}
+
+	if config.TLSEnabled {
+		tlsConfig, err := newTLSConfig(config)
+		if err != nil {
+			return "", err
+		}
+		name := TLSConfigName
+		if config.TargetName != "" {
+			name += "/" + config.TargetName
+		}
+		if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+			return "", err
+		}
+		dsn.TLSConfig = name
+	}
+
+	return dsn.FormatDSN(), nil
+}
+
+func newTLSConfig(config *configPackage.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         config.TLSServerName,
+		InsecureSkipVerify: config.TLSInsecureSkipVerify,
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = config.Host
+	}
+
+	if config.TLSCAPath != "" {
+		ca, err := os.ReadFile(config.TLSCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading tls ca: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in tls ca %s", config.TLSCAPath)
+		}
+	}
+
+	if config.TLSClientCertPath != "" {
+		certificate, err := tls.LoadX509KeyPair(config.TLSClientCertPath, config.TLSClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading tls client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,127 @@
+package database_client_test
+
+import (
+	"github.com/go-sql-driver/mysql"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	configPackage "github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/database_client"
+)
+
+var _ = Describe("DSN", func() {
+	var config *configPackage.Config
+
+	// parse returns the driver config the DSN for config is read as.
+	parse := func() *mysql.Config {
+		dsn, err := database_client.DSN(config)
+		Expect(err).NotTo(HaveOccurred())
+
+		parsed, err := mysql.ParseDSN(dsn)
+		Expect(err).NotTo(HaveOccurred())
+		return parsed
+	}
+
+	BeforeEach(func() {
+		config = &configPackage.Config{
+			Host:     "mysql.example.com",
+			Port:     3306,
+			Username: "user",
+			Password: "p@ss/word",
+		}
+	})
+
+	It("connects over TCP with TLS when the server offers it", func() {
+		parsed := parse()
+		Expect(parsed.Net).To(Equal("tcp"))
+		Expect(parsed.Addr).To(Equal("mysql.example.com:3306"))
+		Expect(parsed.User).To(Equal("user"))
+		Expect(parsed.Passwd).To(Equal("p@ss/word"))
+		Expect(parsed.TLSConfig).To(Equal("preferred"))
+		Expect(parsed.AllowFallbackToPlaintext).To(BeTrue())
+	})
+
+	It("connects over a socket without TLS", func() {
+		config.Host = ""
+		config.Port = 0
+		config.Socket = "/var/vcap/sys/run/mysql/mysqld.sock"
+
+		parsed := parse()
+		Expect(parsed.Net).To(Equal("unix"))
+		Expect(parsed.Addr).To(Equal("/var/vcap/sys/run/mysql/mysqld.sock"))
+		Expect(parsed.TLS).To(BeNil())
+	})
+
+	Context("when TLS is enabled", func() {
+		BeforeEach(func() {
+			config.TLSEnabled = true
+		})
+
+		It("verifies the server with the system roots and its host name", func() {
+			parsed := parse()
+			Expect(parsed.TLSConfig).To(Equal(database_client.TLSConfigName))
+			Expect(parsed.AllowFallbackToPlaintext).To(BeFalse())
+			Expect(parsed.TLS.ServerName).To(Equal("mysql.example.com"))
+			Expect(parsed.TLS.RootCAs).To(BeNil())
+			Expect(parsed.TLS.InsecureSkipVerify).To(BeFalse())
+		})
+
+		It("verifies the server with the configured CA and server name", func() {
+			config.TLSCAPath = "../fixtures/certs/loggregator.crt"
+			config.TLSServerName = "mysql.internal"
+
+			parsed := parse()
+			Expect(parsed.TLS.ServerName).To(Equal("mysql.internal"))
+			Expect(parsed.TLS.RootCAs).NotTo(BeNil())
+		})
+
+		It("presents the configured client certificate", func() {
+			config.TLSClientCertPath = "../fixtures/certs/loggregator-agent.crt"
+			config.TLSClientKeyPath = "../fixtures/certs/loggregator-agent.key"
+
+			Expect(parse().TLS.Certificates).To(HaveLen(1))
+		})
+
+		It("skips verification only when opted in", func() {
+			config.TLSInsecureSkipVerify = true
+
+			Expect(parse().TLS.InsecureSkipVerify).To(BeTrue())
+		})
+
+		It("uses TLS over a socket", func() {
+			config.Socket = "/var/vcap/sys/run/mysql/mysqld.sock"
+
+			parsed := parse()
+			Expect(parsed.Net).To(Equal("unix"))
+			Expect(parsed.TLSConfig).To(Equal(database_client.TLSConfigName))
+		})
+
+		It("registers the TLS config of every target separately", func() {
+			config.TargetName = "replica"
+			replica := parse()
+
+			config.TargetName = "primary"
+			config.Host = "primary.example.com"
+			primary := parse()
+
+			Expect(replica.TLSConfig).To(Equal(database_client.TLSConfigName + "/replica"))
+			Expect(replica.TLS.ServerName).To(Equal("mysql.example.com"))
+			Expect(primary.TLSConfig).To(Equal(database_client.TLSConfigName + "/primary"))
+			Expect(primary.TLS.ServerName).To(Equal("primary.example.com"))
+		})
+
+		It("fails when the CA cannot be read", func() {
+			config.TLSCAPath = "../fixtures/certs/missing.crt"
+
+			_, err := database_client.DSN(config)
+			Expect(err).To(MatchError(ContainSubstring("reading tls ca")))
+		})
+
+		It("fails when the CA contains no certificates", func() {
+			config.TLSCAPath = "../fixtures/certs/loggregator.key"
+
+			_, err := database_client.DSN(config)
+			Expect(err).To(MatchError(ContainSubstring("no certificates found in tls ca")))
+		})
+	})
+})
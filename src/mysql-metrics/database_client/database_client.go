@@ -1,8 +1,10 @@
 package database_client
 
 import (
+	"context"
 	"database/sql"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -11,8 +13,8 @@ import (
 )
 
 type DbClient struct {
-	connection *sql.DB
-	config     *configPackage.Config
+	connection atomic.Pointer[sql.DB]
+	config     atomic.Pointer[configPackage.Config]
 }
 
 func QuoteIdentifier(identifier string) string {
@@ -20,27 +22,39 @@ func QuoteIdentifier(identifier string) string {
 }
 
 func NewDatabaseClient(connection *sql.DB, config *configPackage.Config) *DbClient {
-	return &DbClient{
-		connection: connection,
-		config:     config,
-	}
+	dc := &DbClient{}
+	dc.connection.Store(connection)
+	dc.config.Store(config)
+	return dc
+}
+
+// SetConfig replaces the config the client reads its heartbeat table and query
+// timeout from.
+func (dc *DbClient) SetConfig(config *configPackage.Config) {
+	dc.config.Store(config)
 }
 
-func (dc *DbClient) IsAvailable() bool {
-	_, err := dc.runKeyValueQuery("SHOW GLOBAL STATUS")
+// SwapConnection replaces the connection used by subsequent queries and
+// returns the previous one, which queries already running may still be using.
+func (dc *DbClient) SwapConnection(connection *sql.DB) *sql.DB {
+	return dc.connection.Swap(connection)
+}
+
+func (dc *DbClient) IsAvailable(ctx context.Context) bool {
+	_, err := dc.runKeyValueQuery(ctx, "SHOW GLOBAL STATUS")
 	return nil == err
 }
 
-func (dc *DbClient) ShowGlobalStatus() (map[string]string, error) {
-	return dc.runKeyValueQuery("SHOW GLOBAL STATUS")
+func (dc *DbClient) ShowGlobalStatus(ctx context.Context) (map[string]string, error) {
+	return dc.runKeyValueQuery(ctx, "SHOW GLOBAL STATUS")
 }
 
-func (dc *DbClient) ShowGlobalVariables() (map[string]string, error) {
-	return dc.runKeyValueQuery("SHOW GLOBAL VARIABLES")
+func (dc *DbClient) ShowGlobalVariables(ctx context.Context) (map[string]string, error) {
+	return dc.runKeyValueQuery(ctx, "SHOW GLOBAL VARIABLES")
 }
 
-func (dc *DbClient) ShowSlaveStatus() (map[string]string, error) {
-	replicaStatus, err := dc.runSingleRowQuery("SHOW REPLICA STATUS", nil)
+func (dc *DbClient) ShowSlaveStatus(ctx context.Context) (map[string]string, error) {
+	replicaStatus, err := dc.runSingleRowQuery(ctx, "SHOW REPLICA STATUS", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -58,19 +72,20 @@ func (dc *DbClient) ShowSlaveStatus() (map[string]string, error) {
 	}, err
 }
 
-func (dc *DbClient) IsFollower() (bool, error) {
-	slaveStatus, err := dc.ShowSlaveStatus()
+func (dc *DbClient) IsFollower(ctx context.Context) (bool, error) {
+	slaveStatus, err := dc.ShowSlaveStatus(ctx)
 	return len(slaveStatus) > 0, err
 }
 
-func (dc *DbClient) HeartbeatStatus() (map[string]string, error) {
+func (dc *DbClient) HeartbeatStatus(ctx context.Context) (map[string]string, error) {
+	config := dc.config.Load()
 	sql := "SELECT UNIX_TIMESTAMP(NOW()) - UNIX_TIMESTAMP(timestamp) AS seconds_since_leader_heartbeat FROM " +
-		QuoteIdentifier(dc.config.HeartbeatDatabase) + "." + QuoteIdentifier(dc.config.HeartbeatTable)
-	return dc.runSingleRowQuery(sql, []interface{}{})
+		QuoteIdentifier(config.HeartbeatDatabase) + "." + QuoteIdentifier(config.HeartbeatTable)
+	return dc.runSingleRowQuery(ctx, sql, []interface{}{})
 }
 
-func (dc *DbClient) ServicePlansDiskAllocated() (map[string]string, error) {
-	row, err := dc.runSingleRowQuery("SELECT SUM(max_storage_mb) AS service_plans_disk_allocated FROM mysql_broker.service_instances",
+func (dc *DbClient) ServicePlansDiskAllocated(ctx context.Context) (map[string]string, error) {
+	row, err := dc.runSingleRowQuery(ctx, "SELECT SUM(max_storage_mb) AS service_plans_disk_allocated FROM mysql_broker.service_instances",
 		[]interface{}{})
 	if err != nil {
 		return nil, err
@@ -81,8 +96,8 @@ func (dc *DbClient) ServicePlansDiskAllocated() (map[string]string, error) {
 	return row, nil
 }
 
-func (dc *DbClient) FindLastBackupTimestamp() (time.Time, error) {
-	results, err := dc.runSingleRowQuery("SELECT ts AS timestamp FROM backup_metrics.backup_times ORDER BY ts DESC LIMIT 1", []interface{}{})
+func (dc *DbClient) FindLastBackupTimestamp(ctx context.Context) (time.Time, error) {
+	results, err := dc.runSingleRowQuery(ctx, "SELECT ts AS timestamp FROM backup_metrics.backup_times ORDER BY ts DESC LIMIT 1", []interface{}{})
 	if err != nil {
 		return time.Time{}, err
 	}
@@ -99,20 +114,125 @@ func (dc *DbClient) FindLastBackupTimestamp() (time.Time, error) {
 	return value, nil
 }
 
-func (dc *DbClient) runSingleRowQuery(query string, params []any) (map[string]string, error) {
-	rows, err := dc.connection.Query(query, params...)
+// StatementDigests returns the cumulative statistics of every statement digest
+// recorded by performance_schema.
+func (dc *DbClient) StatementDigests(ctx context.Context) ([]map[string]string, error) {
+	return dc.RunQuery(
+		ctx,
+		"SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, COUNT_STAR, SUM_TIMER_WAIT, SUM_ROWS_EXAMINED, SUM_ERRORS "+
+			"FROM performance_schema.events_statements_summary_by_digest WHERE DIGEST IS NOT NULL",
+		dc.config.Load().QueryTimeoutDuration(),
+	)
+}
+
+// TableSizes returns the size and estimated row count of every base table,
+// which may take as long as the table size timeout.
+func (dc *DbClient) TableSizes(ctx context.Context) ([]map[string]string, error) {
+	return dc.RunQuery(
+		ctx,
+		"SELECT TABLE_SCHEMA, TABLE_NAME, DATA_LENGTH, INDEX_LENGTH, DATA_FREE, TABLE_ROWS "+
+			"FROM information_schema.TABLES WHERE TABLE_TYPE = 'BASE TABLE'",
+		dc.config.Load().TableSizeTimeoutDuration(),
+	)
+}
+
+// InnoDBTablespaces returns the on-disk size of every InnoDB tablespace.
+func (dc *DbClient) InnoDBTablespaces(ctx context.Context) ([]map[string]string, error) {
+	return dc.RunQuery(ctx, "SELECT NAME, FILE_SIZE FROM information_schema.INNODB_TABLESPACES", dc.config.Load().TableSizeTimeoutDuration())
+}
+
+// ShowEngineInnoDBStatus returns the text of the InnoDB monitor output.
+func (dc *DbClient) ShowEngineInnoDBStatus(ctx context.Context) (string, error) {
+	row, err := dc.runSingleRowQuery(ctx, "SHOW ENGINE INNODB STATUS", nil)
+	if err != nil {
+		return "", err
+	}
+
+	return row["status"], nil
+}
+
+// InnoDBTransactions returns the ID and age of every open InnoDB transaction.
+func (dc *DbClient) InnoDBTransactions(ctx context.Context) ([]map[string]string, error) {
+	return dc.RunQuery(
+		ctx,
+		"SELECT trx_id, TIMESTAMPDIFF(SECOND, trx_started, NOW()) AS age_seconds FROM information_schema.innodb_trx",
+		dc.config.Load().QueryTimeoutDuration(),
+	)
+}
+
+// DataLockWaits returns which transaction blocks which for every row lock
+// currently being waited for.
+func (dc *DbClient) DataLockWaits(ctx context.Context) ([]map[string]string, error) {
+	return dc.RunQuery(
+		ctx,
+		"SELECT REQUESTING_ENGINE_TRANSACTION_ID, BLOCKING_ENGINE_TRANSACTION_ID FROM performance_schema.data_lock_waits",
+		dc.config.Load().QueryTimeoutDuration(),
+	)
+}
+
+// MetadataLockWaiters returns the number of metadata lock requests that are
+// waiting to be granted.
+func (dc *DbClient) MetadataLockWaiters(ctx context.Context) (map[string]string, error) {
+	return dc.runSingleRowQuery(
+		ctx,
+		"SELECT COUNT(*) AS metadata_lock_waiters FROM performance_schema.metadata_locks WHERE LOCK_STATUS = 'PENDING'",
+		nil,
+	)
+}
+
+// RunQuery runs an arbitrary query and returns every row as a map of
+// lowercased column names to values, giving up once timeout has elapsed or
+// ctx is done.
+func (dc *DbClient) RunQuery(ctx context.Context, query string, timeout time.Duration) ([]map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	rows, err := dc.connection.Load().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make(map[string]string)
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]string
+	for rows.Next() {
+		result, err := scanRow(rows, columns)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func (dc *DbClient) runSingleRowQuery(ctx context.Context, query string, params []any) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dc.config.Load().QueryTimeoutDuration())
+	defer cancel()
+
+	rows, err := dc.connection.Load().QueryContext(ctx, query, params...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
 	columns, err := rows.Columns()
 	if err != nil {
 		return nil, err
 	}
 
+	if rows.Next() {
+		return scanRow(rows, columns)
+	}
+
+	return make(map[string]string), nil
+}
+
+func scanRow(rows *sql.Rows, columns []string) (map[string]string, error) {
 	nullOrString := func(v any) string {
 		value := v.(*sql.NullString)
 		if value.Valid {
@@ -122,25 +242,28 @@ func (dc *DbClient) runSingleRowQuery(query string, params []any) (map[string]st
 		}
 	}
 
-	if rows.Next() {
-		scanValues := make([]any, len(columns))
-		for i := range scanValues {
-			scanValues[i] = new(sql.NullString)
-		}
+	scanValues := make([]any, len(columns))
+	for i := range scanValues {
+		scanValues[i] = new(sql.NullString)
+	}
 
-		if err = rows.Scan(scanValues...); err != nil {
-			return nil, err
-		}
-		for i, col := range columns {
-			result[strings.ToLower(col)] = nullOrString(scanValues[i])
-		}
+	if err := rows.Scan(scanValues...); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(columns))
+	for i, col := range columns {
+		result[strings.ToLower(col)] = nullOrString(scanValues[i])
 	}
 
 	return result, nil
 }
 
-func (dc *DbClient) runKeyValueQuery(query string) (map[string]string, error) {
-	rows, err := dc.connection.Query(query)
+func (dc *DbClient) runKeyValueQuery(ctx context.Context, query string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, dc.config.Load().QueryTimeoutDuration())
+	defer cancel()
+
+	rows, err := dc.connection.Load().QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -154,5 +277,5 @@ func (dc *DbClient) runKeyValueQuery(query string) (map[string]string, error) {
 
 	}
 
-	return globalStatusVariables, nil
+	return globalStatusVariables, rows.Err()
 }
@@ -1,6 +1,7 @@
 package database_client_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"time"
@@ -23,11 +24,13 @@ var _ = Describe("DatabaseClient", func() {
 		hbDatabase       string
 		hbTable          string
 		hbTableQualified string
+		ctx              context.Context
 	)
 
 	BeforeEach(func() {
 		conn, mock, err = sqlmock.New()
 		Expect(err).NotTo(HaveOccurred())
+		ctx = context.Background()
 
 		hbDatabase = "someHbDatabase"
 		hbTable = "someHbTable"
@@ -48,14 +51,14 @@ var _ = Describe("DatabaseClient", func() {
 				AddRow("something", "doesntmatter")
 			mock.ExpectQuery(`SHOW GLOBAL STATUS`).WillReturnRows(row)
 
-			result := dc.IsAvailable()
+			result := dc.IsAvailable(ctx)
 			Expect(result).To(BeTrue())
 		})
 
 		It("returns false when executing a test query against the DB results in an error", func() {
 			mock.ExpectQuery(`SHOW GLOBAL STATUS`).WillReturnError(errors.New("db unavailable"))
 
-			result := dc.IsAvailable()
+			result := dc.IsAvailable(ctx)
 			Expect(result).To(BeFalse())
 		})
 	})
@@ -67,7 +70,7 @@ var _ = Describe("DatabaseClient", func() {
 				AddRow("Other Thing", "123.4")
 			mock.ExpectQuery(`SHOW GLOBAL STATUS`).WillReturnRows(row)
 
-			status, err := dc.ShowGlobalStatus()
+			status, err := dc.ShowGlobalStatus(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(len(status)).To(Equal(2))
 			Expect(status).To(Equal(map[string]string{
@@ -78,9 +81,21 @@ var _ = Describe("DatabaseClient", func() {
 
 		It("returns an error and no data when the query fails", func() {
 			mock.ExpectQuery(`SHOW GLOBAL STATUS`).WillReturnError(errors.New("db unavailable"))
-			_, err := dc.ShowGlobalStatus()
+			_, err := dc.ShowGlobalStatus(ctx)
 			Expect(err).To(MatchError("db unavailable"))
 		})
+
+		It("gives up once the context is done", func() {
+			mock.ExpectQuery(`SHOW GLOBAL STATUS`).
+				WillDelayFor(time.Second).
+				WillReturnRows(sqlmock.NewRows([]string{"variable_name", "value"}))
+
+			ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancel()
+
+			_, err := dc.ShowGlobalStatus(ctx)
+			Expect(err).To(MatchError(ContainSubstring("canceling query due to user request")))
+		})
 	})
 
 	Describe("ShowGlobalVariables", func() {
@@ -89,7 +104,7 @@ var _ = Describe("DatabaseClient", func() {
 				AddRow("Max_connections", "12")
 			mock.ExpectQuery(`SHOW GLOBAL VARIABLES`).WillReturnRows(row)
 
-			vars, err := dc.ShowGlobalVariables()
+			vars, err := dc.ShowGlobalVariables(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(vars).To(Equal(map[string]string{
 				"max_connections": "12",
@@ -98,7 +113,7 @@ var _ = Describe("DatabaseClient", func() {
 
 		It("returns an error and no data when the query fails", func() {
 			mock.ExpectQuery(`SHOW GLOBAL VARIABLES`).WillReturnError(errors.New("db unavailable"))
-			_, err := dc.ShowGlobalVariables()
+			_, err := dc.ShowGlobalVariables(ctx)
 			Expect(err).To(MatchError("db unavailable"))
 		})
 	})
@@ -107,7 +122,7 @@ var _ = Describe("DatabaseClient", func() {
 		It("returns an empty map when SHOW REPLICA STATUS returns an empty result (leader node)", func() {
 			mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(sqlmock.NewRows([]string{}))
 
-			vars, err := dc.ShowSlaveStatus()
+			vars, err := dc.ShowSlaveStatus(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(vars).To(BeEmpty())
 		})
@@ -128,7 +143,7 @@ var _ = Describe("DatabaseClient", func() {
 			)
 			mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(row)
 
-			vars, err := dc.ShowSlaveStatus()
+			vars, err := dc.ShowSlaveStatus(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(vars).To(Equal(map[string]string{
 				"is_follower":           "true",
@@ -141,7 +156,7 @@ var _ = Describe("DatabaseClient", func() {
 
 		It("returns an error and no data when the query fails", func() {
 			mock.ExpectQuery(`SHOW REPLICA STATUS`).WillReturnError(errors.New("db unavailable"))
-			_, err := dc.ShowSlaveStatus()
+			_, err := dc.ShowSlaveStatus(ctx)
 			Expect(err).To(MatchError("db unavailable"))
 		})
 	})
@@ -155,7 +170,7 @@ var _ = Describe("DatabaseClient", func() {
 			)
 			mock.ExpectQuery("SELECT SUM\\(max_storage_mb\\) AS service_plans_disk_allocated FROM mysql_broker\\.service_instances").WillReturnRows(row)
 
-			vars, err := dc.ServicePlansDiskAllocated()
+			vars, err := dc.ServicePlansDiskAllocated(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(vars).To(Equal(map[string]string{
 				"service_plans_disk_allocated": "0",
@@ -170,7 +185,7 @@ var _ = Describe("DatabaseClient", func() {
 			)
 			mock.ExpectQuery("SELECT SUM\\(max_storage_mb\\) AS service_plans_disk_allocated FROM mysql_broker\\.service_instances").WillReturnRows(row)
 
-			vars, err := dc.ServicePlansDiskAllocated()
+			vars, err := dc.ServicePlansDiskAllocated(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(vars).To(Equal(map[string]string{
 				"service_plans_disk_allocated": "200",
@@ -179,7 +194,7 @@ var _ = Describe("DatabaseClient", func() {
 
 		It("returns an error and no data when the query fails", func() {
 			mock.ExpectQuery("SELECT SUM\\(max_storage_mb\\) AS service_plans_disk_allocated FROM mysql_broker\\.service_instances").WillReturnError(errors.New("db unavailable"))
-			_, err := dc.ServicePlansDiskAllocated()
+			_, err := dc.ServicePlansDiskAllocated(ctx)
 			Expect(err).To(MatchError("db unavailable"))
 		})
 	})
@@ -193,7 +208,7 @@ var _ = Describe("DatabaseClient", func() {
 			)
 			mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(rows)
 
-			isFollower, err := dc.IsFollower()
+			isFollower, err := dc.IsFollower(ctx)
 			Expect(isFollower).To(BeTrue())
 			Expect(err).To(BeNil())
 		})
@@ -202,14 +217,14 @@ var _ = Describe("DatabaseClient", func() {
 			rows := sqlmock.NewRows([]string{})
 			mock.ExpectQuery("SHOW REPLICA STATUS").WillReturnRows(rows)
 
-			isFollower, err := dc.IsFollower()
+			isFollower, err := dc.IsFollower(ctx)
 			Expect(isFollower).To(BeFalse())
 			Expect(err).To(BeNil())
 		})
 
 		It("returns an error when the query fails", func() {
 			mock.ExpectQuery(`SHOW REPLICA STATUS`).WillReturnError(errors.New("db unavailable"))
-			_, err := dc.IsFollower()
+			_, err := dc.IsFollower(ctx)
 			Expect(err).To(MatchError("db unavailable"))
 		})
 	})
@@ -227,7 +242,7 @@ var _ = Describe("DatabaseClient", func() {
 					hbTableQualified).
 				WillReturnRows(row)
 
-			status, err := dc.HeartbeatStatus()
+			status, err := dc.HeartbeatStatus(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(len(status)).To(Equal(1))
 			Expect(status).To(Equal(map[string]string{
@@ -239,9 +254,36 @@ var _ = Describe("DatabaseClient", func() {
 			mock.ExpectQuery(
 				`SELECT UNIX_TIMESTAMP\(NOW\(\)\) - UNIX_TIMESTAMP\(timestamp\) AS seconds_since_leader_heartbeat FROM ` + hbTableQualified).
 				WillReturnError(errors.New("db unavailable"))
-			_, err := dc.HeartbeatStatus()
+			_, err := dc.HeartbeatStatus(ctx)
 			Expect(err).To(MatchError("db unavailable"))
 		})
+
+		It("reads the heartbeat table from the config set last", func() {
+			dc.SetConfig(&configPackage.Config{HeartbeatDatabase: "otherDatabase", HeartbeatTable: "otherTable"})
+
+			mock.ExpectQuery(`FROM ` + "`otherDatabase`.`otherTable`").
+				WillReturnRows(sqlmock.NewRows([]string{"seconds_since_leader_heartbeat"}).AddRow("1"))
+
+			status, err := dc.HeartbeatStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(HaveKeyWithValue("seconds_since_leader_heartbeat", "1"))
+		})
+	})
+
+	Describe("SwapConnection", func() {
+		It("runs subsequent queries on the new connection and returns the previous one", func() {
+			newConn, newMock, err := sqlmock.New()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dc.SwapConnection(newConn)).To(BeIdenticalTo(conn))
+
+			newMock.ExpectQuery(`SHOW GLOBAL STATUS`).
+				WillReturnRows(sqlmock.NewRows([]string{"variable_name", "value"}).AddRow("uptime", "1"))
+
+			Expect(dc.IsAvailable(ctx)).To(BeTrue())
+			Expect(newMock.ExpectationsWereMet()).To(Succeed())
+			Expect(mock.ExpectationsWereMet()).To(Succeed())
+		})
 	})
 
 	Describe("QuoteIdentifier", func() {
@@ -259,7 +301,7 @@ var _ = Describe("DatabaseClient", func() {
 				row := sqlmock.NewRows([]string{"timestamp"}).AddRow([]uint8("2020-07-14 21:28:16.000000"))
 				mock.ExpectQuery(`SELECT ts AS timestamp FROM backup_metrics.backup_times ORDER BY ts DESC LIMIT 1`).WillReturnRows(row)
 
-				vars, err := dc.FindLastBackupTimestamp()
+				vars, err := dc.FindLastBackupTimestamp(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(vars).To(Equal(parsedTime))
 			})
@@ -269,7 +311,7 @@ var _ = Describe("DatabaseClient", func() {
 			It("returns an empty timestamp", func() {
 				mock.ExpectQuery("SELECT ts AS timestamp FROM backup_metrics.backup_times ORDER BY ts DESC LIMIT 1").WillReturnRows(sqlmock.NewRows([]string{}))
 
-				vars, err := dc.FindLastBackupTimestamp()
+				vars, err := dc.FindLastBackupTimestamp(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(vars).To(Equal(time.Time{}))
 			})
@@ -279,7 +321,7 @@ var _ = Describe("DatabaseClient", func() {
 			It("returns an error", func() {
 				mock.ExpectQuery("SELECT ts AS timestamp FROM backup_metrics.backup_times ORDER BY ts DESC LIMIT 1").WillReturnRows(sqlmock.NewRows([]string{"timestamp"}).AddRow([]uint8("bad time")))
 
-				_, err := dc.FindLastBackupTimestamp()
+				_, err := dc.FindLastBackupTimestamp(ctx)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring(`cannot parse "bad time"`))
 			})
@@ -289,9 +331,187 @@ var _ = Describe("DatabaseClient", func() {
 			It("returns an error and no data", func() {
 				mock.ExpectQuery("SELECT ts AS timestamp FROM backup_metrics.backup_times ORDER BY ts DESC LIMIT 1").WillReturnRows(sqlmock.NewRows([]string{})).
 					WillReturnError(errors.New("db unavailable"))
-				_, err := dc.FindLastBackupTimestamp()
+				_, err := dc.FindLastBackupTimestamp(ctx)
 				Expect(err).To(MatchError("db unavailable"))
 			})
 		})
 	})
+
+	Describe("RunQuery", func() {
+		It("returns every row as a map of lowercased columns and their values", func() {
+			rows := sqlmock.NewRows([]string{"Queue", "pending"}).
+				AddRow("emails", 12).
+				AddRow(nil, 3)
+			mock.ExpectQuery("SELECT queue, COUNT\\(\\*\\) AS pending FROM app.jobs GROUP BY queue").WillReturnRows(rows)
+
+			results, err := dc.RunQuery(ctx, "SELECT queue, COUNT(*) AS pending FROM app.jobs GROUP BY queue", time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]map[string]string{
+				{"queue": "emails", "pending": "12"},
+				{"queue": "NULL", "pending": "3"},
+			}))
+		})
+
+		It("returns no rows when the query has no results", func() {
+			mock.ExpectQuery("SELECT 1").WillReturnRows(sqlmock.NewRows([]string{"1"}))
+
+			results, err := dc.RunQuery(ctx, "SELECT 1", time.Second)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(BeEmpty())
+		})
+
+		It("returns an error when the query takes longer than the timeout", func() {
+			mock.ExpectQuery("SELECT SLEEP").
+				WillDelayFor(time.Second).
+				WillReturnRows(sqlmock.NewRows([]string{"sleep"}).AddRow(0))
+
+			_, err := dc.RunQuery(ctx, "SELECT SLEEP(1)", 10*time.Millisecond)
+			Expect(err).To(MatchError(ContainSubstring("canceling query due to user request")))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery("SELECT 1").WillReturnError(errors.New("db unavailable"))
+
+			_, err := dc.RunQuery(ctx, "SELECT 1", time.Second)
+			Expect(err).To(MatchError("db unavailable"))
+		})
+	})
+
+	Describe("StatementDigests", func() {
+		It("returns the statement digest summary", func() {
+			rows := sqlmock.NewRows([]string{"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT", "COUNT_STAR", "SUM_TIMER_WAIT", "SUM_ROWS_EXAMINED", "SUM_ERRORS"}).
+				AddRow("app", "abc123", "SELECT ?", 10, 5000, 20, 0).
+				AddRow(nil, "def456", "COMMIT", 3, 100, 0, 1)
+			mock.ExpectQuery("SELECT SCHEMA_NAME, DIGEST, DIGEST_TEXT, COUNT_STAR, SUM_TIMER_WAIT, SUM_ROWS_EXAMINED, SUM_ERRORS FROM performance_schema.events_statements_summary_by_digest WHERE DIGEST IS NOT NULL").
+				WillReturnRows(rows)
+
+			results, err := dc.StatementDigests(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]map[string]string{
+				{"schema_name": "app", "digest": "abc123", "digest_text": "SELECT ?", "count_star": "10", "sum_timer_wait": "5000", "sum_rows_examined": "20", "sum_errors": "0"},
+				{"schema_name": "NULL", "digest": "def456", "digest_text": "COMMIT", "count_star": "3", "sum_timer_wait": "100", "sum_rows_examined": "0", "sum_errors": "1"},
+			}))
+		})
+
+		It("returns an error when performance_schema cannot be queried", func() {
+			mock.ExpectQuery("events_statements_summary_by_digest").WillReturnError(errors.New("performance_schema disabled"))
+
+			_, err := dc.StatementDigests(ctx)
+			Expect(err).To(MatchError("performance_schema disabled"))
+		})
+
+		It("cancels the query after the query timeout", func() {
+			config.QueryTimeout = 1
+			mock.ExpectQuery("events_statements_summary_by_digest").
+				WillDelayFor(5 * time.Second).
+				WillReturnRows(sqlmock.NewRows([]string{"DIGEST"}).AddRow("abc123"))
+
+			_, err := dc.StatementDigests(ctx)
+			Expect(err).To(MatchError(ContainSubstring("canceling query due to user request")))
+		})
+	})
+
+	Describe("TableSizes", func() {
+		It("returns the size of every base table", func() {
+			rows := sqlmock.NewRows([]string{"TABLE_SCHEMA", "TABLE_NAME", "DATA_LENGTH", "INDEX_LENGTH", "DATA_FREE", "TABLE_ROWS"}).
+				AddRow("app", "users", 16384, 16384, 0, 12)
+			mock.ExpectQuery("SELECT TABLE_SCHEMA, TABLE_NAME, DATA_LENGTH, INDEX_LENGTH, DATA_FREE, TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_TYPE = 'BASE TABLE'").
+				WillReturnRows(rows)
+
+			results, err := dc.TableSizes(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]map[string]string{
+				{"table_schema": "app", "table_name": "users", "data_length": "16384", "index_length": "16384", "data_free": "0", "table_rows": "12"},
+			}))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery("information_schema.TABLES").WillReturnError(errors.New("db unavailable"))
+
+			_, err := dc.TableSizes(ctx)
+			Expect(err).To(MatchError("db unavailable"))
+		})
+	})
+
+	Describe("InnoDBTablespaces", func() {
+		It("returns the file size of every tablespace", func() {
+			rows := sqlmock.NewRows([]string{"NAME", "FILE_SIZE"}).
+				AddRow("app/users", 114688)
+			mock.ExpectQuery("SELECT NAME, FILE_SIZE FROM information_schema.INNODB_TABLESPACES").WillReturnRows(rows)
+
+			results, err := dc.InnoDBTablespaces(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]map[string]string{{"name": "app/users", "file_size": "114688"}}))
+		})
+	})
+
+	Describe("InnoDBTransactions", func() {
+		It("returns the age of every open transaction", func() {
+			rows := sqlmock.NewRows([]string{"trx_id", "age_seconds"}).
+				AddRow("1234", 7200).
+				AddRow("1235", 3)
+			mock.ExpectQuery("SELECT trx_id, TIMESTAMPDIFF\\(SECOND, trx_started, NOW\\(\\)\\) AS age_seconds FROM information_schema.innodb_trx").
+				WillReturnRows(rows)
+
+			results, err := dc.InnoDBTransactions(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]map[string]string{
+				{"trx_id": "1234", "age_seconds": "7200"},
+				{"trx_id": "1235", "age_seconds": "3"},
+			}))
+		})
+	})
+
+	Describe("DataLockWaits", func() {
+		It("returns the requesting and blocking transaction of every lock wait", func() {
+			rows := sqlmock.NewRows([]string{"REQUESTING_ENGINE_TRANSACTION_ID", "BLOCKING_ENGINE_TRANSACTION_ID"}).
+				AddRow("1235", "1234")
+			mock.ExpectQuery("SELECT REQUESTING_ENGINE_TRANSACTION_ID, BLOCKING_ENGINE_TRANSACTION_ID FROM performance_schema.data_lock_waits").
+				WillReturnRows(rows)
+
+			results, err := dc.DataLockWaits(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal([]map[string]string{
+				{"requesting_engine_transaction_id": "1235", "blocking_engine_transaction_id": "1234"},
+			}))
+		})
+	})
+
+	Describe("MetadataLockWaiters", func() {
+		It("returns the number of pending metadata locks", func() {
+			rows := sqlmock.NewRows([]string{"metadata_lock_waiters"}).AddRow(2)
+			mock.ExpectQuery("SELECT COUNT\\(\\*\\) AS metadata_lock_waiters FROM performance_schema.metadata_locks WHERE LOCK_STATUS = 'PENDING'").
+				WillReturnRows(rows)
+
+			result, err := dc.MetadataLockWaiters(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(map[string]string{"metadata_lock_waiters": "2"}))
+		})
+
+		It("returns an error when performance_schema cannot be queried", func() {
+			mock.ExpectQuery("metadata_locks").WillReturnError(errors.New("performance_schema disabled"))
+
+			_, err := dc.MetadataLockWaiters(ctx)
+			Expect(err).To(MatchError("performance_schema disabled"))
+		})
+	})
+
+	Describe("ShowEngineInnoDBStatus", func() {
+		It("returns the status text", func() {
+			rows := sqlmock.NewRows([]string{"Type", "Name", "Status"}).
+				AddRow("InnoDB", "", "INNODB MONITOR OUTPUT")
+			mock.ExpectQuery("SHOW ENGINE INNODB STATUS").WillReturnRows(rows)
+
+			status, err := dc.ShowEngineInnoDBStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal("INNODB MONITOR OUTPUT"))
+		})
+
+		It("returns an error when the query fails", func() {
+			mock.ExpectQuery("SHOW ENGINE INNODB STATUS").WillReturnError(errors.New("access denied; you need the PROCESS privilege"))
+
+			_, err := dc.ShowEngineInnoDBStatus(ctx)
+			Expect(err).To(MatchError(ContainSubstring("PROCESS privilege")))
+		})
+	})
 })
@@ -0,0 +1,258 @@
+// Package digest tracks the statement digests summarised by
+// performance_schema.events_statements_summary_by_digest. The table holds
+// cumulative totals, so a Tracker diffs consecutive snapshots to find the
+// statements that were the most expensive between two samples.
+package digest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const picosecondsPerMillisecond = 1e9
+
+// ErrFirstSample is returned by Tracker.Sample when there is no previous
+// snapshot to compute a delta against.
+var ErrFirstSample = errors.New("this is the first statement digest sample, no delta available")
+
+// Stat is one row of events_statements_summary_by_digest.
+type Stat struct {
+	Schema       string
+	Digest       string
+	DigestText   string
+	Calls        uint64
+	TimerWait    uint64 // picoseconds
+	RowsExamined uint64
+	Errors       uint64
+}
+
+// ParseStat converts a row returned by the database client into a Stat.
+func ParseStat(row map[string]string) (Stat, error) {
+	stat := Stat{
+		Schema:     row["schema_name"],
+		Digest:     row["digest"],
+		DigestText: row["digest_text"],
+	}
+
+	var errs []error
+	for _, column := range []struct {
+		name  string
+		field *uint64
+	}{
+		{"count_star", &stat.Calls},
+		{"sum_timer_wait", &stat.TimerWait},
+		{"sum_rows_examined", &stat.RowsExamined},
+		{"sum_errors", &stat.Errors},
+	} {
+		value, err := strconv.ParseUint(row[column.name], 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest %s: invalid %s %q", stat.Digest, column.name, row[column.name]))
+			continue
+		}
+		*column.field = value
+	}
+
+	return stat, errors.Join(errs...)
+}
+
+func (s Stat) key() string {
+	return digestKey(s.Schema, s.Digest)
+}
+
+func digestKey(schema, digest string) string {
+	return schema + "\x00" + digest
+}
+
+// Delta is the activity of a single statement digest between two samples.
+type Delta struct {
+	Schema       string
+	Digest       string
+	DigestText   string
+	Calls        uint64
+	TimerWait    uint64 // picoseconds
+	RowsExamined uint64
+	Errors       uint64
+}
+
+func (d Delta) key() string {
+	return digestKey(d.Schema, d.Digest)
+}
+
+// LatencyMilliseconds returns the total time spent executing the digest.
+func (d Delta) LatencyMilliseconds() float64 {
+	return float64(d.TimerWait) / picosecondsPerMillisecond
+}
+
+// AverageLatencyMilliseconds returns the mean execution time of the digest.
+func (d Delta) AverageLatencyMilliseconds() float64 {
+	if d.Calls == 0 {
+		return 0
+	}
+	return d.LatencyMilliseconds() / float64(d.Calls)
+}
+
+// Values returns the delta as the string map consumed by the metrics computer.
+func (d Delta) Values() map[string]string {
+	return map[string]string{
+		"schema":        d.Schema,
+		"digest":        d.Digest,
+		"calls":         strconv.FormatUint(d.Calls, 10),
+		"latency":       fmt.Sprintf("%.3f", d.LatencyMilliseconds()),
+		"avg_latency":   fmt.Sprintf("%.3f", d.AverageLatencyMilliseconds()),
+		"rows_examined": strconv.FormatUint(d.RowsExamined, 10),
+		"errors":        strconv.FormatUint(d.Errors, 10),
+	}
+}
+
+// Summary holds every digest that was executed between two samples.
+type Summary struct {
+	Elapsed time.Duration
+	Digests []Delta
+}
+
+// add returns the activity of s followed by that of other, with the deltas of
+// the same digest summed.
+func (s Summary) add(other Summary) Summary {
+	sum := Summary{
+		Elapsed: s.Elapsed + other.Elapsed,
+		Digests: make([]Delta, 0, len(s.Digests)+len(other.Digests)),
+	}
+
+	indexes := make(map[string]int, len(s.Digests)+len(other.Digests))
+	for _, delta := range slices.Concat(s.Digests, other.Digests) {
+		i, ok := indexes[delta.key()]
+		if !ok {
+			indexes[delta.key()] = len(sum.Digests)
+			sum.Digests = append(sum.Digests, delta)
+			continue
+		}
+
+		sum.Digests[i].Calls += delta.Calls
+		sum.Digests[i].TimerWait += delta.TimerWait
+		sum.Digests[i].RowsExamined += delta.RowsExamined
+		sum.Digests[i].Errors += delta.Errors
+	}
+
+	return sum
+}
+
+// Ranking orders deltas from most to least significant.
+type Ranking struct {
+	Name  string
+	value func(Delta) uint64
+}
+
+var (
+	ByLatency      = Ranking{Name: "latency", value: func(d Delta) uint64 { return d.TimerWait }}
+	ByCalls        = Ranking{Name: "calls", value: func(d Delta) uint64 { return d.Calls }}
+	ByRowsExamined = Ranking{Name: "rows_examined", value: func(d Delta) uint64 { return d.RowsExamined }}
+	ByErrors       = Ranking{Name: "errors", value: func(d Delta) uint64 { return d.Errors }}
+
+	Rankings = []Ranking{ByLatency, ByCalls, ByRowsExamined, ByErrors}
+)
+
+// TopBy returns at most n digests with the highest non-zero value for the
+// ranking.
+func (s Summary) TopBy(ranking Ranking, n int) []Delta {
+	var ranked []Delta
+	for _, delta := range s.Digests {
+		if ranking.value(delta) > 0 {
+			ranked = append(ranked, delta)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranking.value(ranked[i]) > ranking.value(ranked[j])
+	})
+
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// Top returns the union of the top n digests of every ranking, ordered by
+// latency.
+func (s Summary) Top(n int) []Delta {
+	selected := make(map[string]bool)
+	for _, ranking := range Rankings {
+		for _, delta := range s.TopBy(ranking, n) {
+			selected[delta.key()] = true
+		}
+	}
+
+	var top []Delta
+	for _, delta := range s.Digests {
+		if selected[delta.key()] {
+			top = append(top, delta)
+		}
+	}
+
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].TimerWait > top[j].TimerWait
+	})
+	return top
+}
+
+// Tracker remembers the previous snapshot of the digest table.
+type Tracker struct {
+	previous     map[string]Stat
+	previousTime time.Time
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+// Sample records the current snapshot and returns the activity since the
+// previous one. Digests whose counters went backwards, because the table was
+// truncated or the digest was evicted and re-added, are counted from zero.
+func (t *Tracker) Sample(stats []Stat, now time.Time) (Summary, error) {
+	current := make(map[string]Stat, len(stats))
+	for _, stat := range stats {
+		current[stat.key()] = stat
+	}
+
+	previous, previousTime := t.previous, t.previousTime
+	t.previous, t.previousTime = current, now
+
+	if previous == nil {
+		return Summary{}, ErrFirstSample
+	}
+
+	summary := Summary{Elapsed: now.Sub(previousTime)}
+	for _, stat := range stats {
+		before := previous[stat.key()]
+		if stat.Calls < before.Calls {
+			before = Stat{}
+		}
+
+		delta := Delta{
+			Schema:       stat.Schema,
+			Digest:       stat.Digest,
+			DigestText:   stat.DigestText,
+			Calls:        stat.Calls - before.Calls,
+			TimerWait:    counterDelta(stat.TimerWait, before.TimerWait),
+			RowsExamined: counterDelta(stat.RowsExamined, before.RowsExamined),
+			Errors:       counterDelta(stat.Errors, before.Errors),
+		}
+		if delta.Calls == 0 {
+			continue
+		}
+
+		summary.Digests = append(summary.Digests, delta)
+	}
+
+	return summary, nil
+}
+
+func counterDelta(current, previous uint64) uint64 {
+	if current < previous {
+		return current
+	}
+	return current - previous
+}
@@ -0,0 +1,142 @@
+package digest_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/digest"
+)
+
+var _ = Describe("ParseStat", func() {
+	It("parses a digest summary row", func() {
+		stat, err := digest.ParseStat(map[string]string{
+			"schema_name":       "app",
+			"digest":            "abc123",
+			"digest_text":       "SELECT * FROM `t` WHERE `id` = ?",
+			"count_star":        "10",
+			"sum_timer_wait":    "5000000000",
+			"sum_rows_examined": "100",
+			"sum_errors":        "1",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stat).To(Equal(digest.Stat{
+			Schema:       "app",
+			Digest:       "abc123",
+			DigestText:   "SELECT * FROM `t` WHERE `id` = ?",
+			Calls:        10,
+			TimerWait:    5000000000,
+			RowsExamined: 100,
+			Errors:       1,
+		}))
+	})
+
+	It("reports every column that is not a number", func() {
+		_, err := digest.ParseStat(map[string]string{
+			"digest":            "abc123",
+			"count_star":        "ten",
+			"sum_timer_wait":    "5",
+			"sum_rows_examined": "NULL",
+			"sum_errors":        "0",
+		})
+		Expect(err).To(MatchError(ContainSubstring(`digest abc123: invalid count_star "ten"`)))
+		Expect(err).To(MatchError(ContainSubstring(`digest abc123: invalid sum_rows_examined "NULL"`)))
+	})
+})
+
+var _ = Describe("Tracker", func() {
+	var (
+		tracker *digest.Tracker
+		start   time.Time
+	)
+
+	BeforeEach(func() {
+		tracker = digest.NewTracker()
+		start = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	})
+
+	It("returns ErrFirstSample for the first snapshot", func() {
+		_, err := tracker.Sample([]digest.Stat{{Digest: "a", Calls: 1}}, start)
+		Expect(err).To(MatchError(digest.ErrFirstSample))
+	})
+
+	It("returns the activity of every digest executed since the previous snapshot", func() {
+		_, _ = tracker.Sample([]digest.Stat{
+			{Schema: "app", Digest: "a", Calls: 10, TimerWait: 1000, RowsExamined: 50, Errors: 1},
+			{Schema: "app", Digest: "idle", Calls: 3, TimerWait: 30},
+		}, start)
+
+		summary, err := tracker.Sample([]digest.Stat{
+			{Schema: "app", Digest: "a", Calls: 15, TimerWait: 4000, RowsExamined: 60, Errors: 1},
+			{Schema: "app", Digest: "idle", Calls: 3, TimerWait: 30},
+			{Schema: "other", Digest: "a", Calls: 2, TimerWait: 200, RowsExamined: 4},
+		}, start.Add(30*time.Second))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(summary.Elapsed).To(Equal(30 * time.Second))
+		Expect(summary.Digests).To(ConsistOf(
+			digest.Delta{Schema: "app", Digest: "a", Calls: 5, TimerWait: 3000, RowsExamined: 10},
+			digest.Delta{Schema: "other", Digest: "a", Calls: 2, TimerWait: 200, RowsExamined: 4},
+		))
+	})
+
+	It("counts digests whose counters were reset from zero", func() {
+		_, _ = tracker.Sample([]digest.Stat{{Digest: "a", Calls: 100, TimerWait: 1000}}, start)
+
+		summary, err := tracker.Sample([]digest.Stat{{Digest: "a", Calls: 4, TimerWait: 40}}, start.Add(time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Digests).To(ConsistOf(digest.Delta{Digest: "a", Calls: 4, TimerWait: 40}))
+	})
+})
+
+var _ = Describe("Summary", func() {
+	var summary digest.Summary
+
+	BeforeEach(func() {
+		summary = digest.Summary{Digests: []digest.Delta{
+			{Digest: "slow", Calls: 1, TimerWait: 9000, RowsExamined: 1},
+			{Digest: "frequent", Calls: 900, TimerWait: 900, RowsExamined: 900},
+			{Digest: "scan", Calls: 2, TimerWait: 800, RowsExamined: 100000},
+			{Digest: "failing", Calls: 3, TimerWait: 10, Errors: 3},
+			{Digest: "boring", Calls: 2, TimerWait: 5, RowsExamined: 2},
+		}}
+	})
+
+	It("ranks digests by a single ranking", func() {
+		Expect(summary.TopBy(digest.ByLatency, 2)).To(Equal([]digest.Delta{summary.Digests[0], summary.Digests[1]}))
+		Expect(summary.TopBy(digest.ByRowsExamined, 1)).To(Equal([]digest.Delta{summary.Digests[2]}))
+	})
+
+	It("leaves out digests without any activity for the ranking", func() {
+		Expect(summary.TopBy(digest.ByErrors, 5)).To(Equal([]digest.Delta{summary.Digests[3]}))
+	})
+
+	It("returns the union of the top digests of every ranking ordered by latency", func() {
+		top := summary.Top(1)
+
+		var names []string
+		for _, delta := range top {
+			names = append(names, delta.Digest)
+		}
+		Expect(names).To(Equal([]string{"slow", "frequent", "scan", "failing"}))
+	})
+})
+
+var _ = Describe("Delta", func() {
+	It("converts latency from picoseconds to milliseconds", func() {
+		delta := digest.Delta{Schema: "app", Digest: "a", Calls: 4, TimerWait: 10_000_000_000, RowsExamined: 8, Errors: 1}
+
+		Expect(delta.LatencyMilliseconds()).To(Equal(10.0))
+		Expect(delta.AverageLatencyMilliseconds()).To(Equal(2.5))
+		Expect(delta.Values()).To(Equal(map[string]string{
+			"schema":        "app",
+			"digest":        "a",
+			"calls":         "4",
+			"latency":       "10.000",
+			"avg_latency":   "2.500",
+			"rows_examined": "8",
+			"errors":        "1",
+		}))
+	})
+})
@@ -0,0 +1,106 @@
+package digest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Report is the JSON document written by a Reporter.
+type Report struct {
+	GeneratedAt    time.Time                `json:"generated_at"`
+	ElapsedSeconds float64                  `json:"elapsed_seconds"`
+	Top            map[string][]ReportEntry `json:"top"`
+}
+
+type ReportEntry struct {
+	Schema                     string  `json:"schema"`
+	Digest                     string  `json:"digest"`
+	DigestText                 string  `json:"digest_text"`
+	Calls                      uint64  `json:"calls"`
+	LatencyMilliseconds        float64 `json:"latency_ms"`
+	AverageLatencyMilliseconds float64 `json:"avg_latency_ms"`
+	RowsExamined               uint64  `json:"rows_examined"`
+	Errors                     uint64  `json:"errors"`
+}
+
+// NewReport ranks the summary's digests by every ranking, keeping at most n
+// digests per ranking.
+func NewReport(summary Summary, n int, generatedAt time.Time) Report {
+	report := Report{
+		GeneratedAt:    generatedAt,
+		ElapsedSeconds: summary.Elapsed.Seconds(),
+		Top:            make(map[string][]ReportEntry, len(Rankings)),
+	}
+
+	for _, ranking := range Rankings {
+		entries := []ReportEntry{}
+		for _, delta := range summary.TopBy(ranking, n) {
+			entries = append(entries, ReportEntry{
+				Schema:                     delta.Schema,
+				Digest:                     delta.Digest,
+				DigestText:                 delta.DigestText,
+				Calls:                      delta.Calls,
+				LatencyMilliseconds:        delta.LatencyMilliseconds(),
+				AverageLatencyMilliseconds: delta.AverageLatencyMilliseconds(),
+				RowsExamined:               delta.RowsExamined,
+				Errors:                     delta.Errors,
+			})
+		}
+		report.Top[ranking.Name] = entries
+	}
+
+	return report
+}
+
+// Reporter periodically writes a Report to a file on disk, covering the
+// activity of every summary since the previous report.
+type Reporter struct {
+	path        string
+	interval    time.Duration
+	lastWritten time.Time
+	pending     Summary
+}
+
+func NewReporter(path string, interval time.Duration) *Reporter {
+	return &Reporter{path: path, interval: interval}
+}
+
+// Write adds summary to the activity since the previous report, and writes
+// the report for that activity when at least the reporter's interval has
+// passed since the previous report. The file is replaced atomically so readers
+// never observe a partial report.
+func (r *Reporter) Write(summary Summary, n int, now time.Time) error {
+	r.pending = r.pending.add(summary)
+
+	if !r.lastWritten.IsZero() && now.Sub(r.lastWritten) < r.interval {
+		return nil
+	}
+
+	contents, err := json.MarshalIndent(NewReport(r.pending, n, now), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(contents); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpFile.Name(), r.path); err != nil {
+		return err
+	}
+
+	r.lastWritten = now
+	r.pending = Summary{}
+	return nil
+}
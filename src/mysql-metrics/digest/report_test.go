@@ -0,0 +1,111 @@
+package digest_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/digest"
+)
+
+var _ = Describe("Reporter", func() {
+	var (
+		reportPath string
+		reporter   *digest.Reporter
+		summary    digest.Summary
+		now        time.Time
+	)
+
+	readReport := func() digest.Report {
+		contents, err := os.ReadFile(reportPath)
+		Expect(err).NotTo(HaveOccurred())
+
+		var report digest.Report
+		Expect(json.Unmarshal(contents, &report)).To(Succeed())
+		return report
+	}
+
+	BeforeEach(func() {
+		reportPath = filepath.Join(GinkgoT().TempDir(), "digests.json")
+		reporter = digest.NewReporter(reportPath, 5*time.Minute)
+		now = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		summary = digest.Summary{
+			Elapsed: 30 * time.Second,
+			Digests: []digest.Delta{
+				{Schema: "app", Digest: "a", DigestText: "SELECT ?", Calls: 4, TimerWait: 10_000_000_000, RowsExamined: 8},
+			},
+		}
+	})
+
+	It("writes the top digests of every ranking", func() {
+		Expect(reporter.Write(summary, 10, now)).To(Succeed())
+
+		report := readReport()
+		Expect(report.GeneratedAt).To(Equal(now))
+		Expect(report.ElapsedSeconds).To(Equal(30.0))
+		Expect(report.Top).To(HaveKeyWithValue("latency", []digest.ReportEntry{{
+			Schema:                     "app",
+			Digest:                     "a",
+			DigestText:                 "SELECT ?",
+			Calls:                      4,
+			LatencyMilliseconds:        10,
+			AverageLatencyMilliseconds: 2.5,
+			RowsExamined:               8,
+		}}))
+		Expect(report.Top).To(HaveKeyWithValue("errors", BeEmpty()))
+	})
+
+	It("only writes a new report once the interval has passed", func() {
+		Expect(reporter.Write(summary, 10, now)).To(Succeed())
+		Expect(reporter.Write(digest.Summary{}, 10, now.Add(time.Minute))).To(Succeed())
+		Expect(readReport().GeneratedAt).To(Equal(now))
+
+		Expect(reporter.Write(digest.Summary{}, 10, now.Add(5*time.Minute))).To(Succeed())
+		Expect(readReport().GeneratedAt).To(Equal(now.Add(5 * time.Minute)))
+	})
+
+	It("reports the activity of every cycle since the previous report", func() {
+		Expect(reporter.Write(summary, 10, now)).To(Succeed())
+
+		Expect(reporter.Write(digest.Summary{
+			Elapsed: 30 * time.Second,
+			Digests: []digest.Delta{
+				{Schema: "app", Digest: "a", DigestText: "SELECT ?", Calls: 2, TimerWait: 2_000_000_000, RowsExamined: 4},
+				{Schema: "app", Digest: "b", DigestText: "UPDATE ?", Calls: 1, TimerWait: 1_000_000_000, Errors: 1},
+			},
+		}, 10, now.Add(time.Minute))).To(Succeed())
+		Expect(reporter.Write(digest.Summary{
+			Elapsed: 30 * time.Second,
+			Digests: []digest.Delta{
+				{Schema: "app", Digest: "a", DigestText: "SELECT ?", Calls: 6, TimerWait: 6_000_000_000, RowsExamined: 12},
+			},
+		}, 10, now.Add(2*time.Minute))).To(Succeed())
+		Expect(reporter.Write(digest.Summary{Elapsed: 30 * time.Second}, 10, now.Add(5*time.Minute))).To(Succeed())
+
+		report := readReport()
+		Expect(report.GeneratedAt).To(Equal(now.Add(5 * time.Minute)))
+		Expect(report.ElapsedSeconds).To(Equal(90.0))
+		Expect(report.Top["calls"]).To(Equal([]digest.ReportEntry{
+			{Schema: "app", Digest: "a", DigestText: "SELECT ?", Calls: 8, LatencyMilliseconds: 8, AverageLatencyMilliseconds: 1, RowsExamined: 16},
+			{Schema: "app", Digest: "b", DigestText: "UPDATE ?", Calls: 1, LatencyMilliseconds: 1, AverageLatencyMilliseconds: 1, Errors: 1},
+		}))
+	})
+
+	It("starts the activity of the next report once a report is written", func() {
+		Expect(reporter.Write(summary, 10, now)).To(Succeed())
+		Expect(reporter.Write(summary, 10, now.Add(5*time.Minute))).To(Succeed())
+
+		report := readReport()
+		Expect(report.ElapsedSeconds).To(Equal(30.0))
+		Expect(report.Top["calls"][0].Calls).To(Equal(uint64(4)))
+	})
+
+	It("returns an error when the report directory does not exist", func() {
+		reporter = digest.NewReporter("/path/does/not/exist/digests.json", time.Minute)
+		Expect(reporter.Write(summary, 10, now)).NotTo(Succeed())
+	})
+})
@@ -2,15 +2,17 @@
 package emitfakes
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cloudfoundry/mysql-metrics/emit"
 )
 
 type FakeProcessor struct {
-	ProcessStub        func() error
+	ProcessStub        func(context.Context) error
 	processMutex       sync.RWMutex
 	processArgsForCall []struct {
+		arg1 context.Context
 	}
 	processReturns struct {
 		result1 error
@@ -22,17 +24,18 @@ type FakeProcessor struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeProcessor) Process() error {
+func (fake *FakeProcessor) Process(arg1 context.Context) error {
 	fake.processMutex.Lock()
 	ret, specificReturn := fake.processReturnsOnCall[len(fake.processArgsForCall)]
 	fake.processArgsForCall = append(fake.processArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ProcessStub
 	fakeReturns := fake.processReturns
-	fake.recordInvocation("Process", []interface{}{})
+	fake.recordInvocation("Process", []interface{}{arg1})
 	fake.processMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
@@ -46,12 +49,19 @@ func (fake *FakeProcessor) ProcessCallCount() int {
 	return len(fake.processArgsForCall)
 }
 
-func (fake *FakeProcessor) ProcessCalls(stub func() error) {
+func (fake *FakeProcessor) ProcessCalls(stub func(context.Context) error) {
 	fake.processMutex.Lock()
 	defer fake.processMutex.Unlock()
 	fake.ProcessStub = stub
 }
 
+func (fake *FakeProcessor) ProcessArgsForCall(i int) context.Context {
+	fake.processMutex.RLock()
+	defer fake.processMutex.RUnlock()
+	argsForCall := fake.processArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeProcessor) ProcessReturns(result1 error) {
 	fake.processMutex.Lock()
 	defer fake.processMutex.Unlock()
@@ -1,6 +1,7 @@
 package emit
 
 import (
+	"context"
 	"time"
 )
 
@@ -11,31 +12,46 @@ type Logger interface {
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Processor
 type Processor interface {
-	Process() error
+	Process(ctx context.Context) error
 }
 
 type Emitter struct {
 	processor Processor
 	interval  time.Duration
-	sleeper   func(time.Duration)
+	after     func(time.Duration) <-chan time.Time
 	logger    Logger
 }
 
-func NewEmitter(processor Processor, interval time.Duration, sleeper func(time.Duration), logger Logger) Emitter {
+func NewEmitter(processor Processor, interval time.Duration, after func(time.Duration) <-chan time.Time, logger Logger) Emitter {
 	return Emitter{
 		processor: processor,
 		interval:  interval,
-		sleeper:   sleeper,
+		after:     after,
 		logger:    logger,
 	}
 }
 
-func (e Emitter) Start() {
+// Start processes metrics every interval until ctx is done. Cancelling ctx
+// does not interrupt a cycle in progress, so the metrics it collected are
+// still written before Start returns. Every cycle is given at most interval to
+// complete.
+func (e Emitter) Start(ctx context.Context) {
 	for {
-		err := e.processor.Process()
-		if err != nil {
-			e.logger.Error("error processing metrics", err)
+		e.process(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.after(e.interval):
 		}
-		e.sleeper(e.interval)
+	}
+}
+
+func (e Emitter) process(ctx context.Context) {
+	cycleCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.interval)
+	defer cancel()
+
+	if err := e.processor.Process(cycleCtx); err != nil {
+		e.logger.Error("error processing metrics", err)
 	}
 }
@@ -1,6 +1,7 @@
 package emit_test
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
@@ -12,33 +13,33 @@ import (
 	"github.com/cloudfoundry/mysql-metrics/emit/emitfakes"
 )
 
-type Sleeper struct {
+type Timer struct {
 	sync.Mutex
 	callCount              int
 	lastDurationCalledWith time.Duration
 }
 
-func (s *Sleeper) Sleep(d time.Duration) {
-	s.Lock()
-	defer s.Unlock()
+func (t *Timer) After(d time.Duration) <-chan time.Time {
+	t.Lock()
+	defer t.Unlock()
 
-	s.callCount++
-	s.lastDurationCalledWith = d
-	time.Sleep(1 * time.Millisecond)
+	t.callCount++
+	t.lastDurationCalledWith = d
+	return time.After(1 * time.Millisecond)
 }
 
-func (s *Sleeper) CallCount() int {
-	s.Lock()
-	defer s.Unlock()
+func (t *Timer) CallCount() int {
+	t.Lock()
+	defer t.Unlock()
 
-	return s.callCount
+	return t.callCount
 }
 
-func (s *Sleeper) LastDuration() time.Duration {
-	s.Lock()
-	defer s.Unlock()
+func (t *Timer) LastDuration() time.Duration {
+	t.Lock()
+	defer t.Unlock()
 
-	return s.lastDurationCalledWith
+	return t.lastDurationCalledWith
 }
 
 var _ = Describe("Emitter", func() {
@@ -46,37 +47,93 @@ var _ = Describe("Emitter", func() {
 		fakeProcessor *emitfakes.FakeProcessor
 		fakeLogger    *emitfakes.FakeLogger
 		emitter       emit.Emitter
-		sleeper       *Sleeper
+		timer         *Timer
 		sleepDuration time.Duration
+		ctx           context.Context
+		cancel        context.CancelFunc
 	)
 
 	BeforeEach(func() {
 		fakeProcessor = &emitfakes.FakeProcessor{}
 		fakeLogger = &emitfakes.FakeLogger{}
-		sleeper = &Sleeper{}
+		timer = &Timer{}
 		sleepDuration = time.Duration(2) * time.Second
 
+		ctx, cancel = context.WithCancel(context.Background())
+		DeferCleanup(func() { cancel() })
+
 		emitter = emit.NewEmitter(
 			fakeProcessor,
 			sleepDuration,
-			sleeper.Sleep,
+			timer.After,
 			fakeLogger,
 		)
 	})
 
 	It("calls processor.Process in a loop", func() {
-		Expect(sleeper.CallCount()).To(Equal(0))
+		Expect(timer.CallCount()).To(Equal(0))
 
-		go emitter.Start() // start this in the background
+		go emitter.Start(ctx) // start this in the background
 
 		Eventually(func() int {
-			return sleeper.CallCount()
+			return timer.CallCount()
 		}).Should(BeNumerically(">", 100))
 
-		Expect(sleeper.LastDuration()).To(Equal(sleepDuration))
+		Expect(timer.LastDuration()).To(Equal(sleepDuration))
 		Expect(fakeProcessor.ProcessCallCount()).To(BeNumerically(">", 100))
 	})
 
+	It("gives every cycle at most the interval to complete", func() {
+		go emitter.Start(ctx)
+
+		Eventually(fakeProcessor.ProcessCallCount).Should(BeNumerically(">", 0))
+		deadline, ok := fakeProcessor.ProcessArgsForCall(0).Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(sleepDuration), time.Second))
+	})
+
+	Context("shutting down", func() {
+		It("returns once the context is done", func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				emitter.Start(ctx)
+			}()
+
+			Eventually(fakeProcessor.ProcessCallCount).Should(BeNumerically(">", 1))
+			cancel()
+
+			Eventually(done).Should(BeClosed())
+			callCount := fakeProcessor.ProcessCallCount()
+			Consistently(fakeProcessor.ProcessCallCount).Should(Equal(callCount))
+		})
+
+		It("completes the cycle in progress", func() {
+			release := make(chan struct{})
+			var cycleErr error
+			fakeProcessor.ProcessStub = func(cycleCtx context.Context) error {
+				<-release
+				cycleErr = cycleCtx.Err()
+				return nil
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				emitter.Start(ctx)
+			}()
+
+			Eventually(fakeProcessor.ProcessCallCount).Should(Equal(1))
+			cancel()
+			Consistently(done).ShouldNot(BeClosed())
+
+			close(release)
+			Eventually(done).Should(BeClosed())
+			Expect(cycleErr).NotTo(HaveOccurred())
+			Expect(fakeProcessor.ProcessCallCount()).To(Equal(1))
+		})
+	})
+
 	Context("error cases", func() {
 		It("logs errors as they occur and continues to loop", func() {
 			errs := errors.Join(
@@ -86,7 +143,7 @@ var _ = Describe("Emitter", func() {
 			)
 
 			fakeProcessor.ProcessReturnsOnCall(0, errs)
-			go emitter.Start()
+			go emitter.Start(ctx)
 
 			Eventually(func() int {
 				return fakeProcessor.ProcessCallCount()
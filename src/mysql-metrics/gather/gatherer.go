@@ -1,23 +1,43 @@
 package gather
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/cpu"
+	"github.com/cloudfoundry/mysql-metrics/digest"
 	"github.com/cloudfoundry/mysql-metrics/diskstat"
+	"github.com/cloudfoundry/mysql-metrics/innodbstatus"
+	"github.com/cloudfoundry/mysql-metrics/mem"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . DatabaseClient
 type DatabaseClient interface {
-	ShowGlobalStatus() (map[string]string, error)
-	ShowGlobalVariables() (map[string]string, error)
-	ShowSlaveStatus() (map[string]string, error)
-	HeartbeatStatus() (map[string]string, error)
-	ServicePlansDiskAllocated() (map[string]string, error)
-	IsAvailable() bool
-	IsFollower() (bool, error)
-	FindLastBackupTimestamp() (time.Time, error)
+	ShowGlobalStatus(ctx context.Context) (map[string]string, error)
+	ShowGlobalVariables(ctx context.Context) (map[string]string, error)
+	ShowSlaveStatus(ctx context.Context) (map[string]string, error)
+	HeartbeatStatus(ctx context.Context) (map[string]string, error)
+	ServicePlansDiskAllocated(ctx context.Context) (map[string]string, error)
+	IsAvailable(ctx context.Context) bool
+	IsFollower(ctx context.Context) (bool, error)
+	FindLastBackupTimestamp(ctx context.Context) (time.Time, error)
+	RunQuery(ctx context.Context, query string, timeout time.Duration) ([]map[string]string, error)
+	StatementDigests(ctx context.Context) ([]map[string]string, error)
+	TableSizes(ctx context.Context) ([]map[string]string, error)
+	InnoDBTablespaces(ctx context.Context) ([]map[string]string, error)
+	InnoDBTransactions(ctx context.Context) ([]map[string]string, error)
+	DataLockWaits(ctx context.Context) ([]map[string]string, error)
+	MetadataLockWaiters(ctx context.Context) (map[string]string, error)
+	ShowEngineInnoDBStatus(ctx context.Context) (string, error)
 }
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Stater
@@ -32,42 +52,458 @@ type DiskstatsReader interface {
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . CpuStater
 type CpuStater interface {
-	GetPercentage() (int, error)
+	Sample() (cpu.Sample, error)
+	LoadAverage() (cpu.LoadAverage, error)
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . MemStater
+type MemStater interface {
+	MemInfo() (mem.MemInfo, error)
+	ProcessMemory(pidFile string) (mem.ProcessMemory, error)
+}
+
+type counterSample struct {
+	value     float64
+	timestamp time.Time
 }
 
 type Gatherer struct {
 	client          DatabaseClient
 	stater          Stater
 	cpuStater       CpuStater
-	previousQueries int
+	memStater       MemStater
 	diskstatsReader DiskstatsReader
+	counters        map[string][]string
+	now             func() time.Time
+
+	// mu guards the state sampled from the database, which is reset when the
+	// server changes.
+	mu               sync.Mutex
+	previousQueries  int
+	previousCounters map[string]counterSample
+	digests          *digest.Tracker
+	innoDBStatus     *innodbstatus.Status
 }
 
-func NewGatherer(client DatabaseClient, stater Stater, cpuStater CpuStater, diskstatsReader DiskstatsReader) *Gatherer {
+// NewGatherer creates a Gatherer. The values named in counters, keyed by the
+// metric mapping category they are emitted in, are treated as cumulative
+// counters, for which "<name>_delta" and "<name>_rate" values are also
+// returned.
+func NewGatherer(client DatabaseClient, stater Stater, cpuStater CpuStater, memStater MemStater, diskstatsReader DiskstatsReader, counters map[string][]string, now func() time.Time) *Gatherer {
 	return &Gatherer{
-		client:          client,
-		stater:          stater,
-		cpuStater:       cpuStater,
-		diskstatsReader: diskstatsReader,
-		previousQueries: -1,
+		client:           client,
+		stater:           stater,
+		cpuStater:        cpuStater,
+		memStater:        memStater,
+		diskstatsReader:  diskstatsReader,
+		previousQueries:  -1,
+		counters:         counters,
+		previousCounters: make(map[string]counterSample),
+		digests:          digest.NewTracker(),
+		now:              now,
+	}
+}
+
+// ResetDatabaseCounters discards the previous samples of the counters and
+// statement digests, which are not comparable with those of another server.
+// Disk and CPU samples are kept, but their counters restart with a baseline.
+func (g *Gatherer) ResetDatabaseCounters() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.previousQueries = -1
+	g.previousCounters = make(map[string]counterSample)
+	g.digests = digest.NewTracker()
+}
+
+func (g *Gatherer) FindLastBackupTimestamp(ctx context.Context) (time.Time, error) {
+	return g.client.FindLastBackupTimestamp(ctx)
+}
+
+func (g *Gatherer) CustomQuery(ctx context.Context, query config.CustomQuery) ([]map[string]string, error) {
+	return g.client.RunQuery(ctx, query.Query, query.TimeoutDuration())
+}
+
+// StatementDigests returns the statement digests executed since the previous
+// call. The first call only records a baseline and returns an empty summary.
+func (g *Gatherer) StatementDigests(ctx context.Context) (digest.Summary, error) {
+	rows, err := g.client.StatementDigests(ctx)
+	if err != nil {
+		return digest.Summary{}, err
+	}
+
+	var (
+		stats []digest.Stat
+		errs  []error
+	)
+	for _, row := range rows {
+		stat, err := digest.ParseStat(row)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		stats = append(stats, stat)
+	}
+
+	g.mu.Lock()
+	summary, err := g.digests.Sample(stats, g.now())
+	g.mu.Unlock()
+	if err != nil && !errors.Is(err, digest.ErrFirstSample) {
+		errs = append(errs, err)
+	}
+
+	return summary, errors.Join(errs...)
+}
+
+// TableSizes returns the size of every table. When includeFileSizes is set,
+// the on-disk size of every table with its own InnoDB tablespace is added.
+func (g *Gatherer) TableSizes(ctx context.Context, includeFileSizes bool) ([]tablesize.Table, error) {
+	rows, err := g.client.TableSizes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		tables []tablesize.Table
+		errs   []error
+	)
+	for _, row := range rows {
+		table, err := tablesize.ParseTable(row)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		tables = append(tables, table)
+	}
+
+	if includeFileSizes {
+		tablespaces, err := g.client.InnoDBTablespaces(ctx)
+		if err != nil {
+			errs = append(errs, err)
+		} else if err := tablesize.AddFileSizes(tables, tablespaces); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return tables, errors.Join(errs...)
+}
+
+// TransactionStats returns the number and age of the open InnoDB
+// transactions, how many are older than each of thresholds (in seconds), the
+// longest chain of transactions waiting on each other's row locks and the
+// number of metadata lock waiters. Every source is read even if another fails.
+func (g *Gatherer) TransactionStats(ctx context.Context, thresholds []int) (map[string]string, error) {
+	result := make(map[string]string)
+	var errs []error
+
+	transactions, err := g.client.InnoDBTransactions(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		ages := make([]int, 0, len(transactions))
+		for _, transaction := range transactions {
+			age, err := strconv.Atoi(transaction["age_seconds"])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("transaction %s: invalid age %q", transaction["trx_id"], transaction["age_seconds"]))
+				continue
+			}
+			ages = append(ages, age)
+		}
+
+		oldest := 0
+		for _, age := range ages {
+			oldest = max(oldest, age)
+		}
+		result["transactions_open"] = strconv.Itoa(len(ages))
+		result["oldest_transaction_age"] = strconv.Itoa(oldest)
+
+		for _, threshold := range thresholds {
+			older := 0
+			for _, age := range ages {
+				if age > threshold {
+					older++
+				}
+			}
+			result[fmt.Sprintf("transactions_older_than_%ds", threshold)] = strconv.Itoa(older)
+		}
+	}
+
+	lockWaits, err := g.client.DataLockWaits(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		result["lock_waits"] = strconv.Itoa(len(lockWaits))
+		result["lock_wait_chain_depth"] = strconv.Itoa(lockWaitChainDepth(lockWaits))
+	}
+
+	metadataLocks, err := g.client.MetadataLockWaiters(ctx)
+	if err != nil {
+		errs = append(errs, err)
+	} else if waiters, ok := metadataLocks["metadata_lock_waiters"]; ok {
+		result["metadata_lock_waiters"] = waiters
+	}
+
+	g.addCounterRates(result, "transactions")
+
+	return result, errors.Join(errs...)
+}
+
+// lockWaitChainDepth returns the number of transactions in the longest chain
+// of transactions waiting on one another, excluding the transaction at the
+// head of the chain that is not waiting itself. Cycles, which only exist until
+// InnoDB resolves the deadlock, are not followed twice.
+func lockWaitChainDepth(lockWaits []map[string]string) int {
+	blockedBy := make(map[string][]string)
+	for _, lockWait := range lockWaits {
+		requesting := lockWait["requesting_engine_transaction_id"]
+		blockedBy[requesting] = append(blockedBy[requesting], lockWait["blocking_engine_transaction_id"])
+	}
+
+	depths := make(map[string]int)
+	visiting := make(map[string]bool)
+
+	var depth func(trx string) int
+	depth = func(trx string) int {
+		if d, ok := depths[trx]; ok {
+			return d
+		}
+		if visiting[trx] {
+			return 0
+		}
+
+		visiting[trx] = true
+		d := 0
+		for _, blocker := range blockedBy[trx] {
+			d = max(d, depth(blocker)+1)
+		}
+		visiting[trx] = false
+
+		depths[trx] = d
+		return d
+	}
+
+	deepest := 0
+	for trx := range blockedBy {
+		deepest = max(deepest, depth(trx))
+	}
+	return deepest
+}
+
+// InnoDBStatus returns the values parsed from SHOW ENGINE INNODB STATUS. The
+// redo log capacity, and the checkpoint age as a percentage of it, are added
+// from the global variables.
+func (g *Gatherer) InnoDBStatus(ctx context.Context) (map[string]string, error) {
+	text, err := g.client.ShowEngineInnoDBStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := innodbstatus.Parse(text)
+	if status == nil {
+		return nil, err
+	}
+
+	g.mu.Lock()
+	g.innoDBStatus = status
+	g.mu.Unlock()
+
+	values := status.Values()
+	g.addCounterRates(values, "innodb_status")
+
+	globalVariables, variablesErr := g.client.ShowGlobalVariables(ctx)
+	if variablesErr != nil {
+		return values, errors.Join(err, variablesErr)
+	}
+
+	if capacity := redoLogCapacity(globalVariables); capacity > 0 {
+		values["redo_log_capacity"] = strconv.FormatUint(capacity, 10)
+		values["checkpoint_age_percent"] = fmt.Sprintf("%.2f", float64(status.Log.CheckpointAge())/float64(capacity)*100)
 	}
+
+	return values, err
 }
 
-func (g Gatherer) FindLastBackupTimestamp() (time.Time, error) {
-	return g.client.FindLastBackupTimestamp()
+// LatestInnoDBStatus returns the status parsed by the latest call to
+// InnoDBStatus, including the transactions of the latest deadlock, or nil when
+// it was never parsed.
+func (g *Gatherer) LatestInnoDBStatus() *innodbstatus.Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.innoDBStatus
 }
 
-func (g Gatherer) BrokerStats() (map[string]string, error) {
-	return g.client.ServicePlansDiskAllocated()
+// redoLogCapacity returns innodb_redo_log_capacity, which replaced
+// innodb_log_file_size and innodb_log_files_in_group in MySQL 8.0.30.
+func redoLogCapacity(globalVariables map[string]string) uint64 {
+	if capacity, err := strconv.ParseUint(globalVariables["innodb_redo_log_capacity"], 10, 64); err == nil {
+		return capacity
+	}
+
+	fileSize, err := strconv.ParseUint(globalVariables["innodb_log_file_size"], 10, 64)
+	if err != nil {
+		return 0
+	}
+	files, err := strconv.ParseUint(globalVariables["innodb_log_files_in_group"], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return fileSize * files
 }
-func (g Gatherer) CPUStats() (map[string]string, error) {
-	percentage, err := g.cpuStater.GetPercentage()
+
+func (g *Gatherer) BrokerStats(ctx context.Context) (map[string]string, error) {
+	values, err := g.client.ServicePlansDiskAllocated(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	g.addCounterRates(values, "broker")
+
+	return values, nil
+}
+
+// CPUStats returns the load averages and the share of the time since the
+// previous call that all cpus spent in every mode, along with the same shares
+// for every core, ordered by core number. The load averages are returned even
+// when the cpu usage cannot be, as on the first call.
+func (g *Gatherer) CPUStats() (map[string]string, []map[string]string, error) {
+	result := make(map[string]string)
+	var errs []error
+
+	loadAverage, err := g.cpuStater.LoadAverage()
+	if err != nil {
+		errs = append(errs, err)
+	} else {
+		result["load_average_1m"] = strconv.FormatFloat(loadAverage.Load1, 'f', 2, 64)
+		result["load_average_5m"] = strconv.FormatFloat(loadAverage.Load5, 'f', 2, 64)
+		result["load_average_15m"] = strconv.FormatFloat(loadAverage.Load15, 'f', 2, 64)
+	}
+
+	sample, err := g.cpuStater.Sample()
+	if err != nil {
+		g.addCounterRates(result, "cpu")
+		return result, nil, errors.Join(append(errs, err)...)
+	}
+
+	result["cpu_utilization_percent"] = strconv.Itoa(int(sample.Total.Utilization))
+	for name, value := range cpuModePercentages(sample.Total) {
+		result["cpu_"+name] = value
+	}
+
+	cores := slices.SortedFunc(maps.Keys(sample.Cores), func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+	})
+	coreStats := make([]map[string]string, 0, len(cores))
+	for _, core := range cores {
+		values := cpuModePercentages(sample.Cores[core])
+		values["core"] = core
+		values["utilization_percent"] = fmt.Sprintf("%.2f", sample.Cores[core].Utilization)
+		coreStats = append(coreStats, values)
+	}
+
+	g.addCounterRates(result, "cpu")
+
+	return result, coreStats, errors.Join(errs...)
+}
+
+// cpuModePercentages returns the share of time spent in every mode, keyed by
+// "<mode>_percent".
+func cpuModePercentages(percentages cpu.Percentages) map[string]string {
+	return map[string]string{
+		"user_percent":    fmt.Sprintf("%.2f", percentages.User),
+		"nice_percent":    fmt.Sprintf("%.2f", percentages.Nice),
+		"system_percent":  fmt.Sprintf("%.2f", percentages.System),
+		"idle_percent":    fmt.Sprintf("%.2f", percentages.Idle),
+		"iowait_percent":  fmt.Sprintf("%.2f", percentages.IOWait),
+		"irq_percent":     fmt.Sprintf("%.2f", percentages.IRQ),
+		"softirq_percent": fmt.Sprintf("%.2f", percentages.SoftIRQ),
+		"steal_percent":   fmt.Sprintf("%.2f", percentages.Steal),
+	}
+}
+
+// MemoryStats returns the memory and swap usage of the host and, when pidFile
+// is set, the memory used by the mysqld process whose pid it contains. While
+// the database is available, the RSS of mysqld is also compared with
+// innodb_buffer_pool_size. The values of the host are returned even if those
+// of mysqld cannot be read.
+func (g *Gatherer) MemoryStats(ctx context.Context, pidFile string, databaseAvailable bool) (map[string]string, error) {
+	result, err := g.memoryStats(ctx, pidFile, databaseAvailable)
+	if result != nil {
+		g.addCounterRates(result, "memory")
+	}
+
+	return result, err
+}
+
+func (g *Gatherer) memoryStats(ctx context.Context, pidFile string, databaseAvailable bool) (map[string]string, error) {
+	memInfo, err := g.memStater.MemInfo()
 	if err != nil {
 		return nil, err
 	}
-	return map[string]string{"cpu_utilization_percent": strconv.Itoa(percentage)}, err
+
+	result := map[string]string{
+		"memory_total":             strconv.FormatUint(memInfo.MemTotal, 10),
+		"memory_available":         strconv.FormatUint(memInfo.MemAvailable, 10),
+		"memory_available_percent": formatPercent(memInfo.MemAvailable, memInfo.MemTotal),
+		"swap_total":               strconv.FormatUint(memInfo.SwapTotal, 10),
+		"swap_used":                strconv.FormatUint(memInfo.SwapUsed(), 10),
+		"swap_used_percent":        formatPercent(memInfo.SwapUsed(), memInfo.SwapTotal),
+	}
+
+	if pidFile == "" {
+		return result, nil
+	}
+
+	// Without smaps_rollup, the values of the process status are still
+	// emitted, and only the error is returned along with the others.
+	var collectedErrors error
+	process, err := g.memStater.ProcessMemory(pidFile)
+	if err != nil {
+		err = fmt.Errorf("failed to read mysqld memory usage: %w", err)
+		if !errors.Is(err, mem.ErrSmapsRollup) {
+			return result, err
+		}
+		collectedErrors = err
+	}
+
+	result["mysqld_rss"] = strconv.FormatUint(process.RSS, 10)
+	result["mysqld_rss_percent"] = formatPercent(process.RSS, memInfo.MemTotal)
+	result["mysqld_peak_rss"] = strconv.FormatUint(process.PeakRSS, 10)
+	result["mysqld_swap"] = strconv.FormatUint(process.Swap, 10)
+	if process.Rollup != nil {
+		result["mysqld_pss"] = strconv.FormatUint(process.Rollup.PSS, 10)
+		result["mysqld_anonymous"] = strconv.FormatUint(process.Rollup.Anonymous, 10)
+	}
+
+	if !databaseAvailable {
+		return result, collectedErrors
+	}
+
+	globalVariables, err := g.client.ShowGlobalVariables(ctx)
+	if err != nil {
+		return result, errors.Join(collectedErrors, err)
+	}
+
+	bufferPoolSize, err := strconv.ParseUint(globalVariables["innodb_buffer_pool_size"], 10, 64)
+	if err != nil || bufferPoolSize == 0 {
+		return result, errors.Join(collectedErrors, fmt.Errorf("invalid innodb_buffer_pool_size %q", globalVariables["innodb_buffer_pool_size"]))
+	}
+	result["mysqld_rss_buffer_pool_ratio"] = fmt.Sprintf("%.2f", float64(process.RSS)*1024/float64(bufferPoolSize))
+
+	return result, collectedErrors
 }
-func (g Gatherer) DiskStats() (map[string]string, error) {
+
+// formatPercent returns numerator as a percentage of denominator, which is 0
+// when denominator is, such as the swap used on a host without swap.
+func formatPercent(numerator, denominator uint64) string {
+	if denominator == 0 {
+		return "0.00"
+	}
+
+	return fmt.Sprintf("%.2f", float64(numerator)/float64(denominator)*100)
+}
+
+func (g *Gatherer) DiskStats() (map[string]string, error) {
 	bytesFreePersistent, bytesTotalPersistent, inodesFreePersistent, inodesTotalPersistent, err := g.stater.Stats("/var/vcap/store")
 	if err != nil {
 		return nil, err
@@ -82,7 +518,7 @@ func (g Gatherer) DiskStats() (map[string]string, error) {
 	ephemeralDiskUsedBytes := bytesTotalEphemeral - bytesFreeEphemeral
 	persistentInodesUsed := inodesTotalPersistent - inodesFreePersistent
 	ephemeralInodesUsed := inodesTotalEphemeral - inodesFreeEphemeral
-	return map[string]string{
+	result := map[string]string{
 		"persistent_disk_used":                strconv.FormatUint(persistentDiskUsedBytes/1024, 10),
 		"persistent_disk_free":                strconv.FormatUint(bytesFreePersistent/1024, 10),
 		"persistent_disk_used_percent":        strconv.FormatUint(g.calculateWholePercent(persistentDiskUsedBytes, bytesTotalPersistent), 10),
@@ -95,10 +531,14 @@ func (g Gatherer) DiskStats() (map[string]string, error) {
 		"ephemeral_disk_inodes_used":          strconv.FormatUint(ephemeralInodesUsed, 10),
 		"ephemeral_disk_inodes_free":          strconv.FormatUint(inodesFreeEphemeral, 10),
 		"ephemeral_disk_inodes_used_percent":  strconv.FormatUint(g.calculateWholePercent(ephemeralInodesUsed, inodesTotalEphemeral), 10),
-	}, nil
+	}
+
+	g.addCounterRates(result, "disk_usage")
+
+	return result, nil
 }
 
-func (g Gatherer) DiskPerformanceStats() (map[string]string, error) {
+func (g *Gatherer) DiskPerformanceStats() (map[string]string, error) {
 	samples, err := g.diskstatsReader.SampleMultiple([]string{"/var/vcap/data", "/var/vcap/store"})
 
 	result := make(map[string]string)
@@ -117,34 +557,37 @@ func (g Gatherer) DiskPerformanceStats() (map[string]string, error) {
 		result["ephemeral_disk_write_iops"] = fmt.Sprintf("%.2f", ephemeralSample.WritesPerSecond())
 	}
 
+	g.addCounterRates(result, "disk_performance")
+
 	return result, err
 }
 
-func (Gatherer) calculateWholePercent(numerator, denominator uint64) uint64 {
+func (*Gatherer) calculateWholePercent(numerator, denominator uint64) uint64 {
 	numeratorFloat := float64(numerator)
 	denominatorFloat := float64(denominator)
 	return uint64((numeratorFloat / denominatorFloat) * 100)
 }
 
-func (g Gatherer) IsDatabaseAvailable() bool {
-	return g.client.IsAvailable()
+func (g *Gatherer) IsDatabaseAvailable(ctx context.Context) bool {
+	return g.client.IsAvailable(ctx)
 }
 
-func (g Gatherer) IsDatabaseFollower() (bool, error) {
-	return g.client.IsFollower()
+func (g *Gatherer) IsDatabaseFollower(ctx context.Context) (bool, error) {
+	return g.client.IsFollower(ctx)
 }
 
-func (g *Gatherer) DatabaseMetadata() (globalStatus map[string]string, globalVariables map[string]string, err error) {
-	globalStatus, err = g.client.ShowGlobalStatus()
+func (g *Gatherer) DatabaseMetadata(ctx context.Context) (globalStatus map[string]string, globalVariables map[string]string, err error) {
+	globalStatus, err = g.client.ShowGlobalStatus(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	globalVariables, err = g.client.ShowGlobalVariables()
+	globalVariables, err = g.client.ShowGlobalVariables(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	g.mu.Lock()
 	currentQueries := -1
 
 	if currentQueriesString, ok := globalStatus["queries"]; ok {
@@ -163,17 +606,68 @@ func (g *Gatherer) DatabaseMetadata() (globalStatus map[string]string, globalVar
 	}
 
 	g.previousQueries = currentQueries
+	g.mu.Unlock()
+
+	g.addCounterRates(globalStatus, "mysql", "galera")
 
 	return
 }
 
-func (g Gatherer) FollowerMetadata() (slaveStatus map[string]string, heartbeatStatus map[string]string, err error) {
-	slaveStatus, err = g.client.ShowSlaveStatus()
+// addCounterRates adds the change since the previous sample of every counter
+// of categories to values. Nothing is added for the first sample of a counter,
+// nor when the counter went backwards because mysqld was restarted or its
+// status was flushed; the new value becomes the baseline for the next sample.
+func (g *Gatherer) addCounterRates(values map[string]string, categories ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	seen := make(map[string]bool)
+
+	for _, category := range categories {
+		for _, name := range g.counters[category] {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			sampleKey := category + "/" + name
+
+			rawValue, ok := values[name]
+			if !ok {
+				delete(g.previousCounters, sampleKey)
+				continue
+			}
+
+			value, err := strconv.ParseFloat(rawValue, 64)
+			if err != nil {
+				delete(g.previousCounters, sampleKey)
+				continue
+			}
+
+			previous, ok := g.previousCounters[sampleKey]
+			g.previousCounters[sampleKey] = counterSample{value: value, timestamp: now}
+
+			if !ok || value < previous.value {
+				continue
+			}
+
+			delta := value - previous.value
+			values[name+"_delta"] = strconv.FormatFloat(delta, 'f', -1, 64)
+
+			if elapsed := now.Sub(previous.timestamp).Seconds(); elapsed > 0 {
+				values[name+"_rate"] = fmt.Sprintf("%.2f", delta/elapsed)
+			}
+		}
+	}
+}
+
+func (g *Gatherer) FollowerMetadata(ctx context.Context) (slaveStatus map[string]string, heartbeatStatus map[string]string, err error) {
+	slaveStatus, err = g.client.ShowSlaveStatus(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	heartbeatStatus, err = g.client.HeartbeatStatus()
+	heartbeatStatus, err = g.client.HeartbeatStatus(ctx)
 	if err != nil {
 		return slaveStatus, nil, err
 	}
@@ -1,17 +1,25 @@
 package gather_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/procfs/blockdevice"
 
+	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/cpu"
+	"github.com/cloudfoundry/mysql-metrics/digest"
 	"github.com/cloudfoundry/mysql-metrics/diskstat"
 	"github.com/cloudfoundry/mysql-metrics/gather"
 	"github.com/cloudfoundry/mysql-metrics/gather/gatherfakes"
+	"github.com/cloudfoundry/mysql-metrics/mem"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
 )
 
 var _ = Describe("Gatherer", func() {
@@ -19,16 +27,22 @@ var _ = Describe("Gatherer", func() {
 		databaseClient  *gatherfakes.FakeDatabaseClient
 		stater          *gatherfakes.FakeStater
 		cpustater       *gatherfakes.FakeCpuStater
+		memstater       *gatherfakes.FakeMemStater
 		diskstatsReader *gatherfakes.FakeDiskstatsReader
 		gatherer        *gather.Gatherer
+		now             time.Time
+		ctx             context.Context
 	)
 
 	BeforeEach(func() {
 		databaseClient = &gatherfakes.FakeDatabaseClient{}
 		stater = &gatherfakes.FakeStater{}
 		cpustater = &gatherfakes.FakeCpuStater{}
+		memstater = &gatherfakes.FakeMemStater{}
 		diskstatsReader = &gatherfakes.FakeDiskstatsReader{}
-		gatherer = gather.NewGatherer(databaseClient, stater, cpustater, diskstatsReader)
+		now = time.Unix(1700000000, 0)
+		ctx = context.Background()
+		gatherer = gather.NewGatherer(databaseClient, stater, cpustater, memstater, diskstatsReader, map[string][]string{"mysql": {"com_select", "slow_queries"}, "galera": {"com_select"}, "innodb_status": {"semaphore_signal_count"}}, func() time.Time { return now })
 	})
 
 	Describe("BrokerStats", func() {
@@ -39,7 +53,7 @@ var _ = Describe("Gatherer", func() {
 
 			databaseClient.ServicePlansDiskAllocatedReturns(diskAllocatedMap, nil)
 
-			brokerStats, err := gatherer.BrokerStats()
+			brokerStats, err := gatherer.BrokerStats(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(brokerStats).To(Equal(diskAllocatedMap))
@@ -49,7 +63,7 @@ var _ = Describe("Gatherer", func() {
 			It("returns an error when there is an error fetching broker stats", func() {
 				databaseClient.ServicePlansDiskAllocatedReturns(nil, errors.New("db error"))
 
-				_, err := gatherer.BrokerStats()
+				_, err := gatherer.BrokerStats(ctx)
 				Expect(err).To(MatchError("db error"))
 
 				Expect(databaseClient.ServicePlansDiskAllocatedCallCount()).To(Equal(1))
@@ -58,22 +72,195 @@ var _ = Describe("Gatherer", func() {
 	})
 
 	Describe("CpuStats", func() {
-		It("returns cpu usage", func() {
-			cpustater.GetPercentageReturns(7, nil)
+		BeforeEach(func() {
+			cpustater.SampleReturns(cpu.Sample{
+				Total: cpu.Percentages{Utilization: 7.9, User: 5.25, System: 2.65, Idle: 84.1, IOWait: 8, Steal: 0.005},
+				Cores: map[string]cpu.Percentages{
+					"10": {Utilization: 1},
+					"0":  {Utilization: 12.5, User: 10, System: 2.5, Idle: 87.5},
+					"2":  {Utilization: 3},
+				},
+			}, nil)
+			cpustater.LoadAverageReturns(cpu.LoadAverage{Load1: 1.52, Load5: 0.97, Load15: 0.6}, nil)
+		})
+
+		It("returns cpu usage by mode and the load averages", func() {
+			cpuStats, _, err := gatherer.CPUStats()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cpuStats).To(Equal(map[string]string{
+				"cpu_utilization_percent": "7",
+				"cpu_user_percent":        "5.25",
+				"cpu_nice_percent":        "0.00",
+				"cpu_system_percent":      "2.65",
+				"cpu_idle_percent":        "84.10",
+				"cpu_iowait_percent":      "8.00",
+				"cpu_irq_percent":         "0.00",
+				"cpu_softirq_percent":     "0.00",
+				"cpu_steal_percent":       "0.01",
+				"load_average_1m":         "1.52",
+				"load_average_5m":         "0.97",
+				"load_average_15m":        "0.60",
+			}))
+		})
+
+		It("returns the cpu usage of every core in order", func() {
+			_, coreStats, err := gatherer.CPUStats()
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(coreStats).To(HaveLen(3))
+			Expect(coreStats[0]).To(Equal(map[string]string{
+				"core":                "0",
+				"utilization_percent": "12.50",
+				"user_percent":        "10.00",
+				"nice_percent":        "0.00",
+				"system_percent":      "2.50",
+				"idle_percent":        "87.50",
+				"iowait_percent":      "0.00",
+				"irq_percent":         "0.00",
+				"softirq_percent":     "0.00",
+				"steal_percent":       "0.00",
+			}))
+			Expect(coreStats[1]).To(HaveKeyWithValue("core", "2"))
+			Expect(coreStats[2]).To(HaveKeyWithValue("core", "10"))
+		})
+
+		Context("error cases", func() {
+			It("returns the load averages when there is no previous cpu sample", func() {
+				cpustater.SampleReturns(cpu.Sample{}, cpu.ErrNoPreviousData)
+
+				cpuStats, coreStats, err := gatherer.CPUStats()
+				Expect(err).To(MatchError(cpu.ErrNoPreviousData))
+				Expect(cpuStats).To(Equal(map[string]string{
+					"load_average_1m":  "1.52",
+					"load_average_5m":  "0.97",
+					"load_average_15m": "0.60",
+				}))
+				Expect(coreStats).To(BeEmpty())
+			})
+
+			It("returns the cpu usage when the load averages cannot be read", func() {
+				cpustater.LoadAverageReturns(cpu.LoadAverage{}, errors.New("loadavg error"))
+
+				cpuStats, _, err := gatherer.CPUStats()
+				Expect(err).To(MatchError("loadavg error"))
+				Expect(cpuStats).To(HaveKeyWithValue("cpu_utilization_percent", "7"))
+				Expect(cpuStats).NotTo(HaveKey("load_average_1m"))
+			})
+		})
+	})
+
+	Describe("MemoryStats", func() {
+		BeforeEach(func() {
+			memstater.MemInfoReturns(mem.MemInfo{
+				MemTotal:     8000000,
+				MemAvailable: 2000000,
+				SwapTotal:    2000000,
+				SwapFree:     1500000,
+			}, nil)
+			memstater.ProcessMemoryReturns(mem.ProcessMemory{
+				RSS:     5000000,
+				PeakRSS: 5400000,
+				Swap:    200000,
+				Rollup:  &mem.SmapsRollup{RSS: 5000000, PSS: 4900000, Anonymous: 4800000, Swap: 200000},
+			}, nil)
+			databaseClient.ShowGlobalVariablesReturns(map[string]string{"innodb_buffer_pool_size": "4096000000"}, nil)
+		})
 
-			cpuStats, err := gatherer.CPUStats()
+		It("returns the memory usage of the host and of mysqld", func() {
+			memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", true)
 			Expect(err).NotTo(HaveOccurred())
 
-			Expect(cpuStats).To(Equal(map[string]string{"cpu_utilization_percent": "7"}))
+			Expect(memoryStats).To(Equal(map[string]string{
+				"memory_total":                 "8000000",
+				"memory_available":             "2000000",
+				"memory_available_percent":     "25.00",
+				"swap_total":                   "2000000",
+				"swap_used":                    "500000",
+				"swap_used_percent":            "25.00",
+				"mysqld_rss":                   "5000000",
+				"mysqld_rss_percent":           "62.50",
+				"mysqld_peak_rss":              "5400000",
+				"mysqld_swap":                  "200000",
+				"mysqld_pss":                   "4900000",
+				"mysqld_anonymous":             "4800000",
+				"mysqld_rss_buffer_pool_ratio": "1.25",
+			}))
+			Expect(memstater.ProcessMemoryArgsForCall(0)).To(Equal("/var/vcap/sys/run/pxc-mysql/mysqld.pid"))
+		})
+
+		It("returns only the memory usage of the host when no pid file is configured", func() {
+			memoryStats, err := gatherer.MemoryStats(ctx, "", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(memoryStats).To(HaveLen(6))
+			Expect(memstater.ProcessMemoryCallCount()).To(BeZero())
+		})
+
+		It("does not compare the RSS with the buffer pool while the database is unavailable", func() {
+			memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(memoryStats).To(HaveKeyWithValue("mysqld_rss", "5000000"))
+			Expect(memoryStats).NotTo(HaveKey("mysqld_rss_buffer_pool_ratio"))
+			Expect(databaseClient.ShowGlobalVariablesCallCount()).To(BeZero())
+		})
+
+		It("reports no swap used on a host without swap", func() {
+			memstater.MemInfoReturns(mem.MemInfo{MemTotal: 8000000, MemAvailable: 2000000}, nil)
+
+			memoryStats, err := gatherer.MemoryStats(ctx, "", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memoryStats).To(HaveKeyWithValue("swap_used_percent", "0.00"))
+		})
+
+		It("leaves out the smaps_rollup values when they are unavailable", func() {
+			memstater.ProcessMemoryReturns(mem.ProcessMemory{RSS: 5000000}, nil)
+
+			memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memoryStats).NotTo(HaveKey("mysqld_pss"))
+			Expect(memoryStats).NotTo(HaveKey("mysqld_anonymous"))
 		})
 
 		Context("error cases", func() {
-			It("returns an error when there is an error fetching cpu stats", func() {
-				cpustater.GetPercentageReturns(-1, errors.New("cpu stats error"))
+			It("returns an error when the memory usage of the host cannot be read", func() {
+				memstater.MemInfoReturns(mem.MemInfo{}, errors.New("meminfo error"))
+
+				memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", true)
+				Expect(err).To(MatchError("meminfo error"))
+				Expect(memoryStats).To(BeEmpty())
+			})
+
+			It("returns the memory usage of the host when that of mysqld cannot be read", func() {
+				memstater.ProcessMemoryReturns(mem.ProcessMemory{}, errors.New("no such process"))
+
+				memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", true)
+				Expect(err).To(MatchError("failed to read mysqld memory usage: no such process"))
+				Expect(memoryStats).To(HaveLen(6))
+			})
+
+			It("returns the values of the process status when smaps_rollup cannot be read", func() {
+				rollupErr := fmt.Errorf("%w: permission denied", mem.ErrSmapsRollup)
+				memstater.ProcessMemoryReturns(mem.ProcessMemory{RSS: 5000000, PeakRSS: 6000000, Swap: 1024}, rollupErr)
+				databaseClient.ShowGlobalVariablesReturns(map[string]string{}, nil)
+
+				memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", true)
+				Expect(err).To(MatchError(mem.ErrSmapsRollup))
+				Expect(err).To(MatchError(ContainSubstring(`invalid innodb_buffer_pool_size ""`)))
+				Expect(memoryStats).To(HaveKeyWithValue("mysqld_rss", "5000000"))
+				Expect(memoryStats).To(HaveKey("mysqld_rss_percent"))
+				Expect(memoryStats).To(HaveKeyWithValue("mysqld_peak_rss", "6000000"))
+				Expect(memoryStats).To(HaveKeyWithValue("mysqld_swap", "1024"))
+				Expect(memoryStats).NotTo(HaveKey("mysqld_pss"))
+			})
+
+			It("returns an error when innodb_buffer_pool_size is invalid", func() {
+				databaseClient.ShowGlobalVariablesReturns(map[string]string{}, nil)
 
-				cpuStats, err := gatherer.CPUStats()
-				Expect(err).To(MatchError("cpu stats error"))
-				Expect(cpuStats).To(BeEmpty())
+				memoryStats, err := gatherer.MemoryStats(ctx, "/var/vcap/sys/run/pxc-mysql/mysqld.pid", true)
+				Expect(err).To(MatchError(`invalid innodb_buffer_pool_size ""`))
+				Expect(memoryStats).To(HaveKey("mysqld_rss"))
 			})
 		})
 	})
@@ -221,7 +408,7 @@ var _ = Describe("Gatherer", func() {
 		It("returns true if the database is a follower node", func() {
 			databaseClient.IsFollowerReturns(true, nil)
 
-			isFollower, err := gatherer.IsDatabaseFollower()
+			isFollower, err := gatherer.IsDatabaseFollower(ctx)
 			Expect(isFollower).To(BeTrue())
 			Expect(err).NotTo(HaveOccurred())
 
@@ -232,7 +419,7 @@ var _ = Describe("Gatherer", func() {
 			It("returns an error", func() {
 				databaseClient.IsFollowerReturns(false, errors.New("could not determine follower state"))
 
-				_, err := gatherer.IsDatabaseFollower()
+				_, err := gatherer.IsDatabaseFollower(ctx)
 				Expect(err).To(MatchError("could not determine follower state"))
 
 				Expect(databaseClient.IsFollowerCallCount()).To(Equal(1))
@@ -245,7 +432,7 @@ var _ = Describe("Gatherer", func() {
 		It("returns true if the database is available", func() {
 			databaseClient.IsAvailableReturns(true)
 
-			isAvailable := gatherer.IsDatabaseAvailable()
+			isAvailable := gatherer.IsDatabaseAvailable(ctx)
 			Expect(isAvailable).To(BeTrue())
 
 			Expect(databaseClient.IsAvailableCallCount()).To(Equal(1))
@@ -264,7 +451,7 @@ var _ = Describe("Gatherer", func() {
 			databaseClient.ShowSlaveStatusReturns(slaveStatusMap, nil)
 			databaseClient.HeartbeatStatusReturns(heartbeatStatusMap, nil)
 
-			slaveStatus, heartbeatStatus, err := gatherer.FollowerMetadata()
+			slaveStatus, heartbeatStatus, err := gatherer.FollowerMetadata(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(slaveStatus).To(Equal(slaveStatusMap))
@@ -275,7 +462,7 @@ var _ = Describe("Gatherer", func() {
 			It("returns an errors when ShowSlaveStatus fails", func() {
 				databaseClient.ShowSlaveStatusReturns(nil, errors.New("ShowSlaveStatus failed"))
 
-				_, _, err := gatherer.FollowerMetadata()
+				_, _, err := gatherer.FollowerMetadata(ctx)
 				Expect(err).To(MatchError("ShowSlaveStatus failed"))
 			})
 
@@ -287,7 +474,7 @@ var _ = Describe("Gatherer", func() {
 				databaseClient.ShowSlaveStatusReturns(slaveStatusMap, nil)
 				databaseClient.HeartbeatStatusReturns(nil, errors.New("HeartbeatStatus failed"))
 
-				slaveStatus, _, err := gatherer.FollowerMetadata()
+				slaveStatus, _, err := gatherer.FollowerMetadata(ctx)
 				Expect(err).To(MatchError("HeartbeatStatus failed"))
 				Expect(slaveStatus).To(Equal(slaveStatusMap))
 			})
@@ -307,7 +494,7 @@ var _ = Describe("Gatherer", func() {
 			databaseClient.ShowGlobalStatusReturns(globalStatusMap, nil)
 			databaseClient.ShowGlobalVariablesReturns(globalVariablesMap, nil)
 
-			globalStatus, globalVariables, err := gatherer.DatabaseMetadata()
+			globalStatus, globalVariables, err := gatherer.DatabaseMetadata(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(globalStatus).To(Equal(globalStatusMap))
@@ -318,33 +505,141 @@ var _ = Describe("Gatherer", func() {
 			It("returns an errors when ShowGlobalStatus fails", func() {
 				databaseClient.ShowGlobalStatusReturns(nil, errors.New("ShowGlobalStatus failed"))
 
-				_, _, err := gatherer.DatabaseMetadata()
+				_, _, err := gatherer.DatabaseMetadata(ctx)
 				Expect(err).To(MatchError("ShowGlobalStatus failed"))
 			})
 
 			It("returns an errors when ShowGlobalVariables fails", func() {
 				databaseClient.ShowGlobalVariablesReturns(nil, errors.New("ShowGlobalVariables failed"))
 
-				_, _, err := gatherer.DatabaseMetadata()
+				_, _, err := gatherer.DatabaseMetadata(ctx)
 				Expect(err).To(MatchError("ShowGlobalVariables failed"))
 			})
 		})
 
+		Context("counters", func() {
+			It("returns the delta and per second rate of every counter", func() {
+				databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"com_select": "100", "slow_queries": "2", "threads_running": "7"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"com_select": "400", "slow_queries": "2", "threads_running": "9"}, nil)
+
+				globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).To(Equal(map[string]string{
+					"com_select":      "100",
+					"slow_queries":    "2",
+					"threads_running": "7",
+					"queries_delta":   "0",
+				}))
+
+				now = now.Add(30 * time.Second)
+
+				globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).To(Equal(map[string]string{
+					"com_select":         "400",
+					"com_select_delta":   "300",
+					"com_select_rate":    "10.00",
+					"slow_queries":       "2",
+					"slow_queries_delta": "0",
+					"slow_queries_rate":  "0.00",
+					"threads_running":    "9",
+					"queries_delta":      "0",
+				}))
+			})
+
+			It("starts over when a counter goes backwards due to a mysql restart", func() {
+				databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"com_select": "1000"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"com_select": "10"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(2, map[string]string{"com_select": "70"}, nil)
+
+				_, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				now = now.Add(30 * time.Second)
+				globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).NotTo(HaveKey("com_select_delta"))
+				Expect(globalStatus).NotTo(HaveKey("com_select_rate"))
+
+				now = now.Add(30 * time.Second)
+				globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).To(HaveKeyWithValue("com_select_delta", "60"))
+				Expect(globalStatus).To(HaveKeyWithValue("com_select_rate", "2.00"))
+			})
+
+			It("starts over when a counter is missing or cannot be parsed", func() {
+				databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"com_select": "100", "slow_queries": "1"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"slow_queries": "Nope"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(2, map[string]string{"com_select": "200", "slow_queries": "5"}, nil)
+
+				_, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				now = now.Add(30 * time.Second)
+				globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).To(Equal(map[string]string{"slow_queries": "Nope", "queries_delta": "0"}))
+
+				now = now.Add(30 * time.Second)
+				globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).NotTo(HaveKey("com_select_delta"))
+				Expect(globalStatus).NotTo(HaveKey("slow_queries_delta"))
+			})
+
+			It("returns the delta but no rate when no time has passed", func() {
+				databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"com_select": "100"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"com_select": "110"}, nil)
+
+				_, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).To(HaveKeyWithValue("com_select_delta", "10"))
+				Expect(globalStatus).NotTo(HaveKey("com_select_rate"))
+			})
+
+			It("starts over when the database counters are reset", func() {
+				databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"com_select": "100", "queries": "50"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"com_select": "400", "queries": "90"}, nil)
+				databaseClient.ShowGlobalStatusReturnsOnCall(2, map[string]string{"com_select": "460", "queries": "95"}, nil)
+
+				_, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+
+				gatherer.ResetDatabaseCounters()
+
+				now = now.Add(30 * time.Second)
+				globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).NotTo(HaveKey("com_select_delta"))
+				Expect(globalStatus).NotTo(HaveKey("queries_delta"))
+
+				now = now.Add(30 * time.Second)
+				globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(globalStatus).To(HaveKeyWithValue("com_select_delta", "60"))
+				Expect(globalStatus).To(HaveKeyWithValue("queries_delta", "5"))
+			})
+		})
+
 		Context("queries_delta", func() {
 			It("returns the queries_delta metric with the difference between queries", func() {
 				databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"queries": "0"}, nil)
 				databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"queries": "5"}, nil)
 				databaseClient.ShowGlobalStatusReturnsOnCall(2, map[string]string{"queries": "16"}, nil)
 
-				globalStatus, _, err := gatherer.DatabaseMetadata()
+				globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(globalStatus).ToNot(HaveKey("queries_delta"))
 
-				globalStatus, _, err = gatherer.DatabaseMetadata()
+				globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(globalStatus).To(HaveKeyWithValue("queries_delta", "5"))
 
-				globalStatus, _, err = gatherer.DatabaseMetadata()
+				globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(globalStatus).To(HaveKeyWithValue("queries_delta", "11"))
 			})
@@ -353,7 +648,7 @@ var _ = Describe("Gatherer", func() {
 				It("returns queries_delta of 0", func() {
 					databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{"queries": "%%%%%"}, nil)
 
-					globalStatus, _, err := gatherer.DatabaseMetadata()
+					globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(globalStatus).To(HaveKeyWithValue("queries_delta", "0"))
 				})
@@ -363,7 +658,7 @@ var _ = Describe("Gatherer", func() {
 				It("returns queries_delta of 0", func() {
 					databaseClient.ShowGlobalStatusReturnsOnCall(0, map[string]string{}, nil)
 
-					globalStatus, _, err := gatherer.DatabaseMetadata()
+					globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(globalStatus).To(HaveKeyWithValue("queries_delta", "0"))
 				})
@@ -375,15 +670,15 @@ var _ = Describe("Gatherer", func() {
 					databaseClient.ShowGlobalStatusReturnsOnCall(1, map[string]string{"queries": "1000"}, nil)
 					databaseClient.ShowGlobalStatusReturnsOnCall(2, map[string]string{"queries": "0"}, nil)
 
-					globalStatus, _, err := gatherer.DatabaseMetadata()
+					globalStatus, _, err := gatherer.DatabaseMetadata(ctx)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(globalStatus).ToNot(HaveKey("queries_delta"))
 
-					globalStatus, _, err = gatherer.DatabaseMetadata()
+					globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(globalStatus).To(HaveKeyWithValue("queries_delta", "1000"))
 
-					globalStatus, _, err = gatherer.DatabaseMetadata()
+					globalStatus, _, err = gatherer.DatabaseMetadata(ctx)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(globalStatus).ToNot(HaveKey("queries_delta"))
 				})
@@ -397,10 +692,281 @@ var _ = Describe("Gatherer", func() {
 			expectedTimestamp := time.Now()
 			databaseClient.FindLastBackupTimestampReturns(expectedTimestamp, nil)
 
-			actualTimestamp, err := gatherer.FindLastBackupTimestamp()
+			actualTimestamp, err := gatherer.FindLastBackupTimestamp(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(expectedTimestamp).To(Equal(actualTimestamp))
 		})
 	})
+
+	Describe("CustomQuery", func() {
+		It("runs the query with its timeout", func() {
+			rows := []map[string]string{{"queue": "emails", "pending": "12"}}
+			databaseClient.RunQueryReturns(rows, nil)
+
+			results, err := gatherer.CustomQuery(ctx, config.CustomQuery{
+				Name:    "queue_depth",
+				Query:   "SELECT queue, COUNT(*) AS pending FROM app.jobs GROUP BY queue",
+				Timeout: 3,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(results).To(Equal(rows))
+
+			Expect(databaseClient.RunQueryCallCount()).To(Equal(1))
+			queryCtx, query, timeout := databaseClient.RunQueryArgsForCall(0)
+			Expect(queryCtx).To(Equal(ctx))
+			Expect(query).To(Equal("SELECT queue, COUNT(*) AS pending FROM app.jobs GROUP BY queue"))
+			Expect(timeout).To(Equal(3 * time.Second))
+		})
+
+		It("returns an error when the query fails", func() {
+			databaseClient.RunQueryReturns(nil, errors.New("db error"))
+
+			_, err := gatherer.CustomQuery(ctx, config.CustomQuery{Name: "queue_depth", Query: "SELECT 1"})
+			Expect(err).To(MatchError("db error"))
+		})
+	})
+
+	Describe("StatementDigests", func() {
+		digestRow := func(digest, calls, timerWait string) map[string]string {
+			return map[string]string{
+				"schema_name":       "app",
+				"digest":            digest,
+				"digest_text":       "SELECT ?",
+				"count_star":        calls,
+				"sum_timer_wait":    timerWait,
+				"sum_rows_examined": "0",
+				"sum_errors":        "0",
+			}
+		}
+
+		It("returns an empty summary for the first sample", func() {
+			databaseClient.StatementDigestsReturns([]map[string]string{digestRow("a", "10", "1000")}, nil)
+
+			summary, err := gatherer.StatementDigests(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary.Digests).To(BeEmpty())
+		})
+
+		It("returns the digests executed since the previous sample", func() {
+			databaseClient.StatementDigestsReturnsOnCall(0, []map[string]string{digestRow("a", "10", "1000")}, nil)
+			databaseClient.StatementDigestsReturnsOnCall(1, []map[string]string{digestRow("a", "15", "4000")}, nil)
+
+			_, err := gatherer.StatementDigests(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			now = now.Add(30 * time.Second)
+			summary, err := gatherer.StatementDigests(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary).To(Equal(digest.Summary{
+				Elapsed: 30 * time.Second,
+				Digests: []digest.Delta{{Schema: "app", Digest: "a", DigestText: "SELECT ?", Calls: 5, TimerWait: 3000}},
+			}))
+		})
+
+		It("skips rows that cannot be parsed and reports them", func() {
+			databaseClient.StatementDigestsReturnsOnCall(0, []map[string]string{digestRow("a", "10", "1000")}, nil)
+			databaseClient.StatementDigestsReturnsOnCall(1, []map[string]string{digestRow("a", "15", "4000"), digestRow("b", "NULL", "1")}, nil)
+
+			_, _ = gatherer.StatementDigests(ctx)
+			summary, err := gatherer.StatementDigests(ctx)
+			Expect(err).To(MatchError(ContainSubstring(`digest b: invalid count_star "NULL"`)))
+			Expect(summary.Digests).To(HaveLen(1))
+		})
+
+		It("returns an error when the digests cannot be read", func() {
+			databaseClient.StatementDigestsReturns(nil, errors.New("performance_schema disabled"))
+
+			_, err := gatherer.StatementDigests(ctx)
+			Expect(err).To(MatchError("performance_schema disabled"))
+		})
+	})
+
+	Describe("TableSizes", func() {
+		BeforeEach(func() {
+			databaseClient.TableSizesReturns([]map[string]string{
+				{"table_schema": "app", "table_name": "users", "data_length": "16384", "index_length": "0", "data_free": "0", "table_rows": "12"},
+			}, nil)
+		})
+
+		It("returns the size of every table", func() {
+			tables, err := gatherer.TableSizes(ctx, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tables).To(Equal([]tablesize.Table{{Schema: "app", Name: "users", DataLength: 16384, Rows: 12}}))
+			Expect(databaseClient.InnoDBTablespacesCallCount()).To(Equal(0))
+		})
+
+		It("adds the file sizes of the InnoDB tablespaces", func() {
+			databaseClient.InnoDBTablespacesReturns([]map[string]string{{"name": "app/users", "file_size": "114688"}}, nil)
+
+			tables, err := gatherer.TableSizes(ctx, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tables).To(Equal([]tablesize.Table{{Schema: "app", Name: "users", DataLength: 16384, Rows: 12, FileSize: 114688}}))
+		})
+
+		It("still returns the tables when the tablespaces cannot be read", func() {
+			databaseClient.InnoDBTablespacesReturns(nil, errors.New("access denied"))
+
+			tables, err := gatherer.TableSizes(ctx, true)
+			Expect(err).To(MatchError("access denied"))
+			Expect(tables).To(HaveLen(1))
+		})
+
+		It("returns an error when the tables cannot be read", func() {
+			databaseClient.TableSizesReturns(nil, errors.New("db error"))
+
+			_, err := gatherer.TableSizes(ctx, false)
+			Expect(err).To(MatchError("db error"))
+		})
+	})
+
+	Describe("TransactionStats", func() {
+		BeforeEach(func() {
+			databaseClient.InnoDBTransactionsReturns([]map[string]string{
+				{"trx_id": "1", "age_seconds": "7200"},
+				{"trx_id": "2", "age_seconds": "120"},
+				{"trx_id": "3", "age_seconds": "5"},
+				{"trx_id": "4", "age_seconds": "1"},
+			}, nil)
+			databaseClient.DataLockWaitsReturns([]map[string]string{
+				{"requesting_engine_transaction_id": "2", "blocking_engine_transaction_id": "1"},
+				{"requesting_engine_transaction_id": "3", "blocking_engine_transaction_id": "2"},
+				{"requesting_engine_transaction_id": "4", "blocking_engine_transaction_id": "1"},
+			}, nil)
+			databaseClient.MetadataLockWaitersReturns(map[string]string{"metadata_lock_waiters": "3"}, nil)
+		})
+
+		It("returns transaction ages, lock wait chains and metadata lock waiters", func() {
+			stats, err := gatherer.TransactionStats(ctx, []int{60, 3600})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(Equal(map[string]string{
+				"transactions_open":             "4",
+				"oldest_transaction_age":        "7200",
+				"transactions_older_than_60s":   "2",
+				"transactions_older_than_3600s": "1",
+				"lock_waits":                    "3",
+				"lock_wait_chain_depth":         "2",
+				"metadata_lock_waiters":         "3",
+			}))
+		})
+
+		It("reports no age when there are no open transactions", func() {
+			databaseClient.InnoDBTransactionsReturns(nil, nil)
+			databaseClient.DataLockWaitsReturns(nil, nil)
+
+			stats, err := gatherer.TransactionStats(ctx, []int{60})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveKeyWithValue("transactions_open", "0"))
+			Expect(stats).To(HaveKeyWithValue("oldest_transaction_age", "0"))
+			Expect(stats).To(HaveKeyWithValue("transactions_older_than_60s", "0"))
+			Expect(stats).To(HaveKeyWithValue("lock_wait_chain_depth", "0"))
+		})
+
+		It("does not loop on a deadlock cycle", func() {
+			databaseClient.DataLockWaitsReturns([]map[string]string{
+				{"requesting_engine_transaction_id": "1", "blocking_engine_transaction_id": "2"},
+				{"requesting_engine_transaction_id": "2", "blocking_engine_transaction_id": "1"},
+			}, nil)
+
+			stats, err := gatherer.TransactionStats(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveKeyWithValue("lock_wait_chain_depth", "2"))
+		})
+
+		It("returns the stats that could be read when a source fails", func() {
+			databaseClient.DataLockWaitsReturns(nil, errors.New("performance_schema disabled"))
+
+			stats, err := gatherer.TransactionStats(ctx, []int{60})
+			Expect(err).To(MatchError("performance_schema disabled"))
+			Expect(stats).To(HaveKeyWithValue("oldest_transaction_age", "7200"))
+			Expect(stats).To(HaveKeyWithValue("metadata_lock_waiters", "3"))
+			Expect(stats).NotTo(HaveKey("lock_wait_chain_depth"))
+		})
+
+		It("reports transactions with an invalid age", func() {
+			databaseClient.InnoDBTransactionsReturns([]map[string]string{{"trx_id": "1", "age_seconds": "NULL"}}, nil)
+
+			_, err := gatherer.TransactionStats(ctx, nil)
+			Expect(err).To(MatchError(`transaction 1: invalid age "NULL"`))
+		})
+	})
+
+	Describe("InnoDBStatus", func() {
+		BeforeEach(func() {
+			status, err := os.ReadFile("../fixtures/innodb-status/mysql-8.0.txt")
+			Expect(err).NotTo(HaveOccurred())
+			databaseClient.ShowEngineInnoDBStatusReturns(string(status), nil)
+			databaseClient.ShowGlobalVariablesReturns(map[string]string{"innodb_redo_log_capacity": "100000"}, nil)
+		})
+
+		It("returns the parsed status with the checkpoint age as a percentage of the redo log", func() {
+			stats, err := gatherer.InnoDBStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveKeyWithValue("history_list_length", "42"))
+			Expect(stats).To(HaveKeyWithValue("checkpoint_age", "13746"))
+			Expect(stats).To(HaveKeyWithValue("redo_log_capacity", "100000"))
+			Expect(stats).To(HaveKeyWithValue("checkpoint_age_percent", "13.75"))
+		})
+
+		It("computes the redo log capacity from the log files before MySQL 8.0.30", func() {
+			databaseClient.ShowGlobalVariablesReturns(map[string]string{
+				"innodb_log_file_size":      "25000",
+				"innodb_log_files_in_group": "2",
+			}, nil)
+
+			stats, err := gatherer.InnoDBStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveKeyWithValue("redo_log_capacity", "50000"))
+			Expect(stats).To(HaveKeyWithValue("checkpoint_age_percent", "27.49"))
+		})
+
+		It("returns the parsed status when the global variables cannot be read", func() {
+			databaseClient.ShowGlobalVariablesReturns(nil, errors.New("access denied"))
+
+			stats, err := gatherer.InnoDBStatus(ctx)
+			Expect(err).To(MatchError("access denied"))
+			Expect(stats).To(HaveKeyWithValue("history_list_length", "42"))
+			Expect(stats).NotTo(HaveKey("checkpoint_age_percent"))
+		})
+
+		It("returns the delta and per second rate of its counters", func() {
+			status, err := os.ReadFile("../fixtures/innodb-status/mysql-8.0.txt")
+			Expect(err).NotTo(HaveOccurred())
+			databaseClient.ShowEngineInnoDBStatusReturnsOnCall(1, strings.Replace(string(status), "signal count 2489", "signal count 2789", 1), nil)
+
+			stats, err := gatherer.InnoDBStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).NotTo(HaveKey("semaphore_signal_count_delta"))
+
+			now = now.Add(30 * time.Second)
+
+			stats, err = gatherer.InnoDBStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(HaveKeyWithValue("semaphore_signal_count", "2789"))
+			Expect(stats).To(HaveKeyWithValue("semaphore_signal_count_delta", "300"))
+			Expect(stats).To(HaveKeyWithValue("semaphore_signal_count_rate", "10.00"))
+			Expect(stats).NotTo(HaveKey("history_list_length_delta"))
+		})
+
+		It("keeps the latest parsed status along with its deadlock", func() {
+			Expect(gatherer.LatestInnoDBStatus()).To(BeNil())
+
+			_, err := gatherer.InnoDBStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			status := gatherer.LatestInnoDBStatus()
+			Expect(status).NotTo(BeNil())
+			Expect(status.HistoryListLength).To(Equal(uint64(42)))
+			Expect(status.LatestDeadlock).NotTo(BeNil())
+			Expect(status.LatestDeadlock.Transactions).To(HaveLen(2))
+		})
+
+		It("returns an error when the status cannot be read", func() {
+			databaseClient.ShowEngineInnoDBStatusReturns("", errors.New("access denied"))
+
+			_, err := gatherer.InnoDBStatus(ctx)
+			Expect(err).To(MatchError("access denied"))
+			Expect(gatherer.LatestInnoDBStatus()).To(BeNil())
+		})
+	})
 })
@@ -4,35 +4,104 @@ package gatherfakes
 import (
 	"sync"
 
+	"github.com/cloudfoundry/mysql-metrics/cpu"
 	"github.com/cloudfoundry/mysql-metrics/gather"
 )
 
 type FakeCpuStater struct {
-	GetPercentageStub        func() (int, error)
-	getPercentageMutex       sync.RWMutex
-	getPercentageArgsForCall []struct {
+	LoadAverageStub        func() (cpu.LoadAverage, error)
+	loadAverageMutex       sync.RWMutex
+	loadAverageArgsForCall []struct {
 	}
-	getPercentageReturns struct {
-		result1 int
+	loadAverageReturns struct {
+		result1 cpu.LoadAverage
 		result2 error
 	}
-	getPercentageReturnsOnCall map[int]struct {
-		result1 int
+	loadAverageReturnsOnCall map[int]struct {
+		result1 cpu.LoadAverage
+		result2 error
+	}
+	SampleStub        func() (cpu.Sample, error)
+	sampleMutex       sync.RWMutex
+	sampleArgsForCall []struct {
+	}
+	sampleReturns struct {
+		result1 cpu.Sample
+		result2 error
+	}
+	sampleReturnsOnCall map[int]struct {
+		result1 cpu.Sample
 		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeCpuStater) GetPercentage() (int, error) {
-	fake.getPercentageMutex.Lock()
-	ret, specificReturn := fake.getPercentageReturnsOnCall[len(fake.getPercentageArgsForCall)]
-	fake.getPercentageArgsForCall = append(fake.getPercentageArgsForCall, struct {
+func (fake *FakeCpuStater) LoadAverage() (cpu.LoadAverage, error) {
+	fake.loadAverageMutex.Lock()
+	ret, specificReturn := fake.loadAverageReturnsOnCall[len(fake.loadAverageArgsForCall)]
+	fake.loadAverageArgsForCall = append(fake.loadAverageArgsForCall, struct {
+	}{})
+	stub := fake.LoadAverageStub
+	fakeReturns := fake.loadAverageReturns
+	fake.recordInvocation("LoadAverage", []interface{}{})
+	fake.loadAverageMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCpuStater) LoadAverageCallCount() int {
+	fake.loadAverageMutex.RLock()
+	defer fake.loadAverageMutex.RUnlock()
+	return len(fake.loadAverageArgsForCall)
+}
+
+func (fake *FakeCpuStater) LoadAverageCalls(stub func() (cpu.LoadAverage, error)) {
+	fake.loadAverageMutex.Lock()
+	defer fake.loadAverageMutex.Unlock()
+	fake.LoadAverageStub = stub
+}
+
+func (fake *FakeCpuStater) LoadAverageReturns(result1 cpu.LoadAverage, result2 error) {
+	fake.loadAverageMutex.Lock()
+	defer fake.loadAverageMutex.Unlock()
+	fake.LoadAverageStub = nil
+	fake.loadAverageReturns = struct {
+		result1 cpu.LoadAverage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCpuStater) LoadAverageReturnsOnCall(i int, result1 cpu.LoadAverage, result2 error) {
+	fake.loadAverageMutex.Lock()
+	defer fake.loadAverageMutex.Unlock()
+	fake.LoadAverageStub = nil
+	if fake.loadAverageReturnsOnCall == nil {
+		fake.loadAverageReturnsOnCall = make(map[int]struct {
+			result1 cpu.LoadAverage
+			result2 error
+		})
+	}
+	fake.loadAverageReturnsOnCall[i] = struct {
+		result1 cpu.LoadAverage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCpuStater) Sample() (cpu.Sample, error) {
+	fake.sampleMutex.Lock()
+	ret, specificReturn := fake.sampleReturnsOnCall[len(fake.sampleArgsForCall)]
+	fake.sampleArgsForCall = append(fake.sampleArgsForCall, struct {
 	}{})
-	stub := fake.GetPercentageStub
-	fakeReturns := fake.getPercentageReturns
-	fake.recordInvocation("GetPercentage", []interface{}{})
-	fake.getPercentageMutex.Unlock()
+	stub := fake.SampleStub
+	fakeReturns := fake.sampleReturns
+	fake.recordInvocation("Sample", []interface{}{})
+	fake.sampleMutex.Unlock()
 	if stub != nil {
 		return stub()
 	}
@@ -42,40 +111,40 @@ func (fake *FakeCpuStater) GetPercentage() (int, error) {
 	return fakeReturns.result1, fakeReturns.result2
 }
 
-func (fake *FakeCpuStater) GetPercentageCallCount() int {
-	fake.getPercentageMutex.RLock()
-	defer fake.getPercentageMutex.RUnlock()
-	return len(fake.getPercentageArgsForCall)
+func (fake *FakeCpuStater) SampleCallCount() int {
+	fake.sampleMutex.RLock()
+	defer fake.sampleMutex.RUnlock()
+	return len(fake.sampleArgsForCall)
 }
 
-func (fake *FakeCpuStater) GetPercentageCalls(stub func() (int, error)) {
-	fake.getPercentageMutex.Lock()
-	defer fake.getPercentageMutex.Unlock()
-	fake.GetPercentageStub = stub
+func (fake *FakeCpuStater) SampleCalls(stub func() (cpu.Sample, error)) {
+	fake.sampleMutex.Lock()
+	defer fake.sampleMutex.Unlock()
+	fake.SampleStub = stub
 }
 
-func (fake *FakeCpuStater) GetPercentageReturns(result1 int, result2 error) {
-	fake.getPercentageMutex.Lock()
-	defer fake.getPercentageMutex.Unlock()
-	fake.GetPercentageStub = nil
-	fake.getPercentageReturns = struct {
-		result1 int
+func (fake *FakeCpuStater) SampleReturns(result1 cpu.Sample, result2 error) {
+	fake.sampleMutex.Lock()
+	defer fake.sampleMutex.Unlock()
+	fake.SampleStub = nil
+	fake.sampleReturns = struct {
+		result1 cpu.Sample
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeCpuStater) GetPercentageReturnsOnCall(i int, result1 int, result2 error) {
-	fake.getPercentageMutex.Lock()
-	defer fake.getPercentageMutex.Unlock()
-	fake.GetPercentageStub = nil
-	if fake.getPercentageReturnsOnCall == nil {
-		fake.getPercentageReturnsOnCall = make(map[int]struct {
-			result1 int
+func (fake *FakeCpuStater) SampleReturnsOnCall(i int, result1 cpu.Sample, result2 error) {
+	fake.sampleMutex.Lock()
+	defer fake.sampleMutex.Unlock()
+	fake.SampleStub = nil
+	if fake.sampleReturnsOnCall == nil {
+		fake.sampleReturnsOnCall = make(map[int]struct {
+			result1 cpu.Sample
 			result2 error
 		})
 	}
-	fake.getPercentageReturnsOnCall[i] = struct {
-		result1 int
+	fake.sampleReturnsOnCall[i] = struct {
+		result1 cpu.Sample
 		result2 error
 	}{result1, result2}
 }
@@ -83,8 +152,10 @@ func (fake *FakeCpuStater) GetPercentageReturnsOnCall(i int, result1 int, result
 func (fake *FakeCpuStater) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.getPercentageMutex.RLock()
-	defer fake.getPercentageMutex.RUnlock()
+	fake.loadAverageMutex.RLock()
+	defer fake.loadAverageMutex.RUnlock()
+	fake.sampleMutex.RLock()
+	defer fake.sampleMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -2,6 +2,7 @@
 package gatherfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -9,9 +10,23 @@ import (
 )
 
 type FakeDatabaseClient struct {
-	FindLastBackupTimestampStub        func() (time.Time, error)
+	DataLockWaitsStub        func(context.Context) ([]map[string]string, error)
+	dataLockWaitsMutex       sync.RWMutex
+	dataLockWaitsArgsForCall []struct {
+		arg1 context.Context
+	}
+	dataLockWaitsReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	dataLockWaitsReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
+	FindLastBackupTimestampStub        func(context.Context) (time.Time, error)
 	findLastBackupTimestampMutex       sync.RWMutex
 	findLastBackupTimestampArgsForCall []struct {
+		arg1 context.Context
 	}
 	findLastBackupTimestampReturns struct {
 		result1 time.Time
@@ -21,9 +36,10 @@ type FakeDatabaseClient struct {
 		result1 time.Time
 		result2 error
 	}
-	HeartbeatStatusStub        func() (map[string]string, error)
+	HeartbeatStatusStub        func(context.Context) (map[string]string, error)
 	heartbeatStatusMutex       sync.RWMutex
 	heartbeatStatusArgsForCall []struct {
+		arg1 context.Context
 	}
 	heartbeatStatusReturns struct {
 		result1 map[string]string
@@ -33,9 +49,36 @@ type FakeDatabaseClient struct {
 		result1 map[string]string
 		result2 error
 	}
-	IsAvailableStub        func() bool
+	InnoDBTablespacesStub        func(context.Context) ([]map[string]string, error)
+	innoDBTablespacesMutex       sync.RWMutex
+	innoDBTablespacesArgsForCall []struct {
+		arg1 context.Context
+	}
+	innoDBTablespacesReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	innoDBTablespacesReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
+	InnoDBTransactionsStub        func(context.Context) ([]map[string]string, error)
+	innoDBTransactionsMutex       sync.RWMutex
+	innoDBTransactionsArgsForCall []struct {
+		arg1 context.Context
+	}
+	innoDBTransactionsReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	innoDBTransactionsReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
+	IsAvailableStub        func(context.Context) bool
 	isAvailableMutex       sync.RWMutex
 	isAvailableArgsForCall []struct {
+		arg1 context.Context
 	}
 	isAvailableReturns struct {
 		result1 bool
@@ -43,9 +86,10 @@ type FakeDatabaseClient struct {
 	isAvailableReturnsOnCall map[int]struct {
 		result1 bool
 	}
-	IsFollowerStub        func() (bool, error)
+	IsFollowerStub        func(context.Context) (bool, error)
 	isFollowerMutex       sync.RWMutex
 	isFollowerArgsForCall []struct {
+		arg1 context.Context
 	}
 	isFollowerReturns struct {
 		result1 bool
@@ -55,9 +99,38 @@ type FakeDatabaseClient struct {
 		result1 bool
 		result2 error
 	}
-	ServicePlansDiskAllocatedStub        func() (map[string]string, error)
+	MetadataLockWaitersStub        func(context.Context) (map[string]string, error)
+	metadataLockWaitersMutex       sync.RWMutex
+	metadataLockWaitersArgsForCall []struct {
+		arg1 context.Context
+	}
+	metadataLockWaitersReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	metadataLockWaitersReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
+	RunQueryStub        func(context.Context, string, time.Duration) ([]map[string]string, error)
+	runQueryMutex       sync.RWMutex
+	runQueryArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}
+	runQueryReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	runQueryReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
+	ServicePlansDiskAllocatedStub        func(context.Context) (map[string]string, error)
 	servicePlansDiskAllocatedMutex       sync.RWMutex
 	servicePlansDiskAllocatedArgsForCall []struct {
+		arg1 context.Context
 	}
 	servicePlansDiskAllocatedReturns struct {
 		result1 map[string]string
@@ -67,9 +140,23 @@ type FakeDatabaseClient struct {
 		result1 map[string]string
 		result2 error
 	}
-	ShowGlobalStatusStub        func() (map[string]string, error)
+	ShowEngineInnoDBStatusStub        func(context.Context) (string, error)
+	showEngineInnoDBStatusMutex       sync.RWMutex
+	showEngineInnoDBStatusArgsForCall []struct {
+		arg1 context.Context
+	}
+	showEngineInnoDBStatusReturns struct {
+		result1 string
+		result2 error
+	}
+	showEngineInnoDBStatusReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	ShowGlobalStatusStub        func(context.Context) (map[string]string, error)
 	showGlobalStatusMutex       sync.RWMutex
 	showGlobalStatusArgsForCall []struct {
+		arg1 context.Context
 	}
 	showGlobalStatusReturns struct {
 		result1 map[string]string
@@ -79,9 +166,10 @@ type FakeDatabaseClient struct {
 		result1 map[string]string
 		result2 error
 	}
-	ShowGlobalVariablesStub        func() (map[string]string, error)
+	ShowGlobalVariablesStub        func(context.Context) (map[string]string, error)
 	showGlobalVariablesMutex       sync.RWMutex
 	showGlobalVariablesArgsForCall []struct {
+		arg1 context.Context
 	}
 	showGlobalVariablesReturns struct {
 		result1 map[string]string
@@ -91,9 +179,10 @@ type FakeDatabaseClient struct {
 		result1 map[string]string
 		result2 error
 	}
-	ShowSlaveStatusStub        func() (map[string]string, error)
+	ShowSlaveStatusStub        func(context.Context) (map[string]string, error)
 	showSlaveStatusMutex       sync.RWMutex
 	showSlaveStatusArgsForCall []struct {
+		arg1 context.Context
 	}
 	showSlaveStatusReturns struct {
 		result1 map[string]string
@@ -103,21 +192,112 @@ type FakeDatabaseClient struct {
 		result1 map[string]string
 		result2 error
 	}
+	StatementDigestsStub        func(context.Context) ([]map[string]string, error)
+	statementDigestsMutex       sync.RWMutex
+	statementDigestsArgsForCall []struct {
+		arg1 context.Context
+	}
+	statementDigestsReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	statementDigestsReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
+	TableSizesStub        func(context.Context) ([]map[string]string, error)
+	tableSizesMutex       sync.RWMutex
+	tableSizesArgsForCall []struct {
+		arg1 context.Context
+	}
+	tableSizesReturns struct {
+		result1 []map[string]string
+		result2 error
+	}
+	tableSizesReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeDatabaseClient) FindLastBackupTimestamp() (time.Time, error) {
+func (fake *FakeDatabaseClient) DataLockWaits(arg1 context.Context) ([]map[string]string, error) {
+	fake.dataLockWaitsMutex.Lock()
+	ret, specificReturn := fake.dataLockWaitsReturnsOnCall[len(fake.dataLockWaitsArgsForCall)]
+	fake.dataLockWaitsArgsForCall = append(fake.dataLockWaitsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.DataLockWaitsStub
+	fakeReturns := fake.dataLockWaitsReturns
+	fake.recordInvocation("DataLockWaits", []interface{}{arg1})
+	fake.dataLockWaitsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) DataLockWaitsCallCount() int {
+	fake.dataLockWaitsMutex.RLock()
+	defer fake.dataLockWaitsMutex.RUnlock()
+	return len(fake.dataLockWaitsArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) DataLockWaitsCalls(stub func(context.Context) ([]map[string]string, error)) {
+	fake.dataLockWaitsMutex.Lock()
+	defer fake.dataLockWaitsMutex.Unlock()
+	fake.DataLockWaitsStub = stub
+}
+
+func (fake *FakeDatabaseClient) DataLockWaitsArgsForCall(i int) context.Context {
+	fake.dataLockWaitsMutex.RLock()
+	defer fake.dataLockWaitsMutex.RUnlock()
+	argsForCall := fake.dataLockWaitsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) DataLockWaitsReturns(result1 []map[string]string, result2 error) {
+	fake.dataLockWaitsMutex.Lock()
+	defer fake.dataLockWaitsMutex.Unlock()
+	fake.DataLockWaitsStub = nil
+	fake.dataLockWaitsReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) DataLockWaitsReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.dataLockWaitsMutex.Lock()
+	defer fake.dataLockWaitsMutex.Unlock()
+	fake.DataLockWaitsStub = nil
+	if fake.dataLockWaitsReturnsOnCall == nil {
+		fake.dataLockWaitsReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.dataLockWaitsReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) FindLastBackupTimestamp(arg1 context.Context) (time.Time, error) {
 	fake.findLastBackupTimestampMutex.Lock()
 	ret, specificReturn := fake.findLastBackupTimestampReturnsOnCall[len(fake.findLastBackupTimestampArgsForCall)]
 	fake.findLastBackupTimestampArgsForCall = append(fake.findLastBackupTimestampArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.FindLastBackupTimestampStub
 	fakeReturns := fake.findLastBackupTimestampReturns
-	fake.recordInvocation("FindLastBackupTimestamp", []interface{}{})
+	fake.recordInvocation("FindLastBackupTimestamp", []interface{}{arg1})
 	fake.findLastBackupTimestampMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -131,12 +311,19 @@ func (fake *FakeDatabaseClient) FindLastBackupTimestampCallCount() int {
 	return len(fake.findLastBackupTimestampArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) FindLastBackupTimestampCalls(stub func() (time.Time, error)) {
+func (fake *FakeDatabaseClient) FindLastBackupTimestampCalls(stub func(context.Context) (time.Time, error)) {
 	fake.findLastBackupTimestampMutex.Lock()
 	defer fake.findLastBackupTimestampMutex.Unlock()
 	fake.FindLastBackupTimestampStub = stub
 }
 
+func (fake *FakeDatabaseClient) FindLastBackupTimestampArgsForCall(i int) context.Context {
+	fake.findLastBackupTimestampMutex.RLock()
+	defer fake.findLastBackupTimestampMutex.RUnlock()
+	argsForCall := fake.findLastBackupTimestampArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) FindLastBackupTimestampReturns(result1 time.Time, result2 error) {
 	fake.findLastBackupTimestampMutex.Lock()
 	defer fake.findLastBackupTimestampMutex.Unlock()
@@ -163,17 +350,18 @@ func (fake *FakeDatabaseClient) FindLastBackupTimestampReturnsOnCall(i int, resu
 	}{result1, result2}
 }
 
-func (fake *FakeDatabaseClient) HeartbeatStatus() (map[string]string, error) {
+func (fake *FakeDatabaseClient) HeartbeatStatus(arg1 context.Context) (map[string]string, error) {
 	fake.heartbeatStatusMutex.Lock()
 	ret, specificReturn := fake.heartbeatStatusReturnsOnCall[len(fake.heartbeatStatusArgsForCall)]
 	fake.heartbeatStatusArgsForCall = append(fake.heartbeatStatusArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.HeartbeatStatusStub
 	fakeReturns := fake.heartbeatStatusReturns
-	fake.recordInvocation("HeartbeatStatus", []interface{}{})
+	fake.recordInvocation("HeartbeatStatus", []interface{}{arg1})
 	fake.heartbeatStatusMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -187,12 +375,19 @@ func (fake *FakeDatabaseClient) HeartbeatStatusCallCount() int {
 	return len(fake.heartbeatStatusArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) HeartbeatStatusCalls(stub func() (map[string]string, error)) {
+func (fake *FakeDatabaseClient) HeartbeatStatusCalls(stub func(context.Context) (map[string]string, error)) {
 	fake.heartbeatStatusMutex.Lock()
 	defer fake.heartbeatStatusMutex.Unlock()
 	fake.HeartbeatStatusStub = stub
 }
 
+func (fake *FakeDatabaseClient) HeartbeatStatusArgsForCall(i int) context.Context {
+	fake.heartbeatStatusMutex.RLock()
+	defer fake.heartbeatStatusMutex.RUnlock()
+	argsForCall := fake.heartbeatStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) HeartbeatStatusReturns(result1 map[string]string, result2 error) {
 	fake.heartbeatStatusMutex.Lock()
 	defer fake.heartbeatStatusMutex.Unlock()
@@ -219,17 +414,146 @@ func (fake *FakeDatabaseClient) HeartbeatStatusReturnsOnCall(i int, result1 map[
 	}{result1, result2}
 }
 
-func (fake *FakeDatabaseClient) IsAvailable() bool {
+func (fake *FakeDatabaseClient) InnoDBTablespaces(arg1 context.Context) ([]map[string]string, error) {
+	fake.innoDBTablespacesMutex.Lock()
+	ret, specificReturn := fake.innoDBTablespacesReturnsOnCall[len(fake.innoDBTablespacesArgsForCall)]
+	fake.innoDBTablespacesArgsForCall = append(fake.innoDBTablespacesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InnoDBTablespacesStub
+	fakeReturns := fake.innoDBTablespacesReturns
+	fake.recordInvocation("InnoDBTablespaces", []interface{}{arg1})
+	fake.innoDBTablespacesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) InnoDBTablespacesCallCount() int {
+	fake.innoDBTablespacesMutex.RLock()
+	defer fake.innoDBTablespacesMutex.RUnlock()
+	return len(fake.innoDBTablespacesArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) InnoDBTablespacesCalls(stub func(context.Context) ([]map[string]string, error)) {
+	fake.innoDBTablespacesMutex.Lock()
+	defer fake.innoDBTablespacesMutex.Unlock()
+	fake.InnoDBTablespacesStub = stub
+}
+
+func (fake *FakeDatabaseClient) InnoDBTablespacesArgsForCall(i int) context.Context {
+	fake.innoDBTablespacesMutex.RLock()
+	defer fake.innoDBTablespacesMutex.RUnlock()
+	argsForCall := fake.innoDBTablespacesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) InnoDBTablespacesReturns(result1 []map[string]string, result2 error) {
+	fake.innoDBTablespacesMutex.Lock()
+	defer fake.innoDBTablespacesMutex.Unlock()
+	fake.InnoDBTablespacesStub = nil
+	fake.innoDBTablespacesReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) InnoDBTablespacesReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.innoDBTablespacesMutex.Lock()
+	defer fake.innoDBTablespacesMutex.Unlock()
+	fake.InnoDBTablespacesStub = nil
+	if fake.innoDBTablespacesReturnsOnCall == nil {
+		fake.innoDBTablespacesReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.innoDBTablespacesReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) InnoDBTransactions(arg1 context.Context) ([]map[string]string, error) {
+	fake.innoDBTransactionsMutex.Lock()
+	ret, specificReturn := fake.innoDBTransactionsReturnsOnCall[len(fake.innoDBTransactionsArgsForCall)]
+	fake.innoDBTransactionsArgsForCall = append(fake.innoDBTransactionsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InnoDBTransactionsStub
+	fakeReturns := fake.innoDBTransactionsReturns
+	fake.recordInvocation("InnoDBTransactions", []interface{}{arg1})
+	fake.innoDBTransactionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) InnoDBTransactionsCallCount() int {
+	fake.innoDBTransactionsMutex.RLock()
+	defer fake.innoDBTransactionsMutex.RUnlock()
+	return len(fake.innoDBTransactionsArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) InnoDBTransactionsCalls(stub func(context.Context) ([]map[string]string, error)) {
+	fake.innoDBTransactionsMutex.Lock()
+	defer fake.innoDBTransactionsMutex.Unlock()
+	fake.InnoDBTransactionsStub = stub
+}
+
+func (fake *FakeDatabaseClient) InnoDBTransactionsArgsForCall(i int) context.Context {
+	fake.innoDBTransactionsMutex.RLock()
+	defer fake.innoDBTransactionsMutex.RUnlock()
+	argsForCall := fake.innoDBTransactionsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) InnoDBTransactionsReturns(result1 []map[string]string, result2 error) {
+	fake.innoDBTransactionsMutex.Lock()
+	defer fake.innoDBTransactionsMutex.Unlock()
+	fake.InnoDBTransactionsStub = nil
+	fake.innoDBTransactionsReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) InnoDBTransactionsReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.innoDBTransactionsMutex.Lock()
+	defer fake.innoDBTransactionsMutex.Unlock()
+	fake.InnoDBTransactionsStub = nil
+	if fake.innoDBTransactionsReturnsOnCall == nil {
+		fake.innoDBTransactionsReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.innoDBTransactionsReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) IsAvailable(arg1 context.Context) bool {
 	fake.isAvailableMutex.Lock()
 	ret, specificReturn := fake.isAvailableReturnsOnCall[len(fake.isAvailableArgsForCall)]
 	fake.isAvailableArgsForCall = append(fake.isAvailableArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.IsAvailableStub
 	fakeReturns := fake.isAvailableReturns
-	fake.recordInvocation("IsAvailable", []interface{}{})
+	fake.recordInvocation("IsAvailable", []interface{}{arg1})
 	fake.isAvailableMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
@@ -243,12 +567,19 @@ func (fake *FakeDatabaseClient) IsAvailableCallCount() int {
 	return len(fake.isAvailableArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) IsAvailableCalls(stub func() bool) {
+func (fake *FakeDatabaseClient) IsAvailableCalls(stub func(context.Context) bool) {
 	fake.isAvailableMutex.Lock()
 	defer fake.isAvailableMutex.Unlock()
 	fake.IsAvailableStub = stub
 }
 
+func (fake *FakeDatabaseClient) IsAvailableArgsForCall(i int) context.Context {
+	fake.isAvailableMutex.RLock()
+	defer fake.isAvailableMutex.RUnlock()
+	argsForCall := fake.isAvailableArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) IsAvailableReturns(result1 bool) {
 	fake.isAvailableMutex.Lock()
 	defer fake.isAvailableMutex.Unlock()
@@ -272,17 +603,18 @@ func (fake *FakeDatabaseClient) IsAvailableReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
-func (fake *FakeDatabaseClient) IsFollower() (bool, error) {
+func (fake *FakeDatabaseClient) IsFollower(arg1 context.Context) (bool, error) {
 	fake.isFollowerMutex.Lock()
 	ret, specificReturn := fake.isFollowerReturnsOnCall[len(fake.isFollowerArgsForCall)]
 	fake.isFollowerArgsForCall = append(fake.isFollowerArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.IsFollowerStub
 	fakeReturns := fake.isFollowerReturns
-	fake.recordInvocation("IsFollower", []interface{}{})
+	fake.recordInvocation("IsFollower", []interface{}{arg1})
 	fake.isFollowerMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -296,12 +628,19 @@ func (fake *FakeDatabaseClient) IsFollowerCallCount() int {
 	return len(fake.isFollowerArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) IsFollowerCalls(stub func() (bool, error)) {
+func (fake *FakeDatabaseClient) IsFollowerCalls(stub func(context.Context) (bool, error)) {
 	fake.isFollowerMutex.Lock()
 	defer fake.isFollowerMutex.Unlock()
 	fake.IsFollowerStub = stub
 }
 
+func (fake *FakeDatabaseClient) IsFollowerArgsForCall(i int) context.Context {
+	fake.isFollowerMutex.RLock()
+	defer fake.isFollowerMutex.RUnlock()
+	argsForCall := fake.isFollowerArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) IsFollowerReturns(result1 bool, result2 error) {
 	fake.isFollowerMutex.Lock()
 	defer fake.isFollowerMutex.Unlock()
@@ -328,17 +667,148 @@ func (fake *FakeDatabaseClient) IsFollowerReturnsOnCall(i int, result1 bool, res
 	}{result1, result2}
 }
 
-func (fake *FakeDatabaseClient) ServicePlansDiskAllocated() (map[string]string, error) {
+func (fake *FakeDatabaseClient) MetadataLockWaiters(arg1 context.Context) (map[string]string, error) {
+	fake.metadataLockWaitersMutex.Lock()
+	ret, specificReturn := fake.metadataLockWaitersReturnsOnCall[len(fake.metadataLockWaitersArgsForCall)]
+	fake.metadataLockWaitersArgsForCall = append(fake.metadataLockWaitersArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.MetadataLockWaitersStub
+	fakeReturns := fake.metadataLockWaitersReturns
+	fake.recordInvocation("MetadataLockWaiters", []interface{}{arg1})
+	fake.metadataLockWaitersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) MetadataLockWaitersCallCount() int {
+	fake.metadataLockWaitersMutex.RLock()
+	defer fake.metadataLockWaitersMutex.RUnlock()
+	return len(fake.metadataLockWaitersArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) MetadataLockWaitersCalls(stub func(context.Context) (map[string]string, error)) {
+	fake.metadataLockWaitersMutex.Lock()
+	defer fake.metadataLockWaitersMutex.Unlock()
+	fake.MetadataLockWaitersStub = stub
+}
+
+func (fake *FakeDatabaseClient) MetadataLockWaitersArgsForCall(i int) context.Context {
+	fake.metadataLockWaitersMutex.RLock()
+	defer fake.metadataLockWaitersMutex.RUnlock()
+	argsForCall := fake.metadataLockWaitersArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) MetadataLockWaitersReturns(result1 map[string]string, result2 error) {
+	fake.metadataLockWaitersMutex.Lock()
+	defer fake.metadataLockWaitersMutex.Unlock()
+	fake.MetadataLockWaitersStub = nil
+	fake.metadataLockWaitersReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) MetadataLockWaitersReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.metadataLockWaitersMutex.Lock()
+	defer fake.metadataLockWaitersMutex.Unlock()
+	fake.MetadataLockWaitersStub = nil
+	if fake.metadataLockWaitersReturnsOnCall == nil {
+		fake.metadataLockWaitersReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.metadataLockWaitersReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) RunQuery(arg1 context.Context, arg2 string, arg3 time.Duration) ([]map[string]string, error) {
+	fake.runQueryMutex.Lock()
+	ret, specificReturn := fake.runQueryReturnsOnCall[len(fake.runQueryArgsForCall)]
+	fake.runQueryArgsForCall = append(fake.runQueryArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.RunQueryStub
+	fakeReturns := fake.runQueryReturns
+	fake.recordInvocation("RunQuery", []interface{}{arg1, arg2, arg3})
+	fake.runQueryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) RunQueryCallCount() int {
+	fake.runQueryMutex.RLock()
+	defer fake.runQueryMutex.RUnlock()
+	return len(fake.runQueryArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) RunQueryCalls(stub func(context.Context, string, time.Duration) ([]map[string]string, error)) {
+	fake.runQueryMutex.Lock()
+	defer fake.runQueryMutex.Unlock()
+	fake.RunQueryStub = stub
+}
+
+func (fake *FakeDatabaseClient) RunQueryArgsForCall(i int) (context.Context, string, time.Duration) {
+	fake.runQueryMutex.RLock()
+	defer fake.runQueryMutex.RUnlock()
+	argsForCall := fake.runQueryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeDatabaseClient) RunQueryReturns(result1 []map[string]string, result2 error) {
+	fake.runQueryMutex.Lock()
+	defer fake.runQueryMutex.Unlock()
+	fake.RunQueryStub = nil
+	fake.runQueryReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) RunQueryReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.runQueryMutex.Lock()
+	defer fake.runQueryMutex.Unlock()
+	fake.RunQueryStub = nil
+	if fake.runQueryReturnsOnCall == nil {
+		fake.runQueryReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.runQueryReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) ServicePlansDiskAllocated(arg1 context.Context) (map[string]string, error) {
 	fake.servicePlansDiskAllocatedMutex.Lock()
 	ret, specificReturn := fake.servicePlansDiskAllocatedReturnsOnCall[len(fake.servicePlansDiskAllocatedArgsForCall)]
 	fake.servicePlansDiskAllocatedArgsForCall = append(fake.servicePlansDiskAllocatedArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ServicePlansDiskAllocatedStub
 	fakeReturns := fake.servicePlansDiskAllocatedReturns
-	fake.recordInvocation("ServicePlansDiskAllocated", []interface{}{})
+	fake.recordInvocation("ServicePlansDiskAllocated", []interface{}{arg1})
 	fake.servicePlansDiskAllocatedMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -352,12 +822,19 @@ func (fake *FakeDatabaseClient) ServicePlansDiskAllocatedCallCount() int {
 	return len(fake.servicePlansDiskAllocatedArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) ServicePlansDiskAllocatedCalls(stub func() (map[string]string, error)) {
+func (fake *FakeDatabaseClient) ServicePlansDiskAllocatedCalls(stub func(context.Context) (map[string]string, error)) {
 	fake.servicePlansDiskAllocatedMutex.Lock()
 	defer fake.servicePlansDiskAllocatedMutex.Unlock()
 	fake.ServicePlansDiskAllocatedStub = stub
 }
 
+func (fake *FakeDatabaseClient) ServicePlansDiskAllocatedArgsForCall(i int) context.Context {
+	fake.servicePlansDiskAllocatedMutex.RLock()
+	defer fake.servicePlansDiskAllocatedMutex.RUnlock()
+	argsForCall := fake.servicePlansDiskAllocatedArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) ServicePlansDiskAllocatedReturns(result1 map[string]string, result2 error) {
 	fake.servicePlansDiskAllocatedMutex.Lock()
 	defer fake.servicePlansDiskAllocatedMutex.Unlock()
@@ -384,17 +861,82 @@ func (fake *FakeDatabaseClient) ServicePlansDiskAllocatedReturnsOnCall(i int, re
 	}{result1, result2}
 }
 
-func (fake *FakeDatabaseClient) ShowGlobalStatus() (map[string]string, error) {
+func (fake *FakeDatabaseClient) ShowEngineInnoDBStatus(arg1 context.Context) (string, error) {
+	fake.showEngineInnoDBStatusMutex.Lock()
+	ret, specificReturn := fake.showEngineInnoDBStatusReturnsOnCall[len(fake.showEngineInnoDBStatusArgsForCall)]
+	fake.showEngineInnoDBStatusArgsForCall = append(fake.showEngineInnoDBStatusArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ShowEngineInnoDBStatusStub
+	fakeReturns := fake.showEngineInnoDBStatusReturns
+	fake.recordInvocation("ShowEngineInnoDBStatus", []interface{}{arg1})
+	fake.showEngineInnoDBStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) ShowEngineInnoDBStatusCallCount() int {
+	fake.showEngineInnoDBStatusMutex.RLock()
+	defer fake.showEngineInnoDBStatusMutex.RUnlock()
+	return len(fake.showEngineInnoDBStatusArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) ShowEngineInnoDBStatusCalls(stub func(context.Context) (string, error)) {
+	fake.showEngineInnoDBStatusMutex.Lock()
+	defer fake.showEngineInnoDBStatusMutex.Unlock()
+	fake.ShowEngineInnoDBStatusStub = stub
+}
+
+func (fake *FakeDatabaseClient) ShowEngineInnoDBStatusArgsForCall(i int) context.Context {
+	fake.showEngineInnoDBStatusMutex.RLock()
+	defer fake.showEngineInnoDBStatusMutex.RUnlock()
+	argsForCall := fake.showEngineInnoDBStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) ShowEngineInnoDBStatusReturns(result1 string, result2 error) {
+	fake.showEngineInnoDBStatusMutex.Lock()
+	defer fake.showEngineInnoDBStatusMutex.Unlock()
+	fake.ShowEngineInnoDBStatusStub = nil
+	fake.showEngineInnoDBStatusReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) ShowEngineInnoDBStatusReturnsOnCall(i int, result1 string, result2 error) {
+	fake.showEngineInnoDBStatusMutex.Lock()
+	defer fake.showEngineInnoDBStatusMutex.Unlock()
+	fake.ShowEngineInnoDBStatusStub = nil
+	if fake.showEngineInnoDBStatusReturnsOnCall == nil {
+		fake.showEngineInnoDBStatusReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.showEngineInnoDBStatusReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) ShowGlobalStatus(arg1 context.Context) (map[string]string, error) {
 	fake.showGlobalStatusMutex.Lock()
 	ret, specificReturn := fake.showGlobalStatusReturnsOnCall[len(fake.showGlobalStatusArgsForCall)]
 	fake.showGlobalStatusArgsForCall = append(fake.showGlobalStatusArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ShowGlobalStatusStub
 	fakeReturns := fake.showGlobalStatusReturns
-	fake.recordInvocation("ShowGlobalStatus", []interface{}{})
+	fake.recordInvocation("ShowGlobalStatus", []interface{}{arg1})
 	fake.showGlobalStatusMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -408,12 +950,19 @@ func (fake *FakeDatabaseClient) ShowGlobalStatusCallCount() int {
 	return len(fake.showGlobalStatusArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) ShowGlobalStatusCalls(stub func() (map[string]string, error)) {
+func (fake *FakeDatabaseClient) ShowGlobalStatusCalls(stub func(context.Context) (map[string]string, error)) {
 	fake.showGlobalStatusMutex.Lock()
 	defer fake.showGlobalStatusMutex.Unlock()
 	fake.ShowGlobalStatusStub = stub
 }
 
+func (fake *FakeDatabaseClient) ShowGlobalStatusArgsForCall(i int) context.Context {
+	fake.showGlobalStatusMutex.RLock()
+	defer fake.showGlobalStatusMutex.RUnlock()
+	argsForCall := fake.showGlobalStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) ShowGlobalStatusReturns(result1 map[string]string, result2 error) {
 	fake.showGlobalStatusMutex.Lock()
 	defer fake.showGlobalStatusMutex.Unlock()
@@ -440,17 +989,18 @@ func (fake *FakeDatabaseClient) ShowGlobalStatusReturnsOnCall(i int, result1 map
 	}{result1, result2}
 }
 
-func (fake *FakeDatabaseClient) ShowGlobalVariables() (map[string]string, error) {
+func (fake *FakeDatabaseClient) ShowGlobalVariables(arg1 context.Context) (map[string]string, error) {
 	fake.showGlobalVariablesMutex.Lock()
 	ret, specificReturn := fake.showGlobalVariablesReturnsOnCall[len(fake.showGlobalVariablesArgsForCall)]
 	fake.showGlobalVariablesArgsForCall = append(fake.showGlobalVariablesArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ShowGlobalVariablesStub
 	fakeReturns := fake.showGlobalVariablesReturns
-	fake.recordInvocation("ShowGlobalVariables", []interface{}{})
+	fake.recordInvocation("ShowGlobalVariables", []interface{}{arg1})
 	fake.showGlobalVariablesMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -464,12 +1014,19 @@ func (fake *FakeDatabaseClient) ShowGlobalVariablesCallCount() int {
 	return len(fake.showGlobalVariablesArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) ShowGlobalVariablesCalls(stub func() (map[string]string, error)) {
+func (fake *FakeDatabaseClient) ShowGlobalVariablesCalls(stub func(context.Context) (map[string]string, error)) {
 	fake.showGlobalVariablesMutex.Lock()
 	defer fake.showGlobalVariablesMutex.Unlock()
 	fake.ShowGlobalVariablesStub = stub
 }
 
+func (fake *FakeDatabaseClient) ShowGlobalVariablesArgsForCall(i int) context.Context {
+	fake.showGlobalVariablesMutex.RLock()
+	defer fake.showGlobalVariablesMutex.RUnlock()
+	argsForCall := fake.showGlobalVariablesArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) ShowGlobalVariablesReturns(result1 map[string]string, result2 error) {
 	fake.showGlobalVariablesMutex.Lock()
 	defer fake.showGlobalVariablesMutex.Unlock()
@@ -496,17 +1053,18 @@ func (fake *FakeDatabaseClient) ShowGlobalVariablesReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
-func (fake *FakeDatabaseClient) ShowSlaveStatus() (map[string]string, error) {
+func (fake *FakeDatabaseClient) ShowSlaveStatus(arg1 context.Context) (map[string]string, error) {
 	fake.showSlaveStatusMutex.Lock()
 	ret, specificReturn := fake.showSlaveStatusReturnsOnCall[len(fake.showSlaveStatusArgsForCall)]
 	fake.showSlaveStatusArgsForCall = append(fake.showSlaveStatusArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.ShowSlaveStatusStub
 	fakeReturns := fake.showSlaveStatusReturns
-	fake.recordInvocation("ShowSlaveStatus", []interface{}{})
+	fake.recordInvocation("ShowSlaveStatus", []interface{}{arg1})
 	fake.showSlaveStatusMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -520,12 +1078,19 @@ func (fake *FakeDatabaseClient) ShowSlaveStatusCallCount() int {
 	return len(fake.showSlaveStatusArgsForCall)
 }
 
-func (fake *FakeDatabaseClient) ShowSlaveStatusCalls(stub func() (map[string]string, error)) {
+func (fake *FakeDatabaseClient) ShowSlaveStatusCalls(stub func(context.Context) (map[string]string, error)) {
 	fake.showSlaveStatusMutex.Lock()
 	defer fake.showSlaveStatusMutex.Unlock()
 	fake.ShowSlaveStatusStub = stub
 }
 
+func (fake *FakeDatabaseClient) ShowSlaveStatusArgsForCall(i int) context.Context {
+	fake.showSlaveStatusMutex.RLock()
+	defer fake.showSlaveStatusMutex.RUnlock()
+	argsForCall := fake.showSlaveStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeDatabaseClient) ShowSlaveStatusReturns(result1 map[string]string, result2 error) {
 	fake.showSlaveStatusMutex.Lock()
 	defer fake.showSlaveStatusMutex.Unlock()
@@ -552,25 +1117,169 @@ func (fake *FakeDatabaseClient) ShowSlaveStatusReturnsOnCall(i int, result1 map[
 	}{result1, result2}
 }
 
+func (fake *FakeDatabaseClient) StatementDigests(arg1 context.Context) ([]map[string]string, error) {
+	fake.statementDigestsMutex.Lock()
+	ret, specificReturn := fake.statementDigestsReturnsOnCall[len(fake.statementDigestsArgsForCall)]
+	fake.statementDigestsArgsForCall = append(fake.statementDigestsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.StatementDigestsStub
+	fakeReturns := fake.statementDigestsReturns
+	fake.recordInvocation("StatementDigests", []interface{}{arg1})
+	fake.statementDigestsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) StatementDigestsCallCount() int {
+	fake.statementDigestsMutex.RLock()
+	defer fake.statementDigestsMutex.RUnlock()
+	return len(fake.statementDigestsArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) StatementDigestsCalls(stub func(context.Context) ([]map[string]string, error)) {
+	fake.statementDigestsMutex.Lock()
+	defer fake.statementDigestsMutex.Unlock()
+	fake.StatementDigestsStub = stub
+}
+
+func (fake *FakeDatabaseClient) StatementDigestsArgsForCall(i int) context.Context {
+	fake.statementDigestsMutex.RLock()
+	defer fake.statementDigestsMutex.RUnlock()
+	argsForCall := fake.statementDigestsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) StatementDigestsReturns(result1 []map[string]string, result2 error) {
+	fake.statementDigestsMutex.Lock()
+	defer fake.statementDigestsMutex.Unlock()
+	fake.StatementDigestsStub = nil
+	fake.statementDigestsReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) StatementDigestsReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.statementDigestsMutex.Lock()
+	defer fake.statementDigestsMutex.Unlock()
+	fake.StatementDigestsStub = nil
+	if fake.statementDigestsReturnsOnCall == nil {
+		fake.statementDigestsReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.statementDigestsReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) TableSizes(arg1 context.Context) ([]map[string]string, error) {
+	fake.tableSizesMutex.Lock()
+	ret, specificReturn := fake.tableSizesReturnsOnCall[len(fake.tableSizesArgsForCall)]
+	fake.tableSizesArgsForCall = append(fake.tableSizesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.TableSizesStub
+	fakeReturns := fake.tableSizesReturns
+	fake.recordInvocation("TableSizes", []interface{}{arg1})
+	fake.tableSizesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeDatabaseClient) TableSizesCallCount() int {
+	fake.tableSizesMutex.RLock()
+	defer fake.tableSizesMutex.RUnlock()
+	return len(fake.tableSizesArgsForCall)
+}
+
+func (fake *FakeDatabaseClient) TableSizesCalls(stub func(context.Context) ([]map[string]string, error)) {
+	fake.tableSizesMutex.Lock()
+	defer fake.tableSizesMutex.Unlock()
+	fake.TableSizesStub = stub
+}
+
+func (fake *FakeDatabaseClient) TableSizesArgsForCall(i int) context.Context {
+	fake.tableSizesMutex.RLock()
+	defer fake.tableSizesMutex.RUnlock()
+	argsForCall := fake.tableSizesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeDatabaseClient) TableSizesReturns(result1 []map[string]string, result2 error) {
+	fake.tableSizesMutex.Lock()
+	defer fake.tableSizesMutex.Unlock()
+	fake.TableSizesStub = nil
+	fake.tableSizesReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeDatabaseClient) TableSizesReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.tableSizesMutex.Lock()
+	defer fake.tableSizesMutex.Unlock()
+	fake.TableSizesStub = nil
+	if fake.tableSizesReturnsOnCall == nil {
+		fake.tableSizesReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.tableSizesReturnsOnCall[i] = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeDatabaseClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.dataLockWaitsMutex.RLock()
+	defer fake.dataLockWaitsMutex.RUnlock()
 	fake.findLastBackupTimestampMutex.RLock()
 	defer fake.findLastBackupTimestampMutex.RUnlock()
 	fake.heartbeatStatusMutex.RLock()
 	defer fake.heartbeatStatusMutex.RUnlock()
+	fake.innoDBTablespacesMutex.RLock()
+	defer fake.innoDBTablespacesMutex.RUnlock()
+	fake.innoDBTransactionsMutex.RLock()
+	defer fake.innoDBTransactionsMutex.RUnlock()
 	fake.isAvailableMutex.RLock()
 	defer fake.isAvailableMutex.RUnlock()
 	fake.isFollowerMutex.RLock()
 	defer fake.isFollowerMutex.RUnlock()
+	fake.metadataLockWaitersMutex.RLock()
+	defer fake.metadataLockWaitersMutex.RUnlock()
+	fake.runQueryMutex.RLock()
+	defer fake.runQueryMutex.RUnlock()
 	fake.servicePlansDiskAllocatedMutex.RLock()
 	defer fake.servicePlansDiskAllocatedMutex.RUnlock()
+	fake.showEngineInnoDBStatusMutex.RLock()
+	defer fake.showEngineInnoDBStatusMutex.RUnlock()
 	fake.showGlobalStatusMutex.RLock()
 	defer fake.showGlobalStatusMutex.RUnlock()
 	fake.showGlobalVariablesMutex.RLock()
 	defer fake.showGlobalVariablesMutex.RUnlock()
 	fake.showSlaveStatusMutex.RLock()
 	defer fake.showSlaveStatusMutex.RUnlock()
+	fake.statementDigestsMutex.RLock()
+	defer fake.statementDigestsMutex.RUnlock()
+	fake.tableSizesMutex.RLock()
+	defer fake.tableSizesMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,13 @@
+package innodbstatus_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestInnoDBStatus(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "InnoDB Status Suite")
+}
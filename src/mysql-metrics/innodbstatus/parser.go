@@ -0,0 +1,332 @@
+// Package innodbstatus parses the text output of SHOW ENGINE INNODB STATUS.
+// Only the sections that carry health signals not available from SHOW GLOBAL
+// STATUS are parsed: semaphores, the latest detected deadlock, the history
+// list length, the redo log and pending I/O. The format of MySQL 5.7, 8.0 and
+// Percona XtraDB is supported.
+package innodbstatus
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotInnoDBStatus is returned when the text does not look like the output of
+// SHOW ENGINE INNODB STATUS.
+var ErrNotInnoDBStatus = errors.New("not the output of SHOW ENGINE INNODB STATUS")
+
+type Status struct {
+	Semaphores        Semaphores `json:"semaphores"`
+	LatestDeadlock    *Deadlock  `json:"latest_deadlock,omitempty"`
+	HistoryListLength uint64     `json:"history_list_length"`
+	Log               Log        `json:"log"`
+	PendingIO         PendingIO  `json:"pending_io"`
+}
+
+type Semaphores struct {
+	ReservationCount uint64 `json:"reservation_count"`
+	SignalCount      uint64 `json:"signal_count"`
+	// LongWaits is the number of threads reported as waiting for a semaphore,
+	// which InnoDB only does once a thread has waited for over a second.
+	LongWaits          int     `json:"long_waits"`
+	LongestWaitSeconds float64 `json:"longest_wait_seconds"`
+	RWSharedOSWaits    uint64  `json:"rw_shared_os_waits"`
+	RWExclOSWaits      uint64  `json:"rw_excl_os_waits"`
+	RWSXOSWaits        uint64  `json:"rw_sx_os_waits"`
+}
+
+type Deadlock struct {
+	// Time is when the deadlock was detected, in the server's time zone
+	// interpreted as the local time zone.
+	Time         time.Time             `json:"time"`
+	Transactions []DeadlockTransaction `json:"transactions"`
+	// RolledBack is the ID of the transaction InnoDB chose as the victim.
+	RolledBack string `json:"rolled_back"`
+}
+
+type DeadlockTransaction struct {
+	ID    string `json:"id"`
+	Query string `json:"query"`
+}
+
+type Log struct {
+	SequenceNumber   uint64 `json:"sequence_number"`
+	FlushedUpTo      uint64 `json:"flushed_up_to"`
+	PagesFlushedUpTo uint64 `json:"pages_flushed_up_to"`
+	LastCheckpointAt uint64 `json:"last_checkpoint_at"`
+	// MaxCheckpointAge is only reported by Percona XtraDB.
+	MaxCheckpointAge uint64 `json:"max_checkpoint_age,omitempty"`
+}
+
+// CheckpointAge is the amount of redo log written since the last checkpoint.
+// When it reaches the redo log capacity InnoDB stalls writes until pages are
+// flushed.
+func (l Log) CheckpointAge() uint64 {
+	if l.SequenceNumber < l.LastCheckpointAt {
+		return 0
+	}
+	return l.SequenceNumber - l.LastCheckpointAt
+}
+
+type PendingIO struct {
+	NormalAIOReads            uint64 `json:"normal_aio_reads"`
+	NormalAIOWrites           uint64 `json:"normal_aio_writes"`
+	InsertBufferAIOReads      uint64 `json:"insert_buffer_aio_reads"`
+	LogIOs                    uint64 `json:"log_ios"`
+	SyncIOs                   uint64 `json:"sync_ios"`
+	LogFlushes                uint64 `json:"log_flushes"`
+	BufferPoolFlushes         uint64 `json:"buffer_pool_flushes"`
+	BufferPoolReads           uint64 `json:"buffer_pool_reads"`
+	BufferPoolLRUWrites       uint64 `json:"buffer_pool_lru_writes"`
+	BufferPoolFlushListWrites uint64 `json:"buffer_pool_flush_list_writes"`
+}
+
+var (
+	sectionTitle        = regexp.MustCompile(`^[A-Z][A-Z /]+$`)
+	longSemaphoreWait   = regexp.MustCompile(`^--Thread \d+ has waited at .* for ([\d.]+) seconds`)
+	rwOSWaits           = regexp.MustCompile(`^RW-(shared|excl|sx) spins \d+, rounds \d+, OS waits (\d+)`)
+	deadlockTransaction = regexp.MustCompile(`^\*\*\* \((\d+)\) TRANSACTION:`)
+	transactionID       = regexp.MustCompile(`^TRANSACTION (\d+),`)
+	rollBack            = regexp.MustCompile(`^\*\*\* WE ROLL BACK TRANSACTION \((\d+)\)`)
+	pendingAIO          = regexp.MustCompile(`^Pending normal aio reads:\s*(\d+)?\s*(?:\[([\d, ]*)\])?\s*, aio writes:\s*(\d+)?\s*(?:\[([\d, ]*)\])?`)
+	pendingIbuf         = regexp.MustCompile(`ibuf aio reads:\s*(\d+), log i/o's:\s*(\d+), sync i/o's:\s*(\d+)`)
+	pendingFlushes      = regexp.MustCompile(`^Pending flushes \(fsync\) log: (\d+); buffer pool: (\d+)`)
+	pendingWrites       = regexp.MustCompile(`^Pending writes: LRU (\d+), flush list (\d+)`)
+)
+
+// Parse parses the Status column of SHOW ENGINE INNODB STATUS.
+func Parse(text string) (*Status, error) {
+	if !strings.Contains(text, "INNODB MONITOR OUTPUT") {
+		return nil, ErrNotInnoDBStatus
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), " \t\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	p := parser{status: &Status{}}
+	section := ""
+	for i := 0; i < len(lines); i++ {
+		if isRule(lines[i]) && i+2 < len(lines) && sectionTitle.MatchString(lines[i+1]) && isRule(lines[i+2]) {
+			section = lines[i+1]
+			i += 2
+			continue
+		}
+
+		switch section {
+		case "SEMAPHORES":
+			p.semaphores(lines[i])
+		case "LATEST DETECTED DEADLOCK":
+			p.deadlock(lines[i])
+		case "TRANSACTIONS":
+			p.transactions(lines[i])
+		case "FILE I/O":
+			p.fileIO(lines[i])
+		case "LOG":
+			p.log(lines[i])
+		case "BUFFER POOL AND MEMORY":
+			p.bufferPool(lines[i])
+		}
+	}
+
+	return p.status, errors.Join(p.errs...)
+}
+
+func isRule(line string) bool {
+	return len(line) >= 3 && strings.Trim(line, "-=") == ""
+}
+
+type parser struct {
+	status *Status
+	errs   []error
+
+	deadlockTransaction *DeadlockTransaction
+	inDeadlockQuery     bool
+}
+
+func (p *parser) semaphores(line string) {
+	s := &p.status.Semaphores
+
+	switch {
+	case strings.HasPrefix(line, "OS WAIT ARRAY INFO: reservation count"):
+		p.parseUint(&s.ReservationCount, "reservation count", lastField(line))
+	case strings.HasPrefix(line, "OS WAIT ARRAY INFO: signal count"):
+		p.parseUint(&s.SignalCount, "signal count", lastField(line))
+	}
+
+	if match := longSemaphoreWait.FindStringSubmatch(line); match != nil {
+		s.LongWaits++
+		if seconds, err := strconv.ParseFloat(match[1], 64); err == nil {
+			s.LongestWaitSeconds = max(s.LongestWaitSeconds, seconds)
+		}
+	}
+
+	if match := rwOSWaits.FindStringSubmatch(line); match != nil {
+		switch match[1] {
+		case "shared":
+			p.parseUint(&s.RWSharedOSWaits, "RW-shared OS waits", match[2])
+		case "excl":
+			p.parseUint(&s.RWExclOSWaits, "RW-excl OS waits", match[2])
+		case "sx":
+			p.parseUint(&s.RWSXOSWaits, "RW-sx OS waits", match[2])
+		}
+	}
+}
+
+func (p *parser) deadlock(line string) {
+	if p.status.LatestDeadlock == nil {
+		if len(line) < len(time.DateTime) {
+			return
+		}
+
+		detectedAt, err := time.ParseInLocation(time.DateTime, line[:len(time.DateTime)], time.Local)
+		if err != nil {
+			p.errs = append(p.errs, fmt.Errorf("invalid deadlock time %q: %w", line, err))
+			return
+		}
+		p.status.LatestDeadlock = &Deadlock{Time: detectedAt}
+		return
+	}
+
+	deadlock := p.status.LatestDeadlock
+
+	if deadlockTransaction.MatchString(line) {
+		deadlock.Transactions = append(deadlock.Transactions, DeadlockTransaction{})
+		p.deadlockTransaction = &deadlock.Transactions[len(deadlock.Transactions)-1]
+		p.inDeadlockQuery = false
+		return
+	}
+
+	if match := rollBack.FindStringSubmatch(line); match != nil {
+		victim, _ := strconv.Atoi(match[1])
+		if victim >= 1 && victim <= len(deadlock.Transactions) {
+			deadlock.RolledBack = deadlock.Transactions[victim-1].ID
+		}
+		p.deadlockTransaction = nil
+		return
+	}
+
+	if p.deadlockTransaction == nil {
+		return
+	}
+
+	switch {
+	case strings.HasPrefix(line, "*** "):
+		p.deadlockTransaction = nil
+	case transactionID.MatchString(line) && p.deadlockTransaction.ID == "":
+		p.deadlockTransaction.ID = transactionID.FindStringSubmatch(line)[1]
+	case strings.HasPrefix(line, "MySQL thread id "):
+		p.inDeadlockQuery = true
+	case p.inDeadlockQuery && line == "":
+		p.inDeadlockQuery = false
+	case p.inDeadlockQuery:
+		if p.deadlockTransaction.Query != "" {
+			p.deadlockTransaction.Query += "\n"
+		}
+		p.deadlockTransaction.Query += line
+	}
+}
+
+func (p *parser) transactions(line string) {
+	if strings.HasPrefix(line, "History list length ") {
+		p.parseUint(&p.status.HistoryListLength, "history list length", lastField(line))
+	}
+}
+
+func (p *parser) fileIO(line string) {
+	io := &p.status.PendingIO
+
+	if match := pendingAIO.FindStringSubmatch(line); match != nil {
+		io.NormalAIOReads = p.pendingTotal("pending normal aio reads", match[1], match[2])
+		io.NormalAIOWrites = p.pendingTotal("pending normal aio writes", match[3], match[4])
+	}
+
+	if match := pendingIbuf.FindStringSubmatch(line); match != nil {
+		p.parseUint(&io.InsertBufferAIOReads, "pending ibuf aio reads", match[1])
+		p.parseUint(&io.LogIOs, "pending log i/o's", match[2])
+		p.parseUint(&io.SyncIOs, "pending sync i/o's", match[3])
+	}
+
+	if match := pendingFlushes.FindStringSubmatch(line); match != nil {
+		p.parseUint(&io.LogFlushes, "pending log flushes", match[1])
+		p.parseUint(&io.BufferPoolFlushes, "pending buffer pool flushes", match[2])
+	}
+}
+
+// pendingTotal returns the total number of pending aio requests. MySQL 5.7
+// prints the total before the per-thread counts, 8.0 only the per-thread
+// counts.
+func (p *parser) pendingTotal(name, total, perThread string) uint64 {
+	var value uint64
+	if total != "" {
+		p.parseUint(&value, name, total)
+		return value
+	}
+
+	for _, count := range strings.Split(perThread, ",") {
+		var threadValue uint64
+		if count = strings.TrimSpace(count); count == "" {
+			continue
+		}
+		p.parseUint(&threadValue, name, count)
+		value += threadValue
+	}
+	return value
+}
+
+func (p *parser) log(line string) {
+	l := &p.status.Log
+
+	fields := map[string]*uint64{
+		"Log sequence number": &l.SequenceNumber,
+		"Log flushed up to":   &l.FlushedUpTo,
+		"Pages flushed up to": &l.PagesFlushedUpTo,
+		"Last checkpoint at":  &l.LastCheckpointAt,
+		"Max checkpoint age":  &l.MaxCheckpointAge,
+	}
+	for prefix, field := range fields {
+		if strings.HasPrefix(line, prefix+" ") {
+			p.parseUint(field, strings.ToLower(prefix), lastField(line))
+		}
+	}
+}
+
+func (p *parser) bufferPool(line string) {
+	io := &p.status.PendingIO
+
+	if strings.HasPrefix(line, "Pending reads ") {
+		p.parseUint(&io.BufferPoolReads, "pending reads", lastField(line))
+	}
+
+	if match := pendingWrites.FindStringSubmatch(line); match != nil {
+		p.parseUint(&io.BufferPoolLRUWrites, "pending LRU writes", match[1])
+		p.parseUint(&io.BufferPoolFlushListWrites, "pending flush list writes", match[2])
+	}
+}
+
+func (p *parser) parseUint(field *uint64, name, value string) {
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("invalid %s %q", name, value))
+		return
+	}
+	*field = parsed
+}
+
+func lastField(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
@@ -0,0 +1,178 @@
+package innodbstatus_test
+
+import (
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/innodbstatus"
+)
+
+var _ = Describe("Parse", func() {
+	readFixture := func(name string) string {
+		contents, err := os.ReadFile("../fixtures/innodb-status/" + name)
+		Expect(err).NotTo(HaveOccurred())
+		return string(contents)
+	}
+
+	Context("MySQL 8.0", func() {
+		var status *innodbstatus.Status
+
+		BeforeEach(func() {
+			var err error
+			status, err = innodbstatus.Parse(readFixture("mysql-8.0.txt"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("parses the semaphores", func() {
+			Expect(status.Semaphores).To(Equal(innodbstatus.Semaphores{
+				ReservationCount: 2571,
+				SignalCount:      2489,
+			}))
+		})
+
+		It("parses the latest detected deadlock", func() {
+			Expect(status.LatestDeadlock).To(Equal(&innodbstatus.Deadlock{
+				Time: time.Date(2026, 3, 4, 10, 20, 11, 0, time.Local),
+				Transactions: []innodbstatus.DeadlockTransaction{
+					{ID: "6031", Query: "UPDATE accounts SET balance = balance - 10 WHERE id = 2"},
+					{ID: "6032", Query: "UPDATE accounts SET balance = balance + 10 WHERE id = 1"},
+				},
+				RolledBack: "6032",
+			}))
+		})
+
+		It("parses the history list length", func() {
+			Expect(status.HistoryListLength).To(Equal(uint64(42)))
+		})
+
+		It("parses the redo log", func() {
+			Expect(status.Log).To(Equal(innodbstatus.Log{
+				SequenceNumber:   19283746,
+				FlushedUpTo:      19283746,
+				PagesFlushedUpTo: 19280000,
+				LastCheckpointAt: 19270000,
+			}))
+			Expect(status.Log.CheckpointAge()).To(Equal(uint64(13746)))
+		})
+
+		It("sums the pending I/O of every thread", func() {
+			Expect(status.PendingIO).To(Equal(innodbstatus.PendingIO{
+				NormalAIOReads:            3,
+				NormalAIOWrites:           3,
+				LogFlushes:                1,
+				BufferPoolFlushListWrites: 2,
+			}))
+		})
+	})
+
+	Context("Percona XtraDB", func() {
+		var status *innodbstatus.Status
+
+		BeforeEach(func() {
+			var err error
+			status, err = innodbstatus.Parse(readFixture("percona-xtradb-5.7.txt"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("parses the semaphores including long semaphore waits", func() {
+			Expect(status.Semaphores).To(Equal(innodbstatus.Semaphores{
+				ReservationCount:   182736,
+				SignalCount:        171122,
+				LongWaits:          2,
+				LongestWaitSeconds: 241,
+				RWSharedOSWaits:    22107,
+				RWExclOSWaits:      11038,
+				RWSXOSWaits:        512,
+			}))
+		})
+
+		It("has no latest detected deadlock", func() {
+			Expect(status.LatestDeadlock).To(BeNil())
+		})
+
+		It("parses the history list length", func() {
+			Expect(status.HistoryListLength).To(Equal(uint64(128734)))
+		})
+
+		It("parses the redo log including the max checkpoint age", func() {
+			Expect(status.Log).To(Equal(innodbstatus.Log{
+				SequenceNumber:   2612361839,
+				FlushedUpTo:      2612361839,
+				PagesFlushedUpTo: 2612300012,
+				LastCheckpointAt: 2531535675,
+				MaxCheckpointAge: 80826164,
+			}))
+			Expect(status.Log.CheckpointAge()).To(Equal(uint64(80826164)))
+		})
+
+		It("parses the pending I/O totals", func() {
+			Expect(status.PendingIO).To(Equal(innodbstatus.PendingIO{
+				NormalAIOReads:            4,
+				NormalAIOWrites:           7,
+				InsertBufferAIOReads:      0,
+				LogIOs:                    1,
+				SyncIOs:                   2,
+				LogFlushes:                3,
+				BufferPoolFlushes:         5,
+				BufferPoolReads:           3,
+				BufferPoolLRUWrites:       1,
+				BufferPoolFlushListWrites: 6,
+			}))
+		})
+	})
+
+	It("returns an error for text that is not InnoDB status output", func() {
+		_, err := innodbstatus.Parse("Empty set")
+		Expect(err).To(MatchError(innodbstatus.ErrNotInnoDBStatus))
+	})
+
+	It("reports values that cannot be parsed and keeps the rest", func() {
+		status, err := innodbstatus.Parse(`
+=====================================
+2026-03-04 10:23:45 139872354055936 INNODB MONITOR OUTPUT
+=====================================
+------------
+TRANSACTIONS
+------------
+History list length lots
+---
+LOG
+---
+Log sequence number 100
+Last checkpoint at  40
+`)
+		Expect(err).To(MatchError(`invalid history list length "lots"`))
+		Expect(status.Log.CheckpointAge()).To(Equal(uint64(60)))
+	})
+})
+
+var _ = Describe("Status", func() {
+	It("converts the status to metric values", func() {
+		status := &innodbstatus.Status{
+			Semaphores:        innodbstatus.Semaphores{ReservationCount: 10, LongWaits: 1, LongestWaitSeconds: 241},
+			LatestDeadlock:    &innodbstatus.Deadlock{Time: time.Unix(1772619611, 0)},
+			HistoryListLength: 42,
+			Log:               innodbstatus.Log{SequenceNumber: 100, LastCheckpointAt: 40, MaxCheckpointAge: 80},
+			PendingIO:         innodbstatus.PendingIO{NormalAIOReads: 3},
+		}
+
+		values := status.Values()
+		Expect(values).To(HaveKeyWithValue("semaphore_reservation_count", "10"))
+		Expect(values).To(HaveKeyWithValue("semaphore_long_waits", "1"))
+		Expect(values).To(HaveKeyWithValue("semaphore_longest_wait", "241.00"))
+		Expect(values).To(HaveKeyWithValue("latest_deadlock", "1772619611"))
+		Expect(values).To(HaveKeyWithValue("history_list_length", "42"))
+		Expect(values).To(HaveKeyWithValue("checkpoint_age", "60"))
+		Expect(values).To(HaveKeyWithValue("max_checkpoint_age", "80"))
+		Expect(values).To(HaveKeyWithValue("pending_normal_aio_reads", "3"))
+	})
+
+	It("leaves out values that are not known", func() {
+		values := (&innodbstatus.Status{}).Values()
+		Expect(values).NotTo(HaveKey("latest_deadlock"))
+		Expect(values).NotTo(HaveKey("max_checkpoint_age"))
+	})
+})
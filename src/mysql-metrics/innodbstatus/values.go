@@ -0,0 +1,43 @@
+package innodbstatus
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Values returns the status as the string map consumed by the metrics
+// computer.
+func (s *Status) Values() map[string]string {
+	values := map[string]string{
+		"semaphore_reservation_count":           strconv.FormatUint(s.Semaphores.ReservationCount, 10),
+		"semaphore_signal_count":                strconv.FormatUint(s.Semaphores.SignalCount, 10),
+		"semaphore_long_waits":                  strconv.Itoa(s.Semaphores.LongWaits),
+		"semaphore_longest_wait":                fmt.Sprintf("%.2f", s.Semaphores.LongestWaitSeconds),
+		"rw_shared_os_waits":                    strconv.FormatUint(s.Semaphores.RWSharedOSWaits, 10),
+		"rw_excl_os_waits":                      strconv.FormatUint(s.Semaphores.RWExclOSWaits, 10),
+		"rw_sx_os_waits":                        strconv.FormatUint(s.Semaphores.RWSXOSWaits, 10),
+		"history_list_length":                   strconv.FormatUint(s.HistoryListLength, 10),
+		"log_sequence_number":                   strconv.FormatUint(s.Log.SequenceNumber, 10),
+		"checkpoint_age":                        strconv.FormatUint(s.Log.CheckpointAge(), 10),
+		"pending_normal_aio_reads":              strconv.FormatUint(s.PendingIO.NormalAIOReads, 10),
+		"pending_normal_aio_writes":             strconv.FormatUint(s.PendingIO.NormalAIOWrites, 10),
+		"pending_ibuf_aio_reads":                strconv.FormatUint(s.PendingIO.InsertBufferAIOReads, 10),
+		"pending_log_ios":                       strconv.FormatUint(s.PendingIO.LogIOs, 10),
+		"pending_sync_ios":                      strconv.FormatUint(s.PendingIO.SyncIOs, 10),
+		"pending_log_flushes":                   strconv.FormatUint(s.PendingIO.LogFlushes, 10),
+		"pending_buffer_pool_flushes":           strconv.FormatUint(s.PendingIO.BufferPoolFlushes, 10),
+		"pending_buffer_pool_reads":             strconv.FormatUint(s.PendingIO.BufferPoolReads, 10),
+		"pending_buffer_pool_lru_writes":        strconv.FormatUint(s.PendingIO.BufferPoolLRUWrites, 10),
+		"pending_buffer_pool_flush_list_writes": strconv.FormatUint(s.PendingIO.BufferPoolFlushListWrites, 10),
+	}
+
+	if s.Log.MaxCheckpointAge > 0 {
+		values["max_checkpoint_age"] = strconv.FormatUint(s.Log.MaxCheckpointAge, 10)
+	}
+
+	if s.LatestDeadlock != nil {
+		values["latest_deadlock"] = strconv.FormatInt(s.LatestDeadlock.Time.Unix(), 10)
+	}
+
+	return values
+}
@@ -16,6 +16,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 
 	"github.com/cloudfoundry/mysql-metrics/internal/testing/docker"
@@ -38,6 +39,8 @@ type MetricsConfig struct {
 	EmitGaleraMetrics         bool   `json:"emit_galera_metrics"`
 	EmitDiskMetrics           bool   `json:"emit_disk_metrics"`
 	EmitCPUMetrics            bool   `json:"emit_cpu_metrics"`
+	HeartbeatDatabase         string `json:"heartbeat_database"`
+	HeartbeatTable            string `json:"heartbeat_table"`
 	LoggregatorCAPath         string `json:"loggregator_ca_path"`
 	LoggregatorClientCertPath string `json:"loggregator_client_cert_path"`
 	LoggregatorClientKeyPath  string `json:"loggregator_client_key_path"`
@@ -69,7 +72,7 @@ var _ = DescribeTableSubtree("mysql-metrics", Ordered, func(mysqlVersion string)
 		resource, err = docker.RunContainer(docker.ContainerSpec{
 			Image: "percona/percona-server:" + mysqlVersion,
 			Ports: []string{"3306/tcp"},
-			Env:   []string{"MYSQL_ALLOW_EMPTY_PASSWORD=1"},
+			Env:   []string{"MYSQL_ROOT_PASSWORD=password"},
 		})
 		Expect(err).NotTo(HaveOccurred())
 		DeferCleanup(func() {
@@ -79,7 +82,7 @@ var _ = DescribeTableSubtree("mysql-metrics", Ordered, func(mysqlVersion string)
 		mysqlPort, err = docker.ContainerPort(resource, "3306/tcp")
 		Expect(err).NotTo(HaveOccurred())
 
-		db, err := sql.Open("mysql", fmt.Sprintf("root@tcp(localhost:%s)/", mysqlPort))
+		db, err := sql.Open("mysql", fmt.Sprintf("root:password@tcp(localhost:%s)/", mysqlPort))
 		Expect(err).NotTo(HaveOccurred())
 		Eventually(db.Ping, "5m", "1s").Should(Succeed())
 		Expect(db.Exec(`CHANGE REPLICATION SOURCE TO SOURCE_HOST = 'some-host', SOURCE_USER = 'some-user', SOURCE_PASSWORD = 'some-password'`)).
@@ -90,7 +93,7 @@ var _ = DescribeTableSubtree("mysql-metrics", Ordered, func(mysqlVersion string)
 
 	BeforeEach(func() {
 		username = "root"
-		password = ""
+		password = "password"
 
 		metricFrequency = 1
 		var err error
@@ -116,6 +119,8 @@ var _ = DescribeTableSubtree("mysql-metrics", Ordered, func(mysqlVersion string)
 			EmitGaleraMetrics:         true,
 			EmitDiskMetrics:           true,
 			EmitCPUMetrics:            true,
+			HeartbeatDatabase:         "replication_monitoring",
+			HeartbeatTable:            "heartbeat",
 			LoggregatorCAPath:         "../fixtures/certs/loggregator.crt",
 			LoggregatorClientCertPath: "../fixtures/certs/loggregator-agent.crt",
 			LoggregatorClientKeyPath:  "../fixtures/certs/loggregator-agent.key",
@@ -206,6 +211,52 @@ var _ = DescribeTableSubtree("mysql-metrics", Ordered, func(mysqlVersion string)
 		})
 	})
 
+	Describe("with -validate", func() {
+		It("exits successfully when the config is valid", func() {
+			runMainWithArgs("-validate")
+
+			Eventually(session).Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("config is valid"))
+		})
+
+		It("lists every problem and exits non-zero when the config is not valid", func() {
+			Expect(os.WriteFile(configFilepath, []byte(`{"metrics_frequency":0}`), os.ModePerm)).To(Succeed())
+
+			runMainWithArgs("-validate")
+
+			Eventually(session).Should(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("metrics_frequency is out of range"))
+			Expect(session.Err).To(gbytes.Say("password is required"))
+		})
+	})
+
+	Describe("with -once", func() {
+		It("prints the metrics of a single cycle and exits", func() {
+			runMainWithArgs("-once", "-output", "json", "-sampleInterval", "1s")
+
+			Eventually(session, 30*time.Second).Should(gexec.Exit())
+
+			var printed []map[string]any
+			Expect(json.Unmarshal(session.Out.Contents(), &printed)).To(Succeed())
+			Expect(printed).To(ContainElement(And(
+				HaveKeyWithValue("key", HaveSuffix("/available")),
+				HaveKeyWithValue("value", 1.0),
+				HaveKeyWithValue("raw_value", Not(BeEmpty())),
+			)))
+			Expect(printed).To(ContainElement(And(
+				HaveKeyWithValue("key", HaveSuffix("/performance/queries_rate")),
+				HaveKeyWithValue("value", Not(BeNil())),
+			)))
+		})
+
+		It("refuses to print the metrics without -once", func() {
+			runMainWithArgs("-output", "json")
+
+			Eventually(session).Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("-output requires -once"))
+		})
+	})
+
 	Describe("when the database is unreachable", func() {
 		It("still logs that it has emitted the available=0 metric", func() {
 			configFilepath = "../fixtures/bad-credentials-fixtures.yml"
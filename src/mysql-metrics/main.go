@@ -1,31 +1,40 @@
 package main
 
 import (
-	"database/sql"
+	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
 	"syscall"
-	"time"
 
 	"code.cloudfoundry.org/lager/v3/lagerflags"
 
+	"github.com/cloudfoundry/mysql-metrics/alert"
 	"github.com/cloudfoundry/mysql-metrics/config"
-	"github.com/cloudfoundry/mysql-metrics/cpu"
-	"github.com/cloudfoundry/mysql-metrics/database_client"
-	"github.com/cloudfoundry/mysql-metrics/disk"
-	"github.com/cloudfoundry/mysql-metrics/diskstat"
-	"github.com/cloudfoundry/mysql-metrics/emit"
-	"github.com/cloudfoundry/mysql-metrics/gather"
 	"github.com/cloudfoundry/mysql-metrics/metrics"
-	"github.com/cloudfoundry/mysql-metrics/metrics_computer"
+	"github.com/cloudfoundry/mysql-metrics/status"
 
 	"code.cloudfoundry.org/go-loggregator/v9"
+	"code.cloudfoundry.org/go-loggregator/v9/rpc/loggregator_v2"
 	"code.cloudfoundry.org/lager/v3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 const (
-	defaultConfigPath = "/var/vcap/jobs/mysql-metrics/config/mysql-config.yml"
+	defaultConfigPath              = "/var/vcap/jobs/mysql-metrics/config/mysql-config.yml"
+	defaultPrometheusListenAddress = ":9104"
+	loggregatorAddr                = "localhost:3458"
 )
 
 type lagerLoggerWrapper struct {
@@ -42,8 +51,8 @@ func (d lagerLoggerWrapper) Debug(action string, message map[string]interface{})
 	d.logger.Debug(action, data)
 }
 
-func (d lagerLoggerWrapper) Info(action string) {
-	d.logger.Info(action)
+func (d lagerLoggerWrapper) Info(action string, message map[string]interface{}) {
+	d.logger.Info(action, lager.Data(message))
 }
 
 func (d lagerLoggerWrapper) Error(action string, err error) {
@@ -53,6 +62,10 @@ func (d lagerLoggerWrapper) Error(action string, err error) {
 var configFilepath = flag.String("c", defaultConfigPath, "location of config file")
 var logFilepath = flag.String("l", "", "location of log file")
 var timeFormat = flag.String("timeFormat", "", "timestamp format for logs")
+var validateOnly = flag.Bool("validate", false, "validate the config file and exit")
+var once = flag.Bool("once", false, "run a single cycle and exit")
+var outputFormat = flag.String("output", "", "with -once, print the metrics to stdout as json, text or prom instead of sending them")
+var sampleInterval = flag.Duration("sampleInterval", 0, "with -once, run a first cycle this long before the one emitted, so that rates have a value")
 
 func setupLogging(metricsLogger lager.Logger) {
 	if *logFilepath != "" {
@@ -74,9 +87,29 @@ func setupLogging(metricsLogger lager.Logger) {
 func main() {
 	flag.Parse()
 
-	lagerConfig := lagerflags.DefaultLagerConfig()
-	lagerConfig.TimeFormat.Set(*timeFormat)
-	metricsLogger, _ := lagerflags.NewFromConfig("MetricsLogger", lagerConfig)
+	if *validateOnly {
+		os.Exit(validateConfig(*configFilepath))
+	}
+
+	if *outputFormat != "" && !*once {
+		fmt.Fprintln(os.Stderr, "-output requires -once")
+		os.Exit(2)
+	}
+	if *outputFormat != "" && !slices.Contains(metrics.OutputFormats, *outputFormat) {
+		fmt.Fprintf(os.Stderr, "unknown -output format %q, expected one of %s\n", *outputFormat, strings.Join(metrics.OutputFormats, ", "))
+		os.Exit(2)
+	}
+
+	var metricsLogger lager.Logger
+	if *outputFormat != "" {
+		// stdout is left to the metrics.
+		metricsLogger = lager.NewLogger("MetricsLogger")
+		metricsLogger.RegisterSink(lager.NewWriterSink(os.Stderr, lager.INFO))
+	} else {
+		lagerConfig := lagerflags.DefaultLagerConfig()
+		lagerConfig.TimeFormat.Set(*timeFormat)
+		metricsLogger, _ = lagerflags.NewFromConfig("MetricsLogger", lagerConfig)
+	}
 
 	setupLogging(metricsLogger)
 
@@ -85,64 +118,338 @@ func main() {
 		metricsLogger.Error("config file is not formatted correctly", err)
 		panic(err)
 	}
+	if err := mysqlMetricsConfig.Validate(); err != nil {
+		metricsLogger.Error("config file is not valid", err)
+		panic(err)
+	}
 
 	metricMappingConfig := metrics.DefaultMetricMappingConfig()
+	if mysqlMetricsConfig.MetricMappingPath != "" {
+		var err error
+		metricMappingConfig, err = metrics.LoadMetricMappingConfig(mysqlMetricsConfig.MetricMappingPath, metricMappingConfig)
+		if err != nil {
+			metricsLogger.Error("metric mapping file is not valid", err)
+			panic(err)
+		}
+	}
 
-	tlsConfig, err := loggregator.NewIngressTLSConfig(
-		mysqlMetricsConfig.LoggregatorCAPath,
-		mysqlMetricsConfig.LoggregatorClientCertPath,
-		mysqlMetricsConfig.LoggregatorClientKeyPath,
-	)
-	if err != nil {
-		metricsLogger.Error("loggregator tls config failed to initialize", err)
-		panic(err)
+	var metricsSenders *senders
+	var alertSinks []alert.Sink
+	var outputs []*metrics.OutputWriter
+	newWriter := func(cfg *config.Config, logger metrics.Logger) metrics.Writer {
+		if *outputFormat != "" {
+			output := metrics.NewOutputWriter(cfg.Origin)
+			outputs = append(outputs, output)
+			return output
+		}
+		return metrics.NewMetricWriter(metricsSenders.forTarget(), logger, cfg.Origin)
 	}
 
-	ingressClient, err := loggregator.NewIngressClient(
-		tlsConfig,
-		loggregator.WithAddr("localhost:3458"),
-		loggregator.WithTag("source_id", mysqlMetricsConfig.SourceID),
-		loggregator.WithTag("origin", mysqlMetricsConfig.Origin),
-	)
-	if err != nil {
-		metricsLogger.Error("loggregator client failed to initialize", err)
-		panic(err)
+	if *outputFormat == "" {
+		var err error
+		metricsSenders, err = newSenders(mysqlMetricsConfig, metricsLogger)
+		if err != nil {
+			panic(err)
+		}
+		alertSinks = newAlertSinks(mysqlMetricsConfig)
 	}
-	sender := metrics.NewLoggregatorSender(ingressClient, mysqlMetricsConfig.SourceID)
 
-	conn := Connection(mysqlMetricsConfig)
-	dbClient := database_client.NewDatabaseClient(conn, mysqlMetricsConfig)
-	stater := disk.NewInfo(syscall.Statfs)
-	procStatFile, err := os.Open("/proc/stat")
-	if err != nil {
-		metricsLogger.Error("failed to open /proc/stat", err)
-		panic(err)
+	var targets []*target
+	for _, targetConfig := range mysqlMetricsConfig.TargetConfigs() {
+		t, err := newTarget(targetConfig, metricMappingConfig, newWriter, alertSinks, metricsLogger)
+		if err != nil {
+			panic(err)
+		}
+		targets = append(targets, t)
 	}
-	cpustater := cpu.New(procStatFile)
-	monitor, err := diskstat.NewVolumeMonitor()
-	if err != nil {
-		metricsLogger.Error("failed to initialize volume monitor", err)
-		panic(err)
+
+	if *once {
+		exitCode := runOnce(targets, outputs, *outputFormat, *sampleInterval, metricsLogger)
+		metricsSenders.close(metricsLogger)
+		os.Exit(exitCode)
+	}
+
+	if mysqlMetricsConfig.StatusListenAddress != "" {
+		listener, err := net.Listen("tcp", mysqlMetricsConfig.StatusListenAddress)
+		if err != nil {
+			metricsLogger.Error("status listener failed to initialize", err)
+			panic(err)
+		}
+
+		handler := status.NewHandler(targets[0].processor)
+		if len(mysqlMetricsConfig.Targets) > 0 {
+			sources := make(map[string]status.Source, len(targets))
+			for _, t := range targets {
+				sources[t.config.TargetName] = t.processor
+			}
+			handler = status.NewTargetsHandler(sources)
+		}
+
+		go func() {
+			if err := http.Serve(listener, handler); err != nil {
+				metricsLogger.Error("status listener stopped", err)
+			}
+		}()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.emitter.Start(ctx)
+		}()
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(stopped)
+	}()
+
+	reloader := &reloader{
+		config:              mysqlMetricsConfig,
+		metricMappingConfig: metricMappingConfig,
+		targets:             targets,
+		senders:             metricsSenders,
+		logger:              metricsLogger,
+	}
+
+running:
+	for {
+		select {
+		case <-hup:
+			reloader.reload(*configFilepath)
+		case <-stopped:
+			break running
+		}
+	}
+
+	metricsLogger.Info("shutting down")
+	for _, t := range targets {
+		t.close()
+	}
+	metricsSenders.close(metricsLogger)
+}
+
+// validateConfig reports every problem with the config file at filepath and
+// returns the exit code of the -validate mode.
+func validateConfig(filepath string) int {
+	mysqlMetricsConfig := &config.Config{}
+	if err := config.LoadFromFile(filepath, mysqlMetricsConfig); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", filepath, err)
+		return 1
+	}
+
+	var validationErr *config.ValidationError
+	if err := mysqlMetricsConfig.Validate(); errors.As(err, &validationErr) {
+		for _, fieldErr := range validationErr.Errors {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filepath, fieldErr)
+		}
+		return 1
+	}
+
+	fmt.Printf("%s: config is valid\n", filepath)
+	return 0
+}
+
+// newAlertSinks returns the sinks the alerts of every target are sent to. Alert
+// metrics are emitted by the processors rather than through a sink.
+func newAlertSinks(mysqlMetricsConfig *config.Config) []alert.Sink {
+	var sinks []alert.Sink
+	if mysqlMetricsConfig.AlertWebhookURL != "" {
+		sinks = append(sinks, alert.NewWebhookSink(mysqlMetricsConfig.AlertWebhookURL))
+	}
+	if mysqlMetricsConfig.AlertFilePath != "" {
+		sinks = append(sinks, alert.NewFileSink(mysqlMetricsConfig.AlertFilePath))
 	}
-	gatherer := gather.NewGatherer(dbClient, stater, &cpustater, monitor)
 
-	loggerWrapper := lagerLoggerWrapper{metricsLogger}
-	metricsComputer := metrics_computer.NewMetricsComputer(*metricMappingConfig)
-	metricsWriter := metrics.NewMetricWriter(sender, loggerWrapper, mysqlMetricsConfig.Origin)
-	processor := metrics.NewProcessor(gatherer, metricsComputer, metricsWriter, mysqlMetricsConfig)
-	metricsInterval := time.Duration(mysqlMetricsConfig.MetricsFrequency) * time.Second
-	emitter := emit.NewEmitter(processor, metricsInterval, time.Sleep, loggerWrapper)
+	return sinks
+}
+
+// senders creates the senders the metrics of every target are written to.
+// The loggregator and otlp clients and the prometheus listener are shared by
+// the targets, but every target buffers the values of its cycles in senders
+// of its own, so that flushing one target neither sends the half-written cycle
+// of another nor reports its failures.
+type senders struct {
+	config                 *config.Config
+	loggregatorClient      *loggregator.IngressClient
+	loggregatorBatchClient loggregator_v2.IngressClient
+	prometheusHandler      *metrics.PrometheusHandler
+	otlpExporter           metrics.OTLPExporter
+	connections            []io.Closer
+	targets                []metrics.MultiSender
+}
+
+func newSenders(mysqlMetricsConfig *config.Config, metricsLogger lager.Logger) (*senders, error) {
+	s := &senders{config: mysqlMetricsConfig}
+
+	if mysqlMetricsConfig.SenderEnabled(config.SenderLoggregator) {
+		tlsConfig, err := loggregator.NewIngressTLSConfig(
+			mysqlMetricsConfig.LoggregatorCAPath,
+			mysqlMetricsConfig.LoggregatorClientCertPath,
+			mysqlMetricsConfig.LoggregatorClientKeyPath,
+		)
+		if err != nil {
+			metricsLogger.Error("loggregator tls config failed to initialize", err)
+			return nil, err
+		}
+
+		if mysqlMetricsConfig.LoggregatorBatch {
+			conn, err := grpc.NewClient(loggregatorAddr, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+			if err != nil {
+				metricsLogger.Error("loggregator client failed to initialize", err)
+				return nil, err
+			}
+			s.connections = append(s.connections, conn)
+			s.loggregatorBatchClient = loggregator_v2.NewIngressClient(conn)
+		} else {
+			s.loggregatorClient, err = loggregator.NewIngressClient(
+				tlsConfig,
+				loggregator.WithAddr(loggregatorAddr),
+				loggregator.WithTag("source_id", mysqlMetricsConfig.SourceID),
+				loggregator.WithTag("origin", mysqlMetricsConfig.Origin),
+			)
+			if err != nil {
+				metricsLogger.Error("loggregator client failed to initialize", err)
+				return nil, err
+			}
+		}
+	}
+
+	if mysqlMetricsConfig.SenderEnabled(config.SenderPrometheus) {
+		s.prometheusHandler = metrics.NewPrometheusHandler(mysqlMetricsConfig.ValueExpiry())
+
+		listenAddress := mysqlMetricsConfig.PrometheusListenAddress
+		if listenAddress == "" {
+			listenAddress = defaultPrometheusListenAddress
+		}
+
+		listener, err := net.Listen("tcp", listenAddress)
+		if err != nil {
+			metricsLogger.Error("prometheus listener failed to initialize", err)
+			return nil, err
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", s.prometheusHandler)
+		go func() {
+			if err := http.Serve(listener, mux); err != nil {
+				metricsLogger.Error("prometheus listener stopped", err)
+			}
+		}()
+	}
+
+	if mysqlMetricsConfig.SenderEnabled(config.SenderOTLP) {
+		exporter, err := s.newOTLPExporter()
+		if err != nil {
+			metricsLogger.Error("otlp exporter failed to initialize", err)
+			return nil, err
+		}
+		s.otlpExporter = exporter
+	}
+
+	if s.loggregatorClient == nil && s.loggregatorBatchClient == nil && s.prometheusHandler == nil && s.otlpExporter == nil {
+		err := fmt.Errorf("no supported metrics senders configured: %v", mysqlMetricsConfig.Senders)
+		metricsLogger.Error("metrics sender failed to initialize", err)
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *senders) newOTLPExporter() (metrics.OTLPExporter, error) {
+	if s.config.OTLPEndpoint == "" {
+		return nil, errors.New("otlp_endpoint is required when the otlp sender is enabled")
+	}
+
+	switch s.config.OTLPProtocol {
+	case "", config.OTLPProtocolGRPC:
+		transportCredentials := credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		if s.config.OTLPInsecure {
+			transportCredentials = insecure.NewCredentials()
+		}
+
+		conn, err := grpc.NewClient(s.config.OTLPEndpoint, grpc.WithTransportCredentials(transportCredentials))
+		if err != nil {
+			return nil, err
+		}
+		s.connections = append(s.connections, conn)
+		return metrics.NewOTLPGRPCExporter(conn), nil
+	case config.OTLPProtocolHTTP:
+		return metrics.NewOTLPHTTPExporter(http.DefaultClient, s.config.OTLPEndpoint), nil
+	default:
+		return nil, fmt.Errorf("unsupported otlp_protocol %q", s.config.OTLPProtocol)
+	}
+}
+
+// reload applies the settings of a reloaded config that the senders read
+// while running.
+func (s *senders) reload(mysqlMetricsConfig *config.Config) {
+	if s == nil || s.prometheusHandler == nil {
+		return
+	}
+
+	s.prometheusHandler.SetMaxAge(mysqlMetricsConfig.ValueExpiry())
+}
+
+// forTarget returns the sender of a new target.
+func (s *senders) forTarget() metrics.Sender {
+	var sender metrics.MultiSender
+
+	if s.loggregatorBatchClient != nil {
+		sender = append(sender, metrics.NewLoggregatorBatchSender(
+			s.loggregatorBatchClient,
+			s.config.SourceID,
+			s.config.InstanceID,
+			map[string]string{"source_id": s.config.SourceID, "origin": s.config.Origin},
+			s.config.LoggregatorEnvelopeSize,
+		))
+	}
+	if s.loggregatorClient != nil {
+		sender = append(sender, metrics.NewLoggregatorSender(s.loggregatorClient, s.config.SourceID, s.config.InstanceID))
+	}
+	if s.prometheusHandler != nil {
+		sender = append(sender, s.prometheusHandler.NewSender())
+	}
+	if s.otlpExporter != nil {
+		sender = append(sender, metrics.NewOTLPSender(s.otlpExporter, s.config.Origin, s.config.SourceID, s.config.InstanceID))
+	}
 
-	emitter.Start()
+	s.targets = append(s.targets, sender)
+	return sender
 }
 
-func Connection(config *config.Config) *sql.DB {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/?tls=preferred", config.Username, config.Password, config.Host, config.Port)
+// close sends the values the senders of every target still hold and closes
+// the shared clients, once no target writes to them any more. It does nothing
+// when the metrics are printed rather than sent.
+func (s *senders) close(metricsLogger lager.Logger) {
+	if s == nil {
+		return
+	}
 
-	conn, err := sql.Open("mysql", dsn)
-	if err != nil {
-		panic(fmt.Sprintf("Database configuration problem: %v", err))
+	for _, sender := range s.targets {
+		if err := sender.Flush(); err != nil {
+			metricsLogger.Error("failed to flush metrics sender", err)
+		}
 	}
 
-	return conn
+	if s.loggregatorClient != nil {
+		if err := s.loggregatorClient.CloseSend(); err != nil {
+			metricsLogger.Error("failed to close loggregator client", err)
+		}
+	}
+	for _, conn := range s.connections {
+		if err := conn.Close(); err != nil {
+			metricsLogger.Error("failed to close metrics sender connection", err)
+		}
+	}
 }
@@ -0,0 +1,13 @@
+package mem_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestMem(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Mem Suite")
+}
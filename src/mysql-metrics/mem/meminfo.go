@@ -0,0 +1,82 @@
+// Package mem reads the memory usage of the host from /proc/meminfo and that of
+// a process, such as mysqld, from /proc/<pid>/status and
+// /proc/<pid>/smaps_rollup.
+package mem
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MemInfo holds the values of /proc/meminfo used by mysql-metrics, in
+// kilobytes.
+type MemInfo struct {
+	MemTotal     uint64
+	MemFree      uint64
+	MemAvailable uint64
+	Buffers      uint64
+	Cached       uint64
+	SwapTotal    uint64
+	SwapFree     uint64
+}
+
+// SwapUsed returns the kilobytes of swap in use.
+func (m MemInfo) SwapUsed() uint64 {
+	if m.SwapFree > m.SwapTotal {
+		return 0
+	}
+
+	return m.SwapTotal - m.SwapFree
+}
+
+// ParseMemInfo parses the contents of /proc/meminfo. MemAvailable, which the
+// kernel has reported since Linux 3.14, is required.
+func ParseMemInfo(r io.Reader) (MemInfo, error) {
+	values, err := parseKilobytes(r)
+	if err != nil {
+		return MemInfo{}, fmt.Errorf("error parsing /proc/meminfo: %w", err)
+	}
+
+	for _, name := range []string{"MemTotal", "MemAvailable", "SwapTotal", "SwapFree"} {
+		if _, ok := values[name]; !ok {
+			return MemInfo{}, fmt.Errorf("/proc/meminfo does not contain %s", name)
+		}
+	}
+
+	return MemInfo{
+		MemTotal:     values["MemTotal"],
+		MemFree:      values["MemFree"],
+		MemAvailable: values["MemAvailable"],
+		Buffers:      values["Buffers"],
+		Cached:       values["Cached"],
+		SwapTotal:    values["SwapTotal"],
+		SwapFree:     values["SwapFree"],
+	}, nil
+}
+
+// parseKilobytes returns the "<name>: <value> kB" lines of a proc file by name.
+// Lines with other values, such as the address range heading smaps_rollup or
+// the "Name:" of /proc/<pid>/status, are skipped.
+func parseKilobytes(r io.Reader) (map[string]uint64, error) {
+	values := make(map[string]uint64)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[2] != "kB" || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+
+		name := strings.TrimSuffix(fields[0], ":")
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q", name, fields[1])
+		}
+		values[name] = value
+	}
+
+	return values, scanner.Err()
+}
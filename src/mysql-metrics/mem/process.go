@@ -0,0 +1,68 @@
+package mem
+
+import (
+	"fmt"
+	"io"
+)
+
+// ProcessMemory is the memory used by a process, in kilobytes.
+type ProcessMemory struct {
+	RSS     uint64
+	PeakRSS uint64
+	Swap    uint64
+
+	// Rollup is nil when smaps_rollup could not be read, as on kernels older
+	// than Linux 4.14.
+	Rollup *SmapsRollup
+}
+
+// SmapsRollup holds the values of /proc/<pid>/smaps_rollup used by
+// mysql-metrics, in kilobytes. Unlike the RSS, the PSS only counts a share of
+// the pages the process shares with others.
+type SmapsRollup struct {
+	RSS       uint64
+	PSS       uint64
+	Anonymous uint64
+	Swap      uint64
+}
+
+// ParseStatus parses the memory usage of a process from /proc/<pid>/status.
+// VmRSS is required; VmHWM and VmSwap are zero when absent.
+func ParseStatus(r io.Reader) (ProcessMemory, error) {
+	values, err := parseKilobytes(r)
+	if err != nil {
+		return ProcessMemory{}, fmt.Errorf("error parsing process status: %w", err)
+	}
+
+	rss, ok := values["VmRSS"]
+	if !ok {
+		return ProcessMemory{}, fmt.Errorf("process status does not contain VmRSS")
+	}
+
+	return ProcessMemory{
+		RSS:     rss,
+		PeakRSS: values["VmHWM"],
+		Swap:    values["VmSwap"],
+	}, nil
+}
+
+// ParseSmapsRollup parses /proc/<pid>/smaps_rollup.
+func ParseSmapsRollup(r io.Reader) (SmapsRollup, error) {
+	values, err := parseKilobytes(r)
+	if err != nil {
+		return SmapsRollup{}, fmt.Errorf("error parsing smaps_rollup: %w", err)
+	}
+
+	for _, name := range []string{"Rss", "Pss"} {
+		if _, ok := values[name]; !ok {
+			return SmapsRollup{}, fmt.Errorf("smaps_rollup does not contain %s", name)
+		}
+	}
+
+	return SmapsRollup{
+		RSS:       values["Rss"],
+		PSS:       values["Pss"],
+		Anonymous: values["Anonymous"],
+		Swap:      values["Swap"],
+	}, nil
+}
@@ -0,0 +1,90 @@
+package mem
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ErrSmapsRollup is wrapped by the error of ProcessMemory when only the
+// smaps_rollup values could not be read, in which case the values of the
+// process status are still returned.
+var ErrSmapsRollup = errors.New("failed to read smaps_rollup")
+
+// Stater reads memory usage from the proc filesystem mounted at procDir.
+type Stater struct {
+	procDir string
+}
+
+func New(procDir string) Stater {
+	return Stater{procDir: procDir}
+}
+
+// MemInfo reads the memory usage of the host.
+func (s Stater) MemInfo() (MemInfo, error) {
+	file, err := os.Open(filepath.Join(s.procDir, "meminfo"))
+	if err != nil {
+		return MemInfo{}, err
+	}
+	defer file.Close()
+
+	return ParseMemInfo(file)
+}
+
+// ProcessMemory reads the memory usage of the process whose pid is written in
+// pidFile. The smaps_rollup values are left out when the kernel does not
+// provide the file.
+func (s Stater) ProcessMemory(pidFile string) (ProcessMemory, error) {
+	pid, err := readPidFile(pidFile)
+	if err != nil {
+		return ProcessMemory{}, err
+	}
+
+	processDir := filepath.Join(s.procDir, strconv.Itoa(pid))
+
+	status, err := os.Open(filepath.Join(processDir, "status"))
+	if err != nil {
+		return ProcessMemory{}, err
+	}
+	defer status.Close()
+
+	memory, err := ParseStatus(status)
+	if err != nil {
+		return ProcessMemory{}, err
+	}
+
+	rollupFile, err := os.Open(filepath.Join(processDir, "smaps_rollup"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return memory, nil
+	}
+	if err != nil {
+		return memory, fmt.Errorf("%w: %w", ErrSmapsRollup, err)
+	}
+	defer rollupFile.Close()
+
+	rollup, err := ParseSmapsRollup(rollupFile)
+	if err != nil {
+		return memory, fmt.Errorf("%w: %w", ErrSmapsRollup, err)
+	}
+	memory.Rollup = &rollup
+
+	return memory, nil
+}
+
+func readPidFile(path string) (int, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil || pid <= 0 {
+		return 0, fmt.Errorf("pid file %s does not contain a pid", path)
+	}
+
+	return pid, nil
+}
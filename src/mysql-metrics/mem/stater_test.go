@@ -0,0 +1,121 @@
+package mem_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/mem"
+)
+
+var _ = Describe("ParseMemInfo", func() {
+	It("parses the memory and swap usage", func() {
+		file, err := os.Open("../fixtures/proc/meminfo")
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		memInfo, err := mem.ParseMemInfo(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(memInfo).To(Equal(mem.MemInfo{
+			MemTotal:     8148180,
+			MemFree:      312040,
+			MemAvailable: 2037044,
+			Buffers:      120416,
+			Cached:       1794528,
+			SwapTotal:    2097148,
+			SwapFree:     1572860,
+		}))
+		Expect(memInfo.SwapUsed()).To(Equal(uint64(524288)))
+	})
+
+	It("requires MemAvailable", func() {
+		_, err := mem.ParseMemInfo(strings.NewReader("MemTotal: 8148180 kB\nMemFree: 312040 kB\nSwapTotal: 0 kB\nSwapFree: 0 kB\n"))
+		Expect(err).To(MatchError("/proc/meminfo does not contain MemAvailable"))
+	})
+
+	It("returns an error for an invalid value", func() {
+		_, err := mem.ParseMemInfo(strings.NewReader("MemTotal: lots kB\n"))
+		Expect(err).To(MatchError(`error parsing /proc/meminfo: invalid MemTotal value "lots"`))
+	})
+})
+
+var _ = Describe("ParseStatus", func() {
+	It("requires VmRSS", func() {
+		_, err := mem.ParseStatus(strings.NewReader("Name:\tmysqld\nVmHWM:\t812044 kB\n"))
+		Expect(err).To(MatchError("process status does not contain VmRSS"))
+	})
+})
+
+var _ = Describe("Stater", func() {
+	var (
+		stater  mem.Stater
+		pidFile string
+	)
+
+	writePidFile := func(contents string) {
+		Expect(os.WriteFile(pidFile, []byte(contents), 0o600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		stater = mem.New("../fixtures/proc")
+		pidFile = filepath.Join(GinkgoT().TempDir(), "mysqld.pid")
+	})
+
+	It("reads the memory usage of the host", func() {
+		memInfo, err := stater.MemInfo()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(memInfo.MemAvailable).To(Equal(uint64(2037044)))
+	})
+
+	It("reads the memory usage of the process in the pid file", func() {
+		writePidFile("4242\n")
+
+		memory, err := stater.ProcessMemory(pidFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(memory).To(Equal(mem.ProcessMemory{
+			RSS:     5123084,
+			PeakRSS: 5402316,
+			Swap:    204800,
+			Rollup: &mem.SmapsRollup{
+				RSS:       5123084,
+				PSS:       5098761,
+				Anonymous: 4987240,
+				Swap:      204800,
+			},
+		}))
+	})
+
+	It("leaves out smaps_rollup when the kernel does not provide it", func() {
+		writePidFile("4343")
+
+		memory, err := stater.ProcessMemory(pidFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(memory).To(Equal(mem.ProcessMemory{RSS: 790312, PeakRSS: 812044}))
+	})
+
+	It("returns the values of the process status when smaps_rollup cannot be parsed", func() {
+		writePidFile("4545")
+
+		memory, err := stater.ProcessMemory(pidFile)
+		Expect(err).To(MatchError(mem.ErrSmapsRollup))
+		Expect(err).To(MatchError(ContainSubstring("smaps_rollup does not contain Pss")))
+		Expect(memory).To(Equal(mem.ProcessMemory{RSS: 790312, PeakRSS: 812044}))
+	})
+
+	It("returns an error when the pid file does not contain a pid", func() {
+		writePidFile("mysqld\n")
+
+		_, err := stater.ProcessMemory(pidFile)
+		Expect(err).To(MatchError(ContainSubstring("does not contain a pid")))
+	})
+
+	It("returns an error when the process is not running", func() {
+		writePidFile("4444")
+
+		_, err := stater.ProcessMemory(pidFile)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry/mysql-metrics/config"
+)
+
+// Collector gathers a single category of metrics. The Processor runs every
+// registered collector that is enabled for the cycle concurrently with the
+// others, and computes the metrics of the samples it returns.
+//
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Collector
+type Collector interface {
+	// Name identifies the collector in errors, the collector metrics and the
+	// collectors section of the config.
+	Name() string
+	Enabled(cycle Cycle) bool
+	// Mappings are the metric definitions of the values the collector
+	// gathers, unless a sample has its own.
+	Mappings() map[string]MetricDefinition
+	Collect(ctx context.Context, cycle Cycle) ([]Sample, error)
+}
+
+// TimeoutCollector is implemented by collectors that should be given a timeout
+// other than the configured collector timeout.
+type TimeoutCollector interface {
+	Collector
+	Timeout(cycle Cycle) time.Duration
+}
+
+// Cycle describes the processor cycle a collector is run in.
+type Cycle struct {
+	Config            *config.Config
+	DatabaseAvailable bool
+}
+
+// Sample is a single set of values gathered by a collector. Its tags, which
+// tell apart the series of a metric such as the cpu usage of every core, are
+// sent with every metric computed from it in addition to those of the config.
+// Its mappings, when set, are used instead of those of the collector.
+type Sample struct {
+	Values   map[string]string
+	Tags     map[string]string
+	Mappings map[string]MetricDefinition
+}
+
+// Registry holds the collectors run by the Processor, in the order their
+// metrics are written.
+type Registry struct {
+	collectors []Collector
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds collector to the registry. Collector names must be unique.
+func (r *Registry) Register(collector Collector) error {
+	for _, registered := range r.collectors {
+		if registered.Name() == collector.Name() {
+			return fmt.Errorf("collector %s is already registered", collector.Name())
+		}
+	}
+
+	r.collectors = append(r.collectors, collector)
+	return nil
+}
+
+func (r *Registry) Collectors() []Collector {
+	return r.collectors
+}
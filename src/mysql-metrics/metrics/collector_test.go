@@ -0,0 +1,42 @@
+package metrics_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/metrics/metricsfakes"
+)
+
+var _ = Describe("Registry", func() {
+	var (
+		registry *metrics.Registry
+		cpu      *metricsfakes.FakeCollector
+		broker   *metricsfakes.FakeCollector
+	)
+
+	BeforeEach(func() {
+		registry = metrics.NewRegistry()
+
+		cpu = &metricsfakes.FakeCollector{}
+		cpu.NameReturns("cpu")
+		broker = &metricsfakes.FakeCollector{}
+		broker.NameReturns("broker")
+	})
+
+	It("returns the collectors in the order they were registered", func() {
+		Expect(registry.Register(cpu)).To(Succeed())
+		Expect(registry.Register(broker)).To(Succeed())
+
+		Expect(registry.Collectors()).To(Equal([]metrics.Collector{cpu, broker}))
+	})
+
+	It("rejects a collector whose name is already registered", func() {
+		other := &metricsfakes.FakeCollector{}
+		other.NameReturns("cpu")
+
+		Expect(registry.Register(cpu)).To(Succeed())
+		Expect(registry.Register(other)).To(MatchError("collector cpu is already registered"))
+		Expect(registry.Collectors()).To(Equal([]metrics.Collector{cpu}))
+	})
+})
@@ -0,0 +1,449 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/digest"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
+)
+
+// NewDefaultRegistry returns a registry of the built-in collectors followed by
+// a collector for every configured custom query.
+func NewDefaultRegistry(gatherer Gatherer, mappings *MetricMappingConfig, configuration *config.Config) (*Registry, error) {
+	digests := &digestCollector{
+		builtin:  builtin{name: "digest", requiresDatabase: true, emit: emitDigestMetrics, mappings: mappings.DigestMetricMappings},
+		gatherer: gatherer,
+	}
+	if configuration.DigestReportPath != "" {
+		digests.reporter = digest.NewReporter(configuration.DigestReportPath, configuration.DigestReportIntervalDuration())
+	}
+
+	collectors := []Collector{
+		&mappedCollector{
+			builtin: builtin{name: "disk_usage", emit: emitDiskMetrics, mappings: mappings.DiskUsageMetricMappings},
+			gather:  func(context.Context) (map[string]string, error) { return gatherer.DiskStats() },
+		},
+		&mappedCollector{
+			builtin: builtin{name: "disk_performance", emit: emitDiskMetrics, mappings: mappings.DiskPerformanceMetricMappings},
+			gather:  func(context.Context) (map[string]string, error) { return gatherer.DiskPerformanceStats() },
+		},
+		&mappedCollector{
+			builtin: builtin{name: "broker", emit: emitBrokerMetrics, mappings: mappings.BrokerMetricMappings},
+			gather:  gatherer.BrokerStats,
+		},
+		&cpuCollector{
+			builtin:      builtin{name: "cpu", emit: emitCPUMetrics, mappings: mappings.CPUMetricMappings},
+			coreMappings: mappings.CPUCoreMetricMappings,
+			gatherer:     gatherer,
+		},
+		&memoryCollector{
+			builtin:  builtin{name: "memory", emit: emitMemoryMetrics, mappings: mappings.MemoryMetricMappings},
+			gatherer: gatherer,
+		},
+		&backupCollector{
+			builtin:  builtin{name: "backup", emit: emitBackupMetrics, mappings: mappings.BackupMetricMappings},
+			gatherer: gatherer,
+		},
+		&mysqlCollector{
+			builtin:        builtin{name: "mysql", emit: emitMysqlMetrics, mappings: mappings.MysqlMetricMappings},
+			galeraMappings: mappings.GaleraMetricMappings,
+			gatherer:       gatherer,
+		},
+		&leaderFollowerCollector{
+			builtin:  builtin{name: "leader_follower", emit: emitLeaderFollowerMetrics, mappings: mappings.LeaderFollowerMetricMappings},
+			gatherer: gatherer,
+		},
+		&transactionCollector{
+			builtin:  builtin{name: "transactions", requiresDatabase: true, emit: emitTransactionMetrics, mappings: mappings.TransactionMetricMappings},
+			gatherer: gatherer,
+		},
+		&mappedCollector{
+			builtin: builtin{name: "innodb_status", requiresDatabase: true, emit: emitInnoDBStatusMetrics, mappings: mappings.InnoDBStatusMetricMappings},
+			gather:  gatherer.InnoDBStatus,
+		},
+		digests,
+		&tableSizeCollector{
+			builtin:        builtin{name: "table_size", requiresDatabase: true, emit: emitTableSizeMetrics, mappings: mappings.TableSizeMetricMappings},
+			schemaMappings: mappings.SchemaSizeMetricMappings,
+			gatherer:       gatherer,
+		},
+	}
+
+	for _, query := range configuration.CustomQueries {
+		collectors = append(collectors, &customQueryCollector{
+			builtin:  builtin{name: query.CollectorName(), emit: func(*config.Config) bool { return true }},
+			query:    query,
+			gatherer: gatherer,
+		})
+	}
+
+	registry := NewRegistry()
+	for _, collector := range collectors {
+		if err := registry.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+func emitDiskMetrics(c *config.Config) bool           { return c.EmitDiskMetrics }
+func emitBrokerMetrics(c *config.Config) bool         { return c.EmitBrokerMetrics }
+func emitCPUMetrics(c *config.Config) bool            { return c.EmitCPUMetrics }
+func emitMemoryMetrics(c *config.Config) bool         { return c.EmitMemoryMetrics }
+func emitBackupMetrics(c *config.Config) bool         { return c.EmitBackupMetrics }
+func emitMysqlMetrics(c *config.Config) bool          { return c.EmitMysqlMetrics }
+func emitLeaderFollowerMetrics(c *config.Config) bool { return c.EmitLeaderFollowerMetrics }
+func emitTransactionMetrics(c *config.Config) bool    { return c.EmitTransactionMetrics }
+func emitInnoDBStatusMetrics(c *config.Config) bool   { return c.EmitInnoDBStatusMetrics }
+func emitDigestMetrics(c *config.Config) bool         { return c.EmitDigestMetrics }
+func emitTableSizeMetrics(c *config.Config) bool      { return c.EmitTableSizeMetrics }
+
+// builtin implements the parts of Collector shared by the built-in collectors.
+// Each is enabled by its emit_* flag unless the collectors section of the
+// config says otherwise.
+type builtin struct {
+	name             string
+	emit             func(*config.Config) bool
+	requiresDatabase bool
+	mappings         map[string]MetricDefinition
+}
+
+func (b *builtin) Name() string {
+	return b.name
+}
+
+func (b *builtin) Enabled(cycle Cycle) bool {
+	if b.requiresDatabase && !cycle.DatabaseAvailable {
+		return false
+	}
+
+	return cycle.Config.CollectorEnabled(b.name, b.emit(cycle.Config))
+}
+
+func (b *builtin) Mappings() map[string]MetricDefinition {
+	return b.mappings
+}
+
+// schedule tracks a collector that runs on its own interval rather than every
+// cycle. Only successful runs count, so that a run that failed or timed out is
+// retried in the next cycle.
+type schedule struct {
+	mu      sync.Mutex
+	lastRun time.Time
+	running bool
+}
+
+// due reports whether the collector should run now, which is when it is not
+// running and has not succeeded within interval.
+func (s *schedule) due(interval time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return !s.running && (s.lastRun.IsZero() || time.Since(s.lastRun) >= interval)
+}
+
+// run calls collect, recording when it started if it succeeds.
+func (s *schedule) run(collect func() ([]Sample, error)) ([]Sample, error) {
+	start := time.Now()
+
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
+
+	samples, err := collect()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.running = false
+	if err == nil {
+		s.lastRun = start
+	}
+
+	return samples, err
+}
+
+// mappedCollector emits a single set of gathered values.
+type mappedCollector struct {
+	builtin
+	gather func(ctx context.Context) (map[string]string, error)
+}
+
+func (c *mappedCollector) Collect(ctx context.Context, _ Cycle) ([]Sample, error) {
+	values, err := c.gather(ctx)
+	return []Sample{{Values: values}}, err
+}
+
+// cpuCollector emits the cpu usage and load averages and, when
+// emit_cpu_core_metrics is enabled, the cpu usage of every core, labelled by
+// its number.
+type cpuCollector struct {
+	builtin
+	coreMappings map[string]MetricDefinition
+	gatherer     Gatherer
+}
+
+func (c *cpuCollector) Collect(_ context.Context, cycle Cycle) ([]Sample, error) {
+	values, cores, err := c.gatherer.CPUStats()
+
+	samples := []Sample{{Values: values}}
+	if cycle.Config.EmitCPUCoreMetrics {
+		for _, core := range cores {
+			samples = append(samples, Sample{Values: core, Tags: map[string]string{"core": core["core"]}, Mappings: c.coreMappings})
+		}
+	}
+
+	return samples, err
+}
+
+// memoryCollector emits the memory usage of the host and, when
+// mysqld_pid_file is set, of mysqld.
+type memoryCollector struct {
+	builtin
+	gatherer Gatherer
+}
+
+func (c *memoryCollector) Collect(ctx context.Context, cycle Cycle) ([]Sample, error) {
+	values, err := c.gatherer.MemoryStats(ctx, cycle.Config.MysqldPidFile, cycle.DatabaseAvailable)
+	return []Sample{{Values: values}}, err
+}
+
+type backupCollector struct {
+	builtin
+	gatherer Gatherer
+}
+
+func (c *backupCollector) Collect(ctx context.Context, _ Cycle) ([]Sample, error) {
+	backupTimestamp, err := c.gatherer.FindLastBackupTimestamp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Sample{{Values: map[string]string{
+		"last_successful_backup": strconv.FormatInt(backupTimestamp.Unix(), 10),
+	}}}, nil
+}
+
+// mysqlCollector emits whether the database is available and, while it is,
+// the global status and variables, including those of galera when
+// emit_galera_metrics is enabled.
+type mysqlCollector struct {
+	builtin
+	galeraMappings map[string]MetricDefinition
+	gatherer       Gatherer
+}
+
+func (c *mysqlCollector) Collect(ctx context.Context, cycle Cycle) ([]Sample, error) {
+	samples := []Sample{{Values: map[string]string{"available": boolValue(cycle.DatabaseAvailable)}}}
+	if !cycle.DatabaseAvailable {
+		return samples, nil
+	}
+
+	globalStatus, globalVariables, err := c.gatherer.DatabaseMetadata(ctx)
+
+	samples = append(samples, Sample{Values: globalStatus}, Sample{Values: globalVariables})
+
+	if cycle.Config.EmitGaleraMetrics {
+		samples = append(samples,
+			Sample{Values: globalStatus, Mappings: c.galeraMappings},
+			Sample{Values: globalVariables, Mappings: c.galeraMappings},
+		)
+	}
+
+	return samples, err
+}
+
+type leaderFollowerCollector struct {
+	builtin
+	gatherer Gatherer
+}
+
+func (c *leaderFollowerCollector) Collect(ctx context.Context, _ Cycle) ([]Sample, error) {
+	var collectedErrors error
+
+	isFollower, err := c.gatherer.IsDatabaseFollower(ctx)
+	if err != nil {
+		collectedErrors = errors.Join(collectedErrors, err)
+	}
+
+	samples := []Sample{{Values: map[string]string{"is_follower": boolValue(isFollower)}}}
+
+	if isFollower {
+		slaveStatus, heartbeatStatus, err := c.gatherer.FollowerMetadata(ctx)
+		if err != nil {
+			collectedErrors = errors.Join(collectedErrors, err)
+		}
+
+		samples = append(samples, Sample{Values: heartbeatStatus}, Sample{Values: slaveStatus})
+	}
+
+	return samples, collectedErrors
+}
+
+// transactionCollector emits the transaction and lock wait metrics. Every
+// "transactions_older_than_<threshold>" value is emitted under the
+// transactions_older_than key with "_<threshold>" appended.
+type transactionCollector struct {
+	builtin
+	gatherer Gatherer
+}
+
+func (c *transactionCollector) Collect(ctx context.Context, cycle Cycle) ([]Sample, error) {
+	values, err := c.gatherer.TransactionStats(ctx, cycle.Config.TransactionAgeThresholdSeconds())
+
+	mappings := make(map[string]MetricDefinition, len(c.mappings))
+	for name, mapping := range c.mappings {
+		mappings[name] = mapping
+	}
+
+	if olderThan, ok := mappings["transactions_older_than"]; ok {
+		for name := range values {
+			if threshold, found := strings.CutPrefix(name, "transactions_older_than_"); found {
+				mappings[name] = MetricDefinition{Key: olderThan.Key + "_" + threshold, Unit: olderThan.Unit}
+			}
+		}
+	}
+
+	return []Sample{{Values: values, Mappings: mappings}}, err
+}
+
+// digestCollector emits the metrics of the top statement digests, tagged with
+// their schema and digest, and writes the digest report when one is configured.
+type digestCollector struct {
+	builtin
+	gatherer Gatherer
+	reporter *digest.Reporter
+}
+
+func (c *digestCollector) Collect(ctx context.Context, cycle Cycle) ([]Sample, error) {
+	var samples []Sample
+	var collectedErrors error
+
+	summary, err := c.gatherer.StatementDigests(ctx)
+	if err != nil {
+		collectedErrors = errors.Join(collectedErrors, err)
+	}
+
+	for _, delta := range summary.Top(cycle.Config.DigestLimit()) {
+		values := delta.Values()
+		samples = append(samples, Sample{Values: values, Tags: map[string]string{"schema": values["schema"], "digest": values["digest"]}})
+	}
+
+	if c.reporter != nil && summary.Elapsed > 0 {
+		if err := c.reporter.Write(summary, cycle.Config.DigestLimit(), time.Now()); err != nil {
+			collectedErrors = errors.Join(collectedErrors, fmt.Errorf("failed to write statement digest report: %w", err))
+		}
+	}
+
+	return samples, collectedErrors
+}
+
+// tableSizeCollector emits the size of every schema and of the largest tables,
+// every table_size_interval.
+type tableSizeCollector struct {
+	builtin
+	schedule
+	schemaMappings map[string]MetricDefinition
+	gatherer       Gatherer
+}
+
+func (c *tableSizeCollector) Enabled(cycle Cycle) bool {
+	return c.builtin.Enabled(cycle) && c.due(cycle.Config.TableSizeIntervalDuration())
+}
+
+func (c *tableSizeCollector) Timeout(cycle Cycle) time.Duration {
+	return cycle.Config.TableSizeTimeoutDuration()
+}
+
+func (c *tableSizeCollector) Collect(ctx context.Context, cycle Cycle) ([]Sample, error) {
+	return c.run(func() ([]Sample, error) { return c.collect(ctx, cycle) })
+}
+
+func (c *tableSizeCollector) collect(ctx context.Context, cycle Cycle) ([]Sample, error) {
+	var samples []Sample
+
+	tables, err := c.gatherer.TableSizes(ctx, cycle.Config.TableSizeFileSizes)
+
+	tables = tablesize.Filter(tables, cycle.Config.TableSizeIncludeSchemas, cycle.Config.TableSizeExcludedSchemas())
+	for _, schema := range tablesize.Summarize(tables) {
+		values := schema.Values()
+		samples = append(samples, Sample{Values: values, Tags: map[string]string{"schema": values["schema"]}, Mappings: c.schemaMappings})
+	}
+	for _, table := range tablesize.Largest(tables, cycle.Config.TableSizeLimit()) {
+		values := table.Values()
+		samples = append(samples, Sample{Values: values, Tags: map[string]string{"schema": values["schema"], "table": values["table"]}})
+	}
+
+	return samples, err
+}
+
+// customQueryCollector emits the value columns of every row of a custom query,
+// tagged with its label columns.
+type customQueryCollector struct {
+	builtin
+	schedule
+	query    config.CustomQuery
+	gatherer Gatherer
+}
+
+func (c *customQueryCollector) Enabled(cycle Cycle) bool {
+	defaultInterval := time.Duration(cycle.Config.MetricsFrequency) * time.Second
+	return c.builtin.Enabled(cycle) && c.due(c.query.IntervalDuration(defaultInterval))
+}
+
+func (c *customQueryCollector) Timeout(Cycle) time.Duration {
+	return c.query.TimeoutDuration()
+}
+
+func (c *customQueryCollector) Mappings() map[string]MetricDefinition {
+	mappings := make(map[string]MetricDefinition, len(c.query.ValueColumns))
+	for _, column := range c.query.ValueColumns {
+		key := column.Key
+		if key == "" {
+			key = "custom/" + c.query.Name + "/" + strings.ToLower(column.Column)
+		}
+
+		mappings[strings.ToLower(column.Column)] = MetricDefinition{Key: key, Unit: column.Unit}
+	}
+
+	return mappings
+}
+
+func (c *customQueryCollector) Collect(ctx context.Context, _ Cycle) ([]Sample, error) {
+	return c.run(func() ([]Sample, error) { return c.collect(ctx) })
+}
+
+func (c *customQueryCollector) collect(ctx context.Context) ([]Sample, error) {
+	rows, err := c.gatherer.CustomQuery(ctx, c.query)
+	if err != nil {
+		return nil, fmt.Errorf("custom query %s: %w", c.query.Name, err)
+	}
+
+	var samples []Sample
+	for _, row := range rows {
+		tags := make(map[string]string, len(c.query.LabelColumns))
+		for _, column := range c.query.LabelColumns {
+			tags[strings.ToLower(column)] = row[strings.ToLower(column)]
+		}
+
+		samples = append(samples, Sample{Values: row, Tags: tags})
+	}
+
+	return samples, nil
+}
+
+func boolValue(b bool) string {
+	if b {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,653 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/digest"
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/metrics/metricsfakes"
+	"github.com/cloudfoundry/mysql-metrics/metrics_computer"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
+)
+
+var _ = Describe("NewDefaultRegistry", func() {
+	var (
+		registry            *metrics.Registry
+		fakeGatherer        *metricsfakes.FakeGatherer
+		metricMappingConfig *metrics.MetricMappingConfig
+		configuration       *config.Config
+		cycle               metrics.Cycle
+		ctx                 context.Context
+	)
+
+	collector := func(name string) metrics.Collector {
+		for _, c := range registry.Collectors() {
+			if c.Name() == name {
+				return c
+			}
+		}
+		Fail("no collector named " + name)
+		return nil
+	}
+
+	enabled := func() []string {
+		var names []string
+		for _, c := range registry.Collectors() {
+			if c.Enabled(cycle) {
+				names = append(names, c.Name())
+			}
+		}
+		return names
+	}
+
+	BeforeEach(func() {
+		fakeGatherer = &metricsfakes.FakeGatherer{}
+		metricMappingConfig = metrics.DefaultMetricMappingConfig()
+		configuration = &config.Config{}
+		cycle = metrics.Cycle{Config: configuration, DatabaseAvailable: true}
+		ctx = context.Background()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		registry, err = metrics.NewDefaultRegistry(fakeGatherer, metricMappingConfig, configuration)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("registers the built-in collectors in order", func() {
+		var names []string
+		for _, c := range registry.Collectors() {
+			names = append(names, c.Name())
+		}
+
+		Expect(names).To(Equal([]string{
+			"disk_usage", "disk_performance", "broker", "cpu", "memory", "backup", "mysql",
+			"leader_follower", "transactions", "innodb_status", "digest", "table_size",
+		}))
+		Expect(names).To(Equal(configuration.CollectorNames()))
+	})
+
+	defaults := metrics.DefaultMetricMappingConfig()
+
+	DescribeTable("declares the mapping table of every collector",
+		func(name string, mappings map[string]metrics.MetricDefinition) {
+			Expect(collector(name).Mappings()).To(Equal(mappings))
+		},
+		Entry(nil, "disk_usage", defaults.DiskUsageMetricMappings),
+		Entry(nil, "disk_performance", defaults.DiskPerformanceMetricMappings),
+		Entry(nil, "broker", defaults.BrokerMetricMappings),
+		Entry(nil, "cpu", defaults.CPUMetricMappings),
+		Entry(nil, "memory", defaults.MemoryMetricMappings),
+		Entry(nil, "backup", defaults.BackupMetricMappings),
+		Entry(nil, "mysql", defaults.MysqlMetricMappings),
+		Entry(nil, "leader_follower", defaults.LeaderFollowerMetricMappings),
+		Entry(nil, "transactions", defaults.TransactionMetricMappings),
+		Entry(nil, "innodb_status", defaults.InnoDBStatusMetricMappings),
+		Entry(nil, "digest", defaults.DigestMetricMappings),
+		Entry(nil, "table_size", defaults.TableSizeMetricMappings),
+	)
+
+	DescribeTable("collects the values that were read when the gatherer returns an error",
+		func(name string, fail func(err error)) {
+			fail(errors.New("partial read"))
+
+			samples, err := collector(name).Collect(ctx, cycle)
+			Expect(err).To(MatchError("partial read"))
+			Expect(samples).NotTo(BeEmpty())
+			Expect(samples[0].Values).NotTo(BeEmpty())
+		},
+		Entry(nil, "innodb_status", func(err error) {
+			fakeGatherer.InnoDBStatusReturns(map[string]string{"history_list_length": "42"}, err)
+		}),
+		Entry(nil, "cpu", func(err error) {
+			fakeGatherer.CPUStatsReturns(map[string]string{"load_average_1m": "1.52"}, nil, err)
+		}),
+		Entry(nil, "memory", func(err error) {
+			fakeGatherer.MemoryStatsReturns(map[string]string{"memory_available": "2000000"}, err)
+		}),
+		Entry(nil, "transactions", func(err error) {
+			fakeGatherer.TransactionStatsReturns(map[string]string{"oldest_transaction_age": "7200"}, err)
+		}),
+		Entry(nil, "table_size", func(err error) {
+			fakeGatherer.TableSizesReturns([]tablesize.Table{{Schema: "app", Name: "users", DataLength: 1000}}, err)
+		}),
+	)
+
+	// itRunsOnItsSchedule describes a collector that runs every interval
+	// rather than every cycle, given a way to make its gatherer fail.
+	itRunsOnItsSchedule := func(name string, fail func()) {
+		It("is not enabled again before its interval has elapsed", func() {
+			Expect(collector(name).Enabled(cycle)).To(BeTrue())
+			_, err := collector(name).Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(collector(name).Enabled(cycle)).To(BeFalse())
+		})
+
+		It("is enabled again in the next cycle when it fails", func() {
+			fail()
+
+			_, err := collector(name).Collect(ctx, cycle)
+			Expect(err).To(HaveOccurred())
+			Expect(collector(name).Enabled(cycle)).To(BeTrue())
+		})
+	}
+
+	Describe("enablement", func() {
+		It("enables no collector by default", func() {
+			Expect(enabled()).To(BeEmpty())
+		})
+
+		It("enables the collectors of every emit flag", func() {
+			configuration.EmitDiskMetrics = true
+			configuration.EmitBrokerMetrics = true
+			configuration.EmitCPUMetrics = true
+			configuration.EmitMemoryMetrics = true
+			configuration.EmitBackupMetrics = true
+			configuration.EmitMysqlMetrics = true
+			configuration.EmitLeaderFollowerMetrics = true
+			configuration.EmitTransactionMetrics = true
+			configuration.EmitInnoDBStatusMetrics = true
+			configuration.EmitDigestMetrics = true
+			configuration.EmitTableSizeMetrics = true
+
+			Expect(enabled()).To(HaveLen(12))
+		})
+
+		It("enables and disables collectors by name", func() {
+			configuration.EmitCPUMetrics = true
+			configuration.EmitDiskMetrics = true
+			configuration.Collectors = map[string]bool{"cpu": false, "disk_performance": false, "broker": true}
+
+			Expect(enabled()).To(Equal([]string{"disk_usage", "broker"}))
+		})
+
+		It("only enables the collectors that need the database while it is available", func() {
+			configuration.EmitMysqlMetrics = true
+			configuration.EmitLeaderFollowerMetrics = true
+			configuration.EmitTransactionMetrics = true
+			configuration.EmitInnoDBStatusMetrics = true
+			configuration.EmitDigestMetrics = true
+			configuration.EmitTableSizeMetrics = true
+			cycle.DatabaseAvailable = false
+
+			Expect(enabled()).To(Equal([]string{"mysql", "leader_follower"}))
+		})
+	})
+
+	Describe("mapped collectors", func() {
+		It("collects the gathered values", func() {
+			fakeGatherer.DiskStatsReturns(map[string]string{"persistent_disk_used": "1024"}, nil)
+			fakeGatherer.DiskPerformanceStatsReturns(map[string]string{"persistent_disk_read_iops": "2250.00"}, nil)
+			fakeGatherer.BrokerStatsReturns(map[string]string{"service_plans_disk_allocated": "200"}, nil)
+			fakeGatherer.InnoDBStatusReturns(map[string]string{"history_list_length": "42"}, nil)
+
+			for name, values := range map[string]map[string]string{
+				"disk_usage":       {"persistent_disk_used": "1024"},
+				"disk_performance": {"persistent_disk_read_iops": "2250.00"},
+				"broker":           {"service_plans_disk_allocated": "200"},
+				"innodb_status":    {"history_list_length": "42"},
+			} {
+				samples, err := collector(name).Collect(ctx, cycle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(samples).To(Equal([]metrics.Sample{{Values: values}}), name)
+			}
+		})
+
+		It("queries the database with the given context", func() {
+			type key struct{}
+			ctx = context.WithValue(ctx, key{}, "cycle")
+
+			_, err := collector("broker").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeGatherer.BrokerStatsArgsForCall(0).Value(key{})).To(Equal("cycle"))
+		})
+	})
+
+	Describe("cpu", func() {
+		BeforeEach(func() {
+			fakeGatherer.CPUStatsReturns(
+				map[string]string{"cpu_utilization_percent": "71", "cpu_steal_percent": "4.20"},
+				[]map[string]string{
+					{"core": "0", "utilization_percent": "80.00"},
+					{"core": "1", "utilization_percent": "62.00"},
+				},
+				nil,
+			)
+		})
+
+		It("collects the cpu usage of all cores", func() {
+			samples, err := collector("cpu").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{
+				{Values: map[string]string{"cpu_utilization_percent": "71", "cpu_steal_percent": "4.20"}},
+			}))
+		})
+
+		It("collects the cpu usage of every core, tagged with its number, when enabled", func() {
+			configuration.EmitCPUCoreMetrics = true
+
+			samples, err := collector("cpu").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{
+				{Values: map[string]string{"cpu_utilization_percent": "71", "cpu_steal_percent": "4.20"}},
+				{
+					Values:   map[string]string{"core": "0", "utilization_percent": "80.00"},
+					Tags:     map[string]string{"core": "0"},
+					Mappings: metricMappingConfig.CPUCoreMetricMappings,
+				},
+				{
+					Values:   map[string]string{"core": "1", "utilization_percent": "62.00"},
+					Tags:     map[string]string{"core": "1"},
+					Mappings: metricMappingConfig.CPUCoreMetricMappings,
+				},
+			}))
+		})
+	})
+
+	Describe("memory", func() {
+		It("collects the memory usage with the configured pid file", func() {
+			configuration.MysqldPidFile = "/var/vcap/sys/run/pxc-mysql/mysqld.pid"
+			fakeGatherer.MemoryStatsReturns(map[string]string{"mysqld_rss": "5000000"}, nil)
+
+			samples, err := collector("memory").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{{Values: map[string]string{"mysqld_rss": "5000000"}}}))
+
+			_, pidFile, databaseAvailable := fakeGatherer.MemoryStatsArgsForCall(0)
+			Expect(pidFile).To(Equal("/var/vcap/sys/run/pxc-mysql/mysqld.pid"))
+			Expect(databaseAvailable).To(BeTrue())
+		})
+
+		It("collects the memory usage of the host while the database is unavailable", func() {
+			cycle.DatabaseAvailable = false
+			fakeGatherer.MemoryStatsReturns(map[string]string{"memory_available": "2000000"}, nil)
+
+			samples, err := collector("memory").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{{Values: map[string]string{"memory_available": "2000000"}}}))
+
+			_, _, databaseAvailable := fakeGatherer.MemoryStatsArgsForCall(0)
+			Expect(databaseAvailable).To(BeFalse())
+		})
+	})
+
+	Describe("backup", func() {
+		It("collects the time of the last backup in seconds", func() {
+			fakeGatherer.FindLastBackupTimestampReturns(time.Unix(1700000000, 0), nil)
+
+			samples, err := collector("backup").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{{Values: map[string]string{"last_successful_backup": "1700000000"}}}))
+		})
+
+		It("collects nothing when the gatherer returns an error", func() {
+			fakeGatherer.FindLastBackupTimestampReturns(time.Time{}, errors.New("some-error"))
+
+			samples, err := collector("backup").Collect(ctx, cycle)
+			Expect(err).To(MatchError("some-error"))
+			Expect(samples).To(BeEmpty())
+		})
+	})
+
+	Describe("mysql", func() {
+		BeforeEach(func() {
+			fakeGatherer.DatabaseMetadataReturns(map[string]string{"wsrep_cluster_status": "Primary"}, map[string]string{"wsrep_on": "ON"}, nil)
+		})
+
+		It("collects the availability, global status and variables", func() {
+			samples, err := collector("mysql").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{
+				{Values: map[string]string{"available": "1"}},
+				{Values: map[string]string{"wsrep_cluster_status": "Primary"}},
+				{Values: map[string]string{"wsrep_on": "ON"}},
+			}))
+		})
+
+		It("also collects them with the galera mappings when galera metrics are enabled", func() {
+			configuration.EmitGaleraMetrics = true
+
+			samples, err := collector("mysql").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(5))
+			Expect(samples[3]).To(Equal(metrics.Sample{
+				Values:   map[string]string{"wsrep_cluster_status": "Primary"},
+				Mappings: metricMappingConfig.GaleraMetricMappings,
+			}))
+			Expect(samples[4]).To(Equal(metrics.Sample{
+				Values:   map[string]string{"wsrep_on": "ON"},
+				Mappings: metricMappingConfig.GaleraMetricMappings,
+			}))
+		})
+
+		It("only collects the availability while the database is unavailable", func() {
+			cycle.DatabaseAvailable = false
+
+			samples, err := collector("mysql").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{{Values: map[string]string{"available": "0"}}}))
+			Expect(fakeGatherer.DatabaseMetadataCallCount()).To(Equal(0))
+		})
+
+		It("returns the gatherer's error", func() {
+			fakeGatherer.DatabaseMetadataReturns(nil, nil, errors.New("DatabaseMetadata failed"))
+
+			samples, err := collector("mysql").Collect(ctx, cycle)
+			Expect(err).To(MatchError("DatabaseMetadata failed"))
+			Expect(samples[0]).To(Equal(metrics.Sample{Values: map[string]string{"available": "1"}}))
+		})
+	})
+
+	Describe("leader_follower", func() {
+		It("collects the follower, heartbeat and replica status of a follower", func() {
+			fakeGatherer.IsDatabaseFollowerReturns(true, nil)
+			fakeGatherer.FollowerMetadataReturns(map[string]string{"slave_io_running": "Yes"}, map[string]string{"seconds_since_leader_heartbeat": "1"}, nil)
+
+			samples, err := collector("leader_follower").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{
+				{Values: map[string]string{"is_follower": "1"}},
+				{Values: map[string]string{"seconds_since_leader_heartbeat": "1"}},
+				{Values: map[string]string{"slave_io_running": "Yes"}},
+			}))
+		})
+
+		It("only collects whether a leader is a follower", func() {
+			samples, err := collector("leader_follower").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{{Values: map[string]string{"is_follower": "0"}}}))
+			Expect(fakeGatherer.FollowerMetadataCallCount()).To(Equal(0))
+		})
+
+		It("collects errors but collects whatever it can", func() {
+			fakeGatherer.IsDatabaseFollowerReturns(true, errors.New("failed to determine follower state"))
+			fakeGatherer.FollowerMetadataReturns(nil, nil, errors.New("FollowerMetadata failed"))
+
+			samples, err := collector("leader_follower").Collect(ctx, cycle)
+			Expect(err).To(MatchError(ContainSubstring("failed to determine follower state")))
+			Expect(err).To(MatchError(ContainSubstring("FollowerMetadata failed")))
+			Expect(samples[0]).To(Equal(metrics.Sample{Values: map[string]string{"is_follower": "1"}}))
+		})
+	})
+
+	Describe("transactions", func() {
+		BeforeEach(func() {
+			configuration.TransactionAgeThresholds = []int{30}
+			fakeGatherer.TransactionStatsReturns(map[string]string{
+				"oldest_transaction_age":     "7200",
+				"transactions_older_than_30": "2",
+			}, nil)
+		})
+
+		It("collects the stats of the configured thresholds with a mapping for every threshold", func() {
+			samples, err := collector("transactions").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, thresholds := fakeGatherer.TransactionStatsArgsForCall(0)
+			Expect(thresholds).To(Equal([]int{30}))
+
+			Expect(samples).To(HaveLen(1))
+			Expect(samples[0].Values).To(HaveKeyWithValue("transactions_older_than_30", "2"))
+			Expect(samples[0].Mappings).To(HaveKeyWithValue("transactions_older_than_30", metrics.MetricDefinition{
+				Key:  metricMappingConfig.TransactionMetricMappings["transactions_older_than"].Key + "_30",
+				Unit: metricMappingConfig.TransactionMetricMappings["transactions_older_than"].Unit,
+			}))
+			Expect(samples[0].Mappings).To(HaveKey("oldest_transaction_age"))
+			Expect(metricMappingConfig.TransactionMetricMappings).NotTo(HaveKey("transactions_older_than_30"))
+		})
+	})
+
+	Describe("digest", func() {
+		var summary digest.Summary
+
+		BeforeEach(func() {
+			configuration.DigestTopN = 1
+
+			summary = digest.Summary{
+				Elapsed: 30 * time.Second,
+				Digests: []digest.Delta{
+					{Schema: "app", Digest: "slow", Calls: 1, TimerWait: 9000},
+					{Schema: "app", Digest: "frequent", Calls: 500, TimerWait: 500},
+					{Schema: "app", Digest: "boring", Calls: 2, TimerWait: 5},
+				},
+			}
+			fakeGatherer.StatementDigestsReturns(summary, nil)
+		})
+
+		It("collects the top digests tagged with their schema and digest", func() {
+			samples, err := collector("digest").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(Equal([]metrics.Sample{
+				{Values: summary.Digests[0].Values(), Tags: map[string]string{"schema": "app", "digest": "slow"}},
+				{Values: summary.Digests[1].Values(), Tags: map[string]string{"schema": "app", "digest": "frequent"}},
+			}))
+		})
+
+		It("emits the same metric names for every digest", func() {
+			configuration.DigestTopN = 3
+
+			samples, err := collector("digest").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+
+			computer := metrics_computer.NewMetricsComputer()
+			for _, sample := range samples {
+				var keys []string
+				for _, metric := range computer.ComputeMetricsFromMapping(sample.Values, collector("digest").Mappings()) {
+					keys = append(keys, metric.Key)
+				}
+				Expect(keys).To(ConsistOf("digest/calls", "digest/latency", "digest/avg_latency", "digest/rows_examined", "digest/errors"))
+			}
+		})
+
+		It("returns the gatherer's error", func() {
+			fakeGatherer.StatementDigestsReturns(digest.Summary{}, errors.New("performance_schema disabled"))
+
+			samples, err := collector("digest").Collect(ctx, cycle)
+			Expect(err).To(MatchError("performance_schema disabled"))
+			Expect(samples).To(BeEmpty())
+		})
+
+		Context("when a digest report path is configured", func() {
+			var reportPath string
+
+			BeforeEach(func() {
+				reportPath = filepath.Join(GinkgoT().TempDir(), "digests.json")
+				configuration.DigestReportPath = reportPath
+			})
+
+			It("writes the digest report", func() {
+				_, err := collector("digest").Collect(ctx, cycle)
+				Expect(err).NotTo(HaveOccurred())
+
+				contents, err := os.ReadFile(reportPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(contents)).To(ContainSubstring(`"digest": "slow"`))
+			})
+
+			It("does not write a report before there is a delta", func() {
+				fakeGatherer.StatementDigestsReturns(digest.Summary{}, nil)
+
+				_, err := collector("digest").Collect(ctx, cycle)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(reportPath).NotTo(BeAnExistingFile())
+			})
+
+			Context("when the report cannot be written", func() {
+				BeforeEach(func() {
+					configuration.DigestReportPath = "/path/does/not/exist/digests.json"
+				})
+
+				It("reports an error", func() {
+					samples, err := collector("digest").Collect(ctx, cycle)
+					Expect(err).To(MatchError(ContainSubstring("failed to write statement digest report")))
+					Expect(samples).To(HaveLen(2))
+				})
+			})
+		})
+	})
+
+	Describe("table_size", func() {
+		BeforeEach(func() {
+			configuration.EmitTableSizeMetrics = true
+			configuration.TableSizeTopN = 1
+			configuration.TableSizeFileSizes = true
+
+			fakeGatherer.TableSizesReturns([]tablesize.Table{
+				{Schema: "app", Name: "users", DataLength: 1000},
+				{Schema: "app", Name: "events", DataLength: 2000},
+				{Schema: "mysql", Name: "user", DataLength: 16384},
+			}, nil)
+		})
+
+		It("collects every schema and the largest tables, leaving out system schemas", func() {
+			samples, err := collector("table_size").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, includeFileSizes := fakeGatherer.TableSizesArgsForCall(0)
+			Expect(includeFileSizes).To(BeTrue())
+
+			Expect(samples).To(HaveLen(2))
+			Expect(samples[0].Values).To(HaveKeyWithValue("data_length", "3000"))
+			Expect(samples[0].Tags).To(Equal(map[string]string{"schema": "app"}))
+			Expect(samples[0].Mappings).To(Equal(metricMappingConfig.SchemaSizeMetricMappings))
+			Expect(samples[1].Values).To(HaveKeyWithValue("table", "events"))
+			Expect(samples[1].Tags).To(Equal(map[string]string{"schema": "app", "table": "events"}))
+			Expect(samples[1].Mappings).To(BeNil())
+		})
+
+		It("only includes the configured schemas", func() {
+			configuration.TableSizeIncludeSchemas = []string{"my*"}
+			configuration.TableSizeExcludeSchemas = []string{}
+
+			samples, err := collector("table_size").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples[0].Tags).To(Equal(map[string]string{"schema": "mysql"}))
+		})
+
+		itRunsOnItsSchedule("table_size", func() {
+			fakeGatherer.TableSizesReturns(nil, context.DeadlineExceeded)
+		})
+
+		It("is not enabled while it is running", func() {
+			release := make(chan struct{})
+			fakeGatherer.TableSizesStub = func(context.Context, bool) ([]tablesize.Table, error) {
+				<-release
+				return nil, nil
+			}
+
+			go func() { _, _ = collector("table_size").Collect(ctx, cycle) }()
+			Eventually(fakeGatherer.TableSizesCallCount).Should(Equal(1))
+			Expect(collector("table_size").Enabled(cycle)).To(BeFalse())
+
+			close(release)
+		})
+
+		It("uses the table size timeout", func() {
+			configuration.TableSizeTimeout = 120
+
+			timeoutCollector, ok := collector("table_size").(metrics.TimeoutCollector)
+			Expect(ok).To(BeTrue())
+			Expect(timeoutCollector.Timeout(cycle)).To(Equal(2 * time.Minute))
+		})
+	})
+
+	Describe("custom queries", func() {
+		BeforeEach(func() {
+			configuration.MetricsFrequency = 30
+			configuration.CustomQueries = []config.CustomQuery{
+				{
+					Name:         "queue_depth",
+					Query:        "SELECT queue, COUNT(*) AS pending FROM app.jobs GROUP BY queue",
+					Timeout:      5,
+					ValueColumns: []config.CustomQueryColumn{{Column: "pending", Key: "app/queue_pending", Unit: "number"}},
+					LabelColumns: []string{"queue"},
+				},
+				{
+					Name:         "oldest_job",
+					Query:        "SELECT MAX(age) AS age FROM app.jobs",
+					ValueColumns: []config.CustomQueryColumn{{Column: "Age", Unit: "second"}},
+				},
+			}
+
+			fakeGatherer.CustomQueryReturns([]map[string]string{
+				{"queue": "emails", "pending": "12"},
+				{"queue": "sms/text", "pending": "3"},
+			}, nil)
+		})
+
+		It("registers a collector for every query", func() {
+			var names []string
+			for _, c := range registry.Collectors() {
+				names = append(names, c.Name())
+			}
+			Expect(names).To(Equal(configuration.CollectorNames()))
+
+			Expect(collector("custom_query/queue_depth").Mappings()).To(Equal(map[string]metrics.MetricDefinition{
+				"pending": {Key: "app/queue_pending", Unit: "number"},
+			}))
+			Expect(collector("custom_query/oldest_job").Mappings()).To(Equal(map[string]metrics.MetricDefinition{
+				"age": {Key: "custom/oldest_job/age", Unit: "second"},
+			}))
+		})
+
+		It("collects every row tagged with its label columns", func() {
+			samples, err := collector("custom_query/queue_depth").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, query := fakeGatherer.CustomQueryArgsForCall(0)
+			Expect(query.Name).To(Equal("queue_depth"))
+			Expect(samples).To(Equal([]metrics.Sample{
+				{Values: map[string]string{"queue": "emails", "pending": "12"}, Tags: map[string]string{"queue": "emails"}},
+				{Values: map[string]string{"queue": "sms/text", "pending": "3"}, Tags: map[string]string{"queue": "sms/text"}},
+			}))
+		})
+
+		It("emits the same metric name for every row", func() {
+			samples, err := collector("custom_query/queue_depth").Collect(ctx, cycle)
+			Expect(err).NotTo(HaveOccurred())
+
+			computer := metrics_computer.NewMetricsComputer()
+			for _, sample := range samples {
+				computed := computer.ComputeMetricsFromMapping(sample.Values, collector("custom_query/queue_depth").Mappings())
+				Expect(computed).To(HaveLen(1))
+				Expect(computed[0].Key).To(Equal("app/queue_pending"))
+			}
+		})
+
+		It("names the query in its error", func() {
+			fakeGatherer.CustomQueryReturns(nil, errors.New("table app.jobs doesn't exist"))
+
+			_, err := collector("custom_query/queue_depth").Collect(ctx, cycle)
+			Expect(err).To(MatchError("custom query queue_depth: table app.jobs doesn't exist"))
+		})
+
+		It("uses the timeout of the query", func() {
+			timeoutCollector, ok := collector("custom_query/queue_depth").(metrics.TimeoutCollector)
+			Expect(ok).To(BeTrue())
+			Expect(timeoutCollector.Timeout(cycle)).To(Equal(5 * time.Second))
+		})
+
+		itRunsOnItsSchedule("custom_query/queue_depth", func() {
+			fakeGatherer.CustomQueryReturns(nil, errors.New("table app.jobs doesn't exist"))
+		})
+
+		Context("with a short interval", func() {
+			BeforeEach(func() {
+				configuration.MetricsFrequency = 0
+			})
+
+			It("is enabled every cycle", func() {
+				Expect(collector("custom_query/queue_depth").Enabled(cycle)).To(BeTrue())
+				Expect(collector("custom_query/queue_depth").Enabled(cycle)).To(BeTrue())
+			})
+		})
+	})
+})
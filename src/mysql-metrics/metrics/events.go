@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// Event marks a change in the value of a state metric, such as availability
+// or the Galera cluster status, between two cycles.
+type Event struct {
+	Key      string            `json:"key"`
+	Title    string            `json:"title"`
+	OldValue float64           `json:"old_value"`
+	NewValue float64           `json:"new_value"`
+	OldState string            `json:"old_state"`
+	NewState string            `json:"new_state"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// EventSender is implemented by senders that can emit events in addition to
+// values.
+type EventSender interface {
+	SendEvent(title, body string, tags map[string]string) error
+}
+
+// EventWriter is implemented by writers that can emit events.
+type EventWriter interface {
+	WriteEvents(events []*Event) error
+}
+
+// stateMetric describes the values of a metric whose changes are emitted as
+// events. The title of an event is the one for the state that was left when
+// there is one, and otherwise the one for the state that was entered.
+type stateMetric struct {
+	states  map[float64]string
+	left    map[float64]string
+	entered map[float64]string
+}
+
+// stateMetrics are the metrics whose changes are emitted as events, by key.
+var stateMetrics = map[string]stateMetric{
+	"available": {
+		entered: map[float64]string{1: "node became available", 0: "node became unavailable"},
+	},
+	"variables/read_only": {
+		entered: map[float64]string{1: "node became read only", 0: "node became writable"},
+	},
+	"galera/wsrep_ready": {
+		entered: map[float64]string{1: "node became ready for queries", 0: "node stopped accepting queries"},
+	},
+	"galera/wsrep_cluster_status": {
+		states:  map[float64]string{1: "PRIMARY", 0: "NON_PRIMARY", -1: "DISCONNECTED"},
+		left:    map[float64]string{1: "node left Primary component"},
+		entered: map[float64]string{1: "node joined Primary component", 0: "node is in a non-Primary component", -1: "node disconnected from the cluster"},
+	},
+	"galera/wsrep_local_state": {
+		states:  map[float64]string{1: "JOINING", 2: "DONOR/DESYNCED", 3: "JOINED", 4: "SYNCED"},
+		left:    map[float64]string{4: "node is no longer synced with the cluster"},
+		entered: map[float64]string{4: "node synced with the cluster", 1: "node is joining the cluster", 2: "node became a donor", 3: "node joined the cluster"},
+	},
+	"follower/is_follower": {
+		entered: map[float64]string{1: "node became a follower", 0: "node stopped being a follower"},
+	},
+	"follower/slave_io_running": {
+		entered: map[float64]string{1: "replica IO thread started", 0: "replica IO thread stopped"},
+	},
+	"follower/slave_sql_running": {
+		entered: map[float64]string{1: "replica SQL thread started", 0: "replica SQL thread stopped"},
+	},
+}
+
+func (m stateMetric) title(key string, oldValue, newValue float64) string {
+	if title, ok := m.left[oldValue]; ok {
+		return title
+	}
+	if title, ok := m.entered[newValue]; ok {
+		return title
+	}
+
+	return fmt.Sprintf("%s changed", key)
+}
+
+func (m stateMetric) state(value float64) string {
+	if state, ok := m.states[value]; ok {
+		return state
+	}
+
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// stateTracker keeps the last value of every state metric to detect when it
+// changes. Metrics that were not emitted, or had an error, in a cycle keep
+// the value they had before.
+type stateTracker struct {
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newStateTracker() *stateTracker {
+	return &stateTracker{values: make(map[string]float64)}
+}
+
+// changes records the values of the state metrics among metrics, returning
+// an event for each one that differs from its previous value.
+func (t *stateTracker) changes(metrics []*Metric) []*Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var events []*Event
+	for _, metric := range metrics {
+		stateMetric, ok := stateMetrics[metric.Key]
+		if !ok || metric.Error != nil {
+			continue
+		}
+
+		previous, seen := t.values[metric.Key]
+		t.values[metric.Key] = metric.Value
+		if !seen || previous == metric.Value {
+			continue
+		}
+
+		events = append(events, &Event{
+			Key:      metric.Key,
+			Title:    stateMetric.title(metric.Key, previous, metric.Value),
+			OldValue: previous,
+			NewValue: metric.Value,
+			OldState: stateMetric.state(previous),
+			NewState: stateMetric.state(metric.Value),
+			Tags:     metric.Tags,
+		})
+	}
+
+	return events
+}
@@ -0,0 +1,212 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/v9/rpc/loggregator_v2"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	loggregatorSendTimeout = 10 * time.Second
+
+	// DefaultLoggregatorEnvelopeSize is the encoded size, in bytes, that the
+	// envelopes of a LoggregatorBatchSender are kept under unless configured
+	// otherwise.
+	DefaultLoggregatorEnvelopeSize = 16 * 1024
+
+	// loggregatorBatchSize bounds the encoded size of the envelopes sent in
+	// one request, well under the default gRPC message size limit.
+	loggregatorBatchSize = 1024 * 1024
+)
+
+// FlushError is returned by senders that could not send some of the values
+// of a flush.
+type FlushError struct {
+	Failed int
+	Total  int
+	Err    error
+}
+
+func (e *FlushError) Error() string {
+	return fmt.Sprintf("failed to send %d of %d values: %s", e.Failed, e.Total, e.Err)
+}
+
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+type loggregatorValue struct {
+	name  string
+	value float64
+	unit  string
+	tags  map[string]string
+}
+
+// LoggregatorBatchSender collects the values sent during a cycle and sends
+// them to the loggregator agent when flushed, as gauge envelopes that hold
+// every value with the same tags, up to a maximum envelope size.
+type LoggregatorBatchSender struct {
+	client       loggregator_v2.IngressClient
+	sourceID     string
+	instanceID   string
+	tags         map[string]string
+	envelopeSize int
+	now          func() time.Time
+
+	mu      sync.Mutex
+	pending []loggregatorValue
+}
+
+// NewLoggregatorBatchSender creates a LoggregatorBatchSender whose envelopes
+// carry tags in addition to the tags of their values, and are kept under
+// envelopeSize bytes unless a single value does not fit.
+func NewLoggregatorBatchSender(client loggregator_v2.IngressClient, sourceID, instanceID string, tags map[string]string, envelopeSize int) *LoggregatorBatchSender {
+	if envelopeSize <= 0 {
+		envelopeSize = DefaultLoggregatorEnvelopeSize
+	}
+
+	return &LoggregatorBatchSender{
+		client:       client,
+		sourceID:     sourceID,
+		instanceID:   instanceID,
+		tags:         tags,
+		envelopeSize: envelopeSize,
+		now:          time.Now,
+	}
+}
+
+func (sender *LoggregatorBatchSender) SendValue(name string, value float64, unit string, tags map[string]string) error {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.pending = append(sender.pending, loggregatorValue{name: name, value: value, unit: unit, tags: tags})
+	return nil
+}
+
+// Flush sends every value sent since the last flush. Values whose request
+// fails are counted in the returned *FlushError and dropped rather than being
+// retried next cycle.
+func (sender *LoggregatorBatchSender) Flush() error {
+	sender.mu.Lock()
+	pending := sender.pending
+	sender.pending = nil
+	sender.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	envelopes, counts := sender.envelopes(pending)
+
+	ctx, cancel := context.WithTimeout(context.Background(), loggregatorSendTimeout)
+	defer cancel()
+
+	failed := 0
+	var lastErr error
+	for start := 0; start < len(envelopes); {
+		end, size := start, 0
+		for end < len(envelopes) && (end == start || size+proto.Size(envelopes[end]) <= loggregatorBatchSize) {
+			size += proto.Size(envelopes[end])
+			end++
+		}
+
+		if _, err := sender.client.Send(ctx, &loggregator_v2.EnvelopeBatch{Batch: envelopes[start:end]}); err != nil {
+			for _, count := range counts[start:end] {
+				failed += count
+			}
+			lastErr = err
+		}
+		start = end
+	}
+
+	if failed > 0 {
+		return &FlushError{Failed: failed, Total: len(pending), Err: lastErr}
+	}
+
+	return nil
+}
+
+// SendEvent sends an event envelope right away rather than with the values of
+// the next flush.
+func (sender *LoggregatorBatchSender) SendEvent(title, body string, tags map[string]string) error {
+	envelope := &loggregator_v2.Envelope{
+		Timestamp:  sender.now().UnixNano(),
+		SourceId:   sender.sourceID,
+		InstanceId: sender.instanceID,
+		Tags:       make(map[string]string, len(sender.tags)+len(tags)),
+		Message: &loggregator_v2.Envelope_Event{
+			Event: &loggregator_v2.Event{Title: title, Body: body},
+		},
+	}
+	maps.Copy(envelope.Tags, sender.tags)
+	maps.Copy(envelope.Tags, tags)
+
+	ctx, cancel := context.WithTimeout(context.Background(), loggregatorSendTimeout)
+	defer cancel()
+
+	_, err := sender.client.Send(ctx, &loggregator_v2.EnvelopeBatch{Batch: []*loggregator_v2.Envelope{envelope}})
+	return err
+}
+
+// envelopes groups values by their tags into gauge envelopes, returning the
+// envelopes along with the number of values each holds.
+func (sender *LoggregatorBatchSender) envelopes(values []loggregatorValue) ([]*loggregator_v2.Envelope, []int) {
+	var envelopes []*loggregator_v2.Envelope
+	var counts []int
+
+	open := make(map[string]int)
+	timestamp := sender.now().UnixNano()
+
+	for _, value := range values {
+		key := tagsKey(value.tags)
+		gaugeValue := &loggregator_v2.GaugeValue{Unit: value.unit, Value: value.value}
+
+		if i, ok := open[key]; ok {
+			metrics := envelopes[i].GetGauge().Metrics
+			if _, duplicate := metrics[value.name]; !duplicate {
+				metrics[value.name] = gaugeValue
+				if proto.Size(envelopes[i]) <= sender.envelopeSize {
+					counts[i]++
+					continue
+				}
+				delete(metrics, value.name)
+			}
+		}
+
+		envelope := &loggregator_v2.Envelope{
+			Timestamp:  timestamp,
+			SourceId:   sender.sourceID,
+			InstanceId: sender.instanceID,
+			Tags:       make(map[string]string, len(sender.tags)+len(value.tags)),
+			Message: &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{
+					Metrics: map[string]*loggregator_v2.GaugeValue{value.name: gaugeValue},
+				},
+			},
+		}
+		maps.Copy(envelope.Tags, sender.tags)
+		maps.Copy(envelope.Tags, value.tags)
+
+		open[key] = len(envelopes)
+		envelopes = append(envelopes, envelope)
+		counts = append(counts, 1)
+	}
+
+	return envelopes, counts
+}
+
+func tagsKey(tags map[string]string) string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(tags)) {
+		fmt.Fprintf(&b, "%q=%q,", name, tags[name])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,181 @@
+package metrics_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"sync"
+
+	"code.cloudfoundry.org/go-loggregator/v9"
+	"code.cloudfoundry.org/go-loggregator/v9/rpc/loggregator_v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+)
+
+type ingressServer struct {
+	loggregator_v2.UnimplementedIngressServer
+
+	mu      sync.Mutex
+	batches []*loggregator_v2.EnvelopeBatch
+	fail    func(*loggregator_v2.EnvelopeBatch) bool
+}
+
+func (s *ingressServer) Send(_ context.Context, batch *loggregator_v2.EnvelopeBatch) (*loggregator_v2.SendResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fail != nil && s.fail(batch) {
+		return nil, errors.New("ingress unavailable")
+	}
+	s.batches = append(s.batches, batch)
+	return &loggregator_v2.SendResponse{}, nil
+}
+
+func (s *ingressServer) Envelopes() []*loggregator_v2.Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var envelopes []*loggregator_v2.Envelope
+	for _, batch := range s.batches {
+		envelopes = append(envelopes, batch.Batch...)
+	}
+	return envelopes
+}
+
+var _ = Describe("LoggregatorBatchSender", func() {
+	var (
+		server *ingressServer
+		client loggregator_v2.IngressClient
+	)
+
+	newSender := func(envelopeSize int) *metrics.LoggregatorBatchSender {
+		return metrics.NewLoggregatorBatchSender(client, "source-id", "instance-id", map[string]string{"origin": "p-mysql"}, envelopeSize)
+	}
+
+	BeforeEach(func() {
+		serverCert, err := tls.LoadX509KeyPair("../fixtures/certs/loggregator.crt", "../fixtures/certs/loggregator.key")
+		Expect(err).NotTo(HaveOccurred())
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		server = &ingressServer{}
+		grpcServer := grpc.NewServer(grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientAuth:   tls.RequireAnyClientCert,
+		})))
+		loggregator_v2.RegisterIngressServer(grpcServer, server)
+		go func() { _ = grpcServer.Serve(listener) }()
+		DeferCleanup(grpcServer.Stop)
+
+		tlsConfig, err := loggregator.NewIngressTLSConfig(
+			"../fixtures/certs/loggregator.crt",
+			"../fixtures/certs/loggregator-agent.crt",
+			"../fixtures/certs/loggregator-agent.key",
+		)
+		Expect(err).NotTo(HaveOccurred())
+		tlsConfig.ServerName = "loggregator"
+
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		client = loggregator_v2.NewIngressClient(conn)
+	})
+
+	It("sends nothing until flushed, and nothing when no values were sent", func() {
+		sender := newSender(0)
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(server.Envelopes()).To(BeEmpty())
+
+		Expect(sender.Flush()).To(Succeed())
+		Expect(server.Envelopes()).To(HaveLen(1))
+
+		Expect(sender.Flush()).To(Succeed())
+		Expect(server.Envelopes()).To(HaveLen(1))
+	})
+
+	It("sends the values of a cycle with the same tags in one gauge envelope", func() {
+		sender := newSender(0)
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", map[string]string{"az": "z1"})).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/connections", 12, "number", map[string]string{"az": "z1"})).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/available", 0, "boolean", map[string]string{"az": "z2"})).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		envelopes := server.Envelopes()
+		Expect(envelopes).To(HaveLen(2))
+
+		Expect(envelopes[0].SourceId).To(Equal("source-id"))
+		Expect(envelopes[0].InstanceId).To(Equal("instance-id"))
+		Expect(envelopes[0].Tags).To(Equal(map[string]string{"origin": "p-mysql", "az": "z1"}))
+		Expect(envelopes[0].GetGauge().Metrics).To(HaveLen(2))
+		Expect(envelopes[0].GetGauge().Metrics["/p-mysql/available"].Value).To(Equal(1.0))
+		Expect(envelopes[0].GetGauge().Metrics["/p-mysql/connections"].Value).To(Equal(12.0))
+		Expect(envelopes[0].GetGauge().Metrics["/p-mysql/connections"].Unit).To(Equal("number"))
+
+		Expect(envelopes[1].Tags).To(Equal(map[string]string{"origin": "p-mysql", "az": "z2"}))
+		Expect(envelopes[1].GetGauge().Metrics["/p-mysql/available"].Value).To(Equal(0.0))
+	})
+
+	It("starts a new envelope when a value is sent twice", func() {
+		sender := newSender(0)
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/available", 0, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		envelopes := server.Envelopes()
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetGauge().Metrics["/p-mysql/available"].Value).To(Equal(1.0))
+		Expect(envelopes[1].GetGauge().Metrics["/p-mysql/available"].Value).To(Equal(0.0))
+	})
+
+	It("keeps envelopes under the maximum size", func() {
+		sender := newSender(256)
+		for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+			Expect(sender.SendValue("/p-mysql/innodb/"+name, 1, "number", nil)).To(Succeed())
+		}
+		Expect(sender.Flush()).To(Succeed())
+
+		envelopes := server.Envelopes()
+		Expect(len(envelopes)).To(BeNumerically(">", 1))
+
+		values := 0
+		for _, envelope := range envelopes {
+			Expect(len(envelope.GetGauge().Metrics)).To(BeNumerically(">", 1))
+			values += len(envelope.GetGauge().Metrics)
+		}
+		Expect(values).To(Equal(10))
+	})
+
+	It("sends events right away", func() {
+		sender := newSender(0)
+		Expect(sender.SendEvent("node left Primary component", "changed from PRIMARY to NON_PRIMARY", map[string]string{"az": "z1"})).To(Succeed())
+
+		envelopes := server.Envelopes()
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].SourceId).To(Equal("source-id"))
+		Expect(envelopes[0].Tags).To(Equal(map[string]string{"origin": "p-mysql", "az": "z1"}))
+		Expect(envelopes[0].GetEvent().Title).To(Equal("node left Primary component"))
+		Expect(envelopes[0].GetEvent().Body).To(Equal("changed from PRIMARY to NON_PRIMARY"))
+	})
+
+	It("reports how many values could not be sent", func() {
+		server.fail = func(*loggregator_v2.EnvelopeBatch) bool { return true }
+
+		sender := newSender(0)
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/connections", 12, "number", nil)).To(Succeed())
+
+		err := sender.Flush()
+		var flushErr *metrics.FlushError
+		Expect(errors.As(err, &flushErr)).To(BeTrue())
+		Expect(flushErr.Failed).To(Equal(2))
+		Expect(flushErr.Total).To(Equal(2))
+		Expect(err).To(MatchError(ContainSubstring("ingress unavailable")))
+	})
+})
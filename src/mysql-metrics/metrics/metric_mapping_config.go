@@ -1,19 +1,79 @@
 package metrics
 
+import (
+	"sort"
+)
+
 type MetricMappingConfig struct {
-	MysqlMetricMappings           map[string]MetricDefinition
-	GaleraMetricMappings          map[string]MetricDefinition
-	LeaderFollowerMetricMappings  map[string]MetricDefinition
-	DiskUsageMetricMappings       map[string]MetricDefinition
-	DiskPerformanceMetricMappings map[string]MetricDefinition
-	BrokerMetricMappings          map[string]MetricDefinition
-	CPUMetricMappings             map[string]MetricDefinition
-	BackupMetricMappings          map[string]MetricDefinition
+	MysqlMetricMappings           map[string]MetricDefinition `yaml:"mysql"`
+	GaleraMetricMappings          map[string]MetricDefinition `yaml:"galera"`
+	LeaderFollowerMetricMappings  map[string]MetricDefinition `yaml:"leader_follower"`
+	DiskUsageMetricMappings       map[string]MetricDefinition `yaml:"disk_usage"`
+	DiskPerformanceMetricMappings map[string]MetricDefinition `yaml:"disk_performance"`
+	BrokerMetricMappings          map[string]MetricDefinition `yaml:"broker"`
+	CPUMetricMappings             map[string]MetricDefinition `yaml:"cpu"`
+	CPUCoreMetricMappings         map[string]MetricDefinition `yaml:"cpu_core"`
+	MemoryMetricMappings          map[string]MetricDefinition `yaml:"memory"`
+	BackupMetricMappings          map[string]MetricDefinition `yaml:"backup"`
+	DigestMetricMappings          map[string]MetricDefinition `yaml:"digest"`
+	SchemaSizeMetricMappings      map[string]MetricDefinition `yaml:"schema_size"`
+	TableSizeMetricMappings       map[string]MetricDefinition `yaml:"table_size"`
+	TransactionMetricMappings     map[string]MetricDefinition `yaml:"transactions"`
+	InnoDBStatusMetricMappings    map[string]MetricDefinition `yaml:"innodb_status"`
+	CollectorMetricMappings       map[string]MetricDefinition `yaml:"collector"`
+	TelemetryMetricMappings       map[string]MetricDefinition `yaml:"telemetry"`
+	AlertMetricMappings           map[string]MetricDefinition `yaml:"alert"`
 }
 
+const (
+	GaugeKind   = "gauge"
+	CounterKind = "counter"
+)
+
+// MetricDefinition describes how a raw value is emitted. Values of kind
+// CounterKind are cumulative totals; in addition to the total, their per
+// second rate and per interval delta are emitted with the "_rate" and
+// "_delta" key suffixes. Only the categories in counterCategories can have
+// counters.
 type MetricDefinition struct {
 	Key  string `yaml:"key"`
 	Unit string `yaml:"unit"`
+	Kind string `yaml:"kind"`
+}
+
+// counterCategories are the categories whose values are sampled once per
+// cycle, so that the gatherer can compare every sample of a counter with the
+// previous one.
+var counterCategories = []string{
+	"mysql",
+	"galera",
+	"disk_usage",
+	"disk_performance",
+	"broker",
+	"cpu",
+	"memory",
+	"transactions",
+	"innodb_status",
+}
+
+// Counters returns the names of the raw values mapped as counters, by
+// category.
+func (c *MetricMappingConfig) Counters() map[string][]string {
+	counters := make(map[string][]string)
+	for _, category := range counterCategories {
+		var names []string
+		for name, definition := range *c.category(category) {
+			if definition.Kind == CounterKind {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			counters[category] = names
+		}
+	}
+
+	return counters
 }
 
 func DefaultMetricMappingConfig() *MetricMappingConfig {
@@ -38,6 +98,7 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"questions": {
 				Key:  "performance/questions",
 				Unit: "metric",
+				Kind: CounterKind,
 			},
 			"queries": {
 				Key:  "performance/queries",
@@ -66,38 +127,47 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"innodb_data_read": {
 				Key:  "innodb/data_read",
 				Unit: "byte",
+				Kind: CounterKind,
 			},
 			"innodb_data_written": {
 				Key:  "innodb/data_written",
 				Unit: "byte",
+				Kind: CounterKind,
 			},
 			"innodb_mutex_os_waits": {
 				Key:  "innodb/mutex_os_waits",
 				Unit: "event",
+				Kind: CounterKind,
 			},
 			"innodb_mutex_spin_rounds": {
 				Key:  "innodb/mutex_spin_rounds",
 				Unit: "event",
+				Kind: CounterKind,
 			},
 			"innodb_mutex_spin_waits": {
 				Key:  "innodb/mutex_spin_waits",
 				Unit: "event",
+				Kind: CounterKind,
 			},
 			"innodb_os_log_fsyncs": {
 				Key:  "innodb/os_log_fsyncs",
 				Unit: "event",
+				Kind: CounterKind,
 			},
 			"innodb_row_lock_time": {
 				Key:  "innodb/row_lock_time",
 				Unit: "millisecond",
+				Kind: CounterKind,
 			},
 			"innodb_row_lock_waits": {
 				Key:  "innodb/row_lock_waits",
 				Unit: "event",
+				Kind: CounterKind,
 			},
 			"connections": {
 				Key:  "net/connections",
 				Unit: "connection",
+				Kind: CounterKind,
 			},
 			"max_used_connections": {
 				Key:  "net/max_used_connections",
@@ -106,50 +176,62 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"com_delete": {
 				Key:  "performance/com_delete",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_delete_multi": {
 				Key:  "performance/com_delete_multi",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_insert": {
 				Key:  "performance/com_insert",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_insert_select": {
 				Key:  "performance/com_insert_select",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_replace_select": {
 				Key:  "performance/com_replace_select",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_select": {
 				Key:  "performance/com_select",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_update": {
 				Key:  "performance/com_update",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"com_update_multi": {
 				Key:  "performance/com_update_multi",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"created_tmp_disk_tables": {
 				Key:  "performance/created_tmp_disk_tables",
 				Unit: "table",
+				Kind: CounterKind,
 			},
 			"created_tmp_files": {
 				Key:  "performance/created_tmp_files",
 				Unit: "file",
+				Kind: CounterKind,
 			},
 			"created_tmp_tables": {
 				Key:  "performance/created_tmp_tables",
 				Unit: "table",
+				Kind: CounterKind,
 			},
 			"cpu_time": {
 				Key:  "performance/cpu_time",
 				Unit: "second",
+				Kind: CounterKind,
 			},
 			"open_files": {
 				Key:  "performance/open_files",
@@ -162,6 +244,7 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"opened_tables": {
 				Key:  "performance/opened_tables",
 				Unit: "integer",
+				Kind: CounterKind,
 			},
 			"open_table_definitions": {
 				Key:  "performance/open_table_definitions",
@@ -170,18 +253,22 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"opened_table_definitions": {
 				Key:  "performance/opened_table_definitions",
 				Unit: "integer",
+				Kind: CounterKind,
 			},
 			"qcache_hits": {
 				Key:  "performance/qcache_hits",
 				Unit: "hit",
+				Kind: CounterKind,
 			},
 			"slow_queries": {
 				Key:  "performance/slow_queries",
 				Unit: "query",
+				Kind: CounterKind,
 			},
 			"table_locks_waited": {
 				Key:  "performance/table_locks_waited",
 				Unit: "number",
+				Kind: CounterKind,
 			},
 			"threads_connected": {
 				Key:  "performance/threads_connected",
@@ -198,6 +285,7 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"rpl_semi_sync_master_no_tx": {
 				Key:  "rpl_semi_sync_master_no_tx",
 				Unit: "integer",
+				Kind: CounterKind,
 			},
 			"rpl_semi_sync_master_wait_sessions": {
 				Key:  "rpl_semi_sync_master_wait_sessions",
@@ -240,10 +328,12 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 			"wsrep_flow_control_sent": {
 				Key:  "galera/wsrep_flow_control_sent",
 				Unit: "number",
+				Kind: CounterKind,
 			},
 			"wsrep_flow_control_recv": {
 				Key:  "galera/wsrep_flow_control_recv",
 				Unit: "number",
+				Kind: CounterKind,
 			},
 		},
 		LeaderFollowerMetricMappings: map[string]MetricDefinition{
@@ -399,6 +489,142 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 				Key:  "performance/cpu_utilization_percent",
 				Unit: "percentage",
 			},
+			"cpu_user_percent": {
+				Key:  "performance/cpu_user_percent",
+				Unit: "percentage",
+			},
+			"cpu_nice_percent": {
+				Key:  "performance/cpu_nice_percent",
+				Unit: "percentage",
+			},
+			"cpu_system_percent": {
+				Key:  "performance/cpu_system_percent",
+				Unit: "percentage",
+			},
+			"cpu_idle_percent": {
+				Key:  "performance/cpu_idle_percent",
+				Unit: "percentage",
+			},
+			"cpu_iowait_percent": {
+				Key:  "performance/cpu_iowait_percent",
+				Unit: "percentage",
+			},
+			"cpu_irq_percent": {
+				Key:  "performance/cpu_irq_percent",
+				Unit: "percentage",
+			},
+			"cpu_softirq_percent": {
+				Key:  "performance/cpu_softirq_percent",
+				Unit: "percentage",
+			},
+			"cpu_steal_percent": {
+				Key:  "performance/cpu_steal_percent",
+				Unit: "percentage",
+			},
+			"load_average_1m": {
+				Key:  "system/load_average_1m",
+				Unit: "float",
+			},
+			"load_average_5m": {
+				Key:  "system/load_average_5m",
+				Unit: "float",
+			},
+			"load_average_15m": {
+				Key:  "system/load_average_15m",
+				Unit: "float",
+			},
+		},
+		CPUCoreMetricMappings: map[string]MetricDefinition{
+			"utilization_percent": {
+				Key:  "cpu_core/utilization_percent",
+				Unit: "percentage",
+			},
+			"user_percent": {
+				Key:  "cpu_core/user_percent",
+				Unit: "percentage",
+			},
+			"nice_percent": {
+				Key:  "cpu_core/nice_percent",
+				Unit: "percentage",
+			},
+			"system_percent": {
+				Key:  "cpu_core/system_percent",
+				Unit: "percentage",
+			},
+			"idle_percent": {
+				Key:  "cpu_core/idle_percent",
+				Unit: "percentage",
+			},
+			"iowait_percent": {
+				Key:  "cpu_core/iowait_percent",
+				Unit: "percentage",
+			},
+			"irq_percent": {
+				Key:  "cpu_core/irq_percent",
+				Unit: "percentage",
+			},
+			"softirq_percent": {
+				Key:  "cpu_core/softirq_percent",
+				Unit: "percentage",
+			},
+			"steal_percent": {
+				Key:  "cpu_core/steal_percent",
+				Unit: "percentage",
+			},
+		},
+		MemoryMetricMappings: map[string]MetricDefinition{
+			"memory_total": {
+				Key:  "system/memory_total",
+				Unit: "kb",
+			},
+			"memory_available": {
+				Key:  "system/memory_available",
+				Unit: "kb",
+			},
+			"memory_available_percent": {
+				Key:  "system/memory_available_percent",
+				Unit: "percentage",
+			},
+			"swap_total": {
+				Key:  "system/swap_total",
+				Unit: "kb",
+			},
+			"swap_used": {
+				Key:  "system/swap_used",
+				Unit: "kb",
+			},
+			"swap_used_percent": {
+				Key:  "system/swap_used_percent",
+				Unit: "percentage",
+			},
+			"mysqld_rss": {
+				Key:  "system/mysqld_rss",
+				Unit: "kb",
+			},
+			"mysqld_rss_percent": {
+				Key:  "system/mysqld_rss_percent",
+				Unit: "percentage",
+			},
+			"mysqld_peak_rss": {
+				Key:  "system/mysqld_peak_rss",
+				Unit: "kb",
+			},
+			"mysqld_swap": {
+				Key:  "system/mysqld_swap",
+				Unit: "kb",
+			},
+			"mysqld_pss": {
+				Key:  "system/mysqld_pss",
+				Unit: "kb",
+			},
+			"mysqld_anonymous": {
+				Key:  "system/mysqld_anonymous",
+				Unit: "kb",
+			},
+			"mysqld_rss_buffer_pool_ratio": {
+				Key:  "system/mysqld_rss_buffer_pool_ratio",
+				Unit: "float",
+			},
 		},
 		BackupMetricMappings: map[string]MetricDefinition{
 			"last_successful_backup": {
@@ -406,5 +632,250 @@ func DefaultMetricMappingConfig() *MetricMappingConfig {
 				Unit: "seconds",
 			},
 		},
+		DigestMetricMappings: map[string]MetricDefinition{
+			"calls": {
+				Key:  "digest/calls",
+				Unit: "query",
+			},
+			"latency": {
+				Key:  "digest/latency",
+				Unit: "millisecond",
+			},
+			"avg_latency": {
+				Key:  "digest/avg_latency",
+				Unit: "millisecond",
+			},
+			"rows_examined": {
+				Key:  "digest/rows_examined",
+				Unit: "number",
+			},
+			"errors": {
+				Key:  "digest/errors",
+				Unit: "number",
+			},
+		},
+		SchemaSizeMetricMappings: map[string]MetricDefinition{
+			"tables": {
+				Key:  "schema/tables",
+				Unit: "table",
+			},
+			"data_length": {
+				Key:  "schema/data_length",
+				Unit: "byte",
+			},
+			"index_length": {
+				Key:  "schema/index_length",
+				Unit: "byte",
+			},
+			"total_length": {
+				Key:  "schema/total_length",
+				Unit: "byte",
+			},
+			"data_free": {
+				Key:  "schema/data_free",
+				Unit: "byte",
+			},
+			"table_rows": {
+				Key:  "schema/rows",
+				Unit: "number",
+			},
+			"file_size": {
+				Key:  "schema/file_size",
+				Unit: "byte",
+			},
+		},
+		TableSizeMetricMappings: map[string]MetricDefinition{
+			"data_length": {
+				Key:  "table/data_length",
+				Unit: "byte",
+			},
+			"index_length": {
+				Key:  "table/index_length",
+				Unit: "byte",
+			},
+			"total_length": {
+				Key:  "table/total_length",
+				Unit: "byte",
+			},
+			"data_free": {
+				Key:  "table/data_free",
+				Unit: "byte",
+			},
+			"table_rows": {
+				Key:  "table/rows",
+				Unit: "number",
+			},
+			"file_size": {
+				Key:  "table/file_size",
+				Unit: "byte",
+			},
+		},
+		TransactionMetricMappings: map[string]MetricDefinition{
+			"transactions_open": {
+				Key:  "transactions/open",
+				Unit: "number",
+			},
+			"oldest_transaction_age": {
+				Key:  "transactions/oldest_age",
+				Unit: "second",
+			},
+			"transactions_older_than": {
+				Key:  "transactions/older_than",
+				Unit: "number",
+			},
+			"lock_waits": {
+				Key:  "transactions/lock_waits",
+				Unit: "number",
+			},
+			"lock_wait_chain_depth": {
+				Key:  "transactions/lock_wait_chain_depth",
+				Unit: "number",
+			},
+			"metadata_lock_waiters": {
+				Key:  "transactions/metadata_lock_waiters",
+				Unit: "number",
+			},
+		},
+		InnoDBStatusMetricMappings: map[string]MetricDefinition{
+			"semaphore_reservation_count": {
+				Key:  "innodb_status/semaphore_reservation_count",
+				Unit: "event",
+				Kind: CounterKind,
+			},
+			"semaphore_signal_count": {
+				Key:  "innodb_status/semaphore_signal_count",
+				Unit: "event",
+				Kind: CounterKind,
+			},
+			"semaphore_long_waits": {
+				Key:  "innodb_status/semaphore_long_waits",
+				Unit: "thread",
+			},
+			"semaphore_longest_wait": {
+				Key:  "innodb_status/semaphore_longest_wait",
+				Unit: "second",
+			},
+			"rw_shared_os_waits": {
+				Key:  "innodb_status/rw_shared_os_waits",
+				Unit: "event",
+				Kind: CounterKind,
+			},
+			"rw_excl_os_waits": {
+				Key:  "innodb_status/rw_excl_os_waits",
+				Unit: "event",
+				Kind: CounterKind,
+			},
+			"rw_sx_os_waits": {
+				Key:  "innodb_status/rw_sx_os_waits",
+				Unit: "event",
+				Kind: CounterKind,
+			},
+			"latest_deadlock": {
+				Key:  "innodb_status/latest_deadlock",
+				Unit: "seconds",
+			},
+			"history_list_length": {
+				Key:  "innodb_status/history_list_length",
+				Unit: "number",
+			},
+			"log_sequence_number": {
+				Key:  "innodb_status/log_sequence_number",
+				Unit: "byte",
+			},
+			"checkpoint_age": {
+				Key:  "innodb_status/checkpoint_age",
+				Unit: "byte",
+			},
+			"max_checkpoint_age": {
+				Key:  "innodb_status/max_checkpoint_age",
+				Unit: "byte",
+			},
+			"redo_log_capacity": {
+				Key:  "innodb_status/redo_log_capacity",
+				Unit: "byte",
+			},
+			"checkpoint_age_percent": {
+				Key:  "innodb_status/checkpoint_age_percent",
+				Unit: "percentage",
+			},
+			"pending_normal_aio_reads": {
+				Key:  "innodb_status/pending_normal_aio_reads",
+				Unit: "number",
+			},
+			"pending_normal_aio_writes": {
+				Key:  "innodb_status/pending_normal_aio_writes",
+				Unit: "number",
+			},
+			"pending_ibuf_aio_reads": {
+				Key:  "innodb_status/pending_ibuf_aio_reads",
+				Unit: "number",
+			},
+			"pending_log_ios": {
+				Key:  "innodb_status/pending_log_ios",
+				Unit: "number",
+			},
+			"pending_sync_ios": {
+				Key:  "innodb_status/pending_sync_ios",
+				Unit: "number",
+			},
+			"pending_log_flushes": {
+				Key:  "innodb_status/pending_log_flushes",
+				Unit: "number",
+			},
+			"pending_buffer_pool_flushes": {
+				Key:  "innodb_status/pending_buffer_pool_flushes",
+				Unit: "number",
+			},
+			"pending_buffer_pool_reads": {
+				Key:  "innodb_status/pending_buffer_pool_reads",
+				Unit: "number",
+			},
+			"pending_buffer_pool_lru_writes": {
+				Key:  "innodb_status/pending_buffer_pool_lru_writes",
+				Unit: "number",
+			},
+			"pending_buffer_pool_flush_list_writes": {
+				Key:  "innodb_status/pending_buffer_pool_flush_list_writes",
+				Unit: "number",
+			},
+		},
+		CollectorMetricMappings: map[string]MetricDefinition{
+			"timed_out": {
+				Key:  "collector/timed_out",
+				Unit: "boolean",
+			},
+			"duration": {
+				Key:  "collector/duration",
+				Unit: "millisecond",
+			},
+			"errors": {
+				Key:  "collector/errors",
+				Unit: "number",
+			},
+		},
+		TelemetryMetricMappings: map[string]MetricDefinition{
+			"cycle_duration": {
+				Key:  "telemetry/cycle_duration",
+				Unit: "millisecond",
+			},
+			"metric_errors": {
+				Key:  "telemetry/metric_errors",
+				Unit: "number",
+			},
+			"sender_failures": {
+				Key:  "telemetry/sender_failures",
+				Unit: "number",
+			},
+			"seconds_since_last_database_read": {
+				Key:  "telemetry/seconds_since_last_database_read",
+				Unit: "second",
+			},
+		},
+		AlertMetricMappings: map[string]MetricDefinition{
+			"state": {
+				Key:  "alert/state",
+				Unit: "integer",
+			},
+		},
 	}
 }
@@ -0,0 +1,201 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KnownUnits lists the units a metric mapping may use.
+var KnownUnits = []string{
+	"boolean",
+	"byte",
+	"bytes",
+	"connection",
+	"event",
+	"file",
+	"float",
+	"hit",
+	"integer",
+	"kb",
+	"lock",
+	"megabyte",
+	"metric",
+	"microsecond",
+	"millisecond",
+	"node",
+	"number",
+	"ops_per_second",
+	"page",
+	"percentage",
+	"query",
+	"second",
+	"seconds",
+	"table",
+	"thread",
+}
+
+// MetricMappingFile is the operator supplied mapping file. Every category is
+// merged over the base mapping, unless it is listed in Replace, in which case
+// it replaces the base mapping for that category entirely.
+type MetricMappingFile struct {
+	Replace             []string `yaml:"replace"`
+	MetricMappingConfig `yaml:",inline"`
+}
+
+// LoadMetricMappingConfig reads the mapping file at path and applies it to a
+// copy of base. All invalid entries are reported together.
+func LoadMetricMappingConfig(path string, base *MetricMappingConfig) (*MetricMappingConfig, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var mappingFile MetricMappingFile
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&mappingFile); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse metric mapping file %s: %w", path, err)
+	}
+
+	result := &MetricMappingConfig{}
+	var errs []error
+
+	for _, category := range mappingFile.Replace {
+		if !slices.Contains(metricMappingCategories, category) {
+			errs = append(errs, fmt.Errorf("replace: unknown metric mapping category %q", category))
+		}
+	}
+
+	for _, category := range metricMappingCategories {
+		baseMappings := *base.category(category)
+		fileMappings := *mappingFile.category(category)
+
+		merged := make(map[string]MetricDefinition, len(baseMappings)+len(fileMappings))
+		if !slices.Contains(mappingFile.Replace, category) {
+			for name, definition := range baseMappings {
+				merged[name] = definition
+			}
+		}
+		for name, definition := range fileMappings {
+			merged[name] = definition
+		}
+
+		errs = append(errs, validateMetricMappings(category, merged)...)
+		*result.category(category) = merged
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid metric mapping file %s: %w", path, errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+var metricMappingCategories = []string{
+	"mysql",
+	"galera",
+	"leader_follower",
+	"disk_usage",
+	"disk_performance",
+	"broker",
+	"cpu",
+	"cpu_core",
+	"memory",
+	"backup",
+	"digest",
+	"schema_size",
+	"table_size",
+	"transactions",
+	"innodb_status",
+	"collector",
+	"telemetry",
+	"alert",
+}
+
+func (c *MetricMappingConfig) category(name string) *map[string]MetricDefinition {
+	switch name {
+	case "mysql":
+		return &c.MysqlMetricMappings
+	case "galera":
+		return &c.GaleraMetricMappings
+	case "leader_follower":
+		return &c.LeaderFollowerMetricMappings
+	case "disk_usage":
+		return &c.DiskUsageMetricMappings
+	case "disk_performance":
+		return &c.DiskPerformanceMetricMappings
+	case "broker":
+		return &c.BrokerMetricMappings
+	case "cpu":
+		return &c.CPUMetricMappings
+	case "cpu_core":
+		return &c.CPUCoreMetricMappings
+	case "memory":
+		return &c.MemoryMetricMappings
+	case "backup":
+		return &c.BackupMetricMappings
+	case "digest":
+		return &c.DigestMetricMappings
+	case "schema_size":
+		return &c.SchemaSizeMetricMappings
+	case "table_size":
+		return &c.TableSizeMetricMappings
+	case "transactions":
+		return &c.TransactionMetricMappings
+	case "innodb_status":
+		return &c.InnoDBStatusMetricMappings
+	case "collector":
+		return &c.CollectorMetricMappings
+	case "telemetry":
+		return &c.TelemetryMetricMappings
+	case "alert":
+		return &c.AlertMetricMappings
+	default:
+		panic("unknown metric mapping category " + name)
+	}
+}
+
+func validateMetricMappings(category string, mappings map[string]MetricDefinition) []error {
+	names := make([]string, 0, len(mappings))
+	for name := range mappings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	keys := make(map[string]string, len(mappings))
+	for _, name := range names {
+		definition := mappings[name]
+
+		if definition.Key == "" {
+			errs = append(errs, fmt.Errorf("%s.%s: key is required", category, name))
+		} else if other, ok := keys[definition.Key]; ok {
+			errs = append(errs, fmt.Errorf("%s.%s: duplicate key %q already used by %s.%s", category, name, definition.Key, category, other))
+		} else {
+			keys[definition.Key] = name
+		}
+
+		if !slices.Contains(KnownUnits, definition.Unit) {
+			errs = append(errs, fmt.Errorf("%s.%s: unknown unit %q", category, name, definition.Unit))
+		}
+
+		switch definition.Kind {
+		case "", GaugeKind:
+		case CounterKind:
+			if !slices.Contains(counterCategories, category) {
+				errs = append(errs, fmt.Errorf("%s.%s: kind %q is not supported in the %s category", category, name, definition.Kind, category))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("%s.%s: unknown kind %q", category, name, definition.Kind))
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,177 @@
+package metrics_test
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+)
+
+var _ = Describe("LoadMetricMappingConfig()", func() {
+	var (
+		mappingFilepath string
+		base            *metrics.MetricMappingConfig
+	)
+
+	writeMappingFile := func(contents string) {
+		Expect(os.WriteFile(mappingFilepath, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		mappingFilepath = filepath.Join(GinkgoT().TempDir(), "metric-mappings.yml")
+		base = metrics.DefaultMetricMappingConfig()
+	})
+
+	It("returns the base mappings for an empty file", func() {
+		writeMappingFile("")
+
+		mappingConfig, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappingConfig).To(Equal(base))
+	})
+
+	It("merges entries over the base mappings", func() {
+		writeMappingFile(`
+mysql:
+  innodb_buffer_pool_reads:
+    key: innodb/buffer_pool_reads
+    unit: event
+  questions:
+    key: performance/questions_total
+    unit: query
+`)
+
+		mappingConfig, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(mappingConfig.MysqlMetricMappings).To(HaveLen(len(base.MysqlMetricMappings) + 1))
+		Expect(mappingConfig.MysqlMetricMappings).To(HaveKeyWithValue("innodb_buffer_pool_reads", metrics.MetricDefinition{
+			Key:  "innodb/buffer_pool_reads",
+			Unit: "event",
+		}))
+		Expect(mappingConfig.MysqlMetricMappings).To(HaveKeyWithValue("questions", metrics.MetricDefinition{
+			Key:  "performance/questions_total",
+			Unit: "query",
+		}))
+		Expect(mappingConfig.GaleraMetricMappings).To(Equal(base.GaleraMetricMappings))
+	})
+
+	It("replaces the categories listed in replace", func() {
+		writeMappingFile(`
+replace: [galera]
+galera:
+  wsrep_cluster_size:
+    key: galera/cluster_size
+    unit: node
+`)
+
+		mappingConfig, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(mappingConfig.GaleraMetricMappings).To(Equal(map[string]metrics.MetricDefinition{
+			"wsrep_cluster_size": {Key: "galera/cluster_size", Unit: "node"},
+		}))
+		Expect(mappingConfig.MysqlMetricMappings).To(Equal(base.MysqlMetricMappings))
+	})
+
+	It("does not modify the base mappings", func() {
+		writeMappingFile(`
+replace: [cpu]
+mysql:
+  innodb_buffer_pool_reads: {key: innodb/buffer_pool_reads, unit: event}
+`)
+
+		_, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(base).To(Equal(metrics.DefaultMetricMappingConfig()))
+	})
+
+	It("reports every invalid entry", func() {
+		writeMappingFile(`
+replace: [nonsense]
+mysql:
+  innodb_buffer_pool_reads:
+    key: innodb/buffer_pool_reads
+    unit: furlong
+  buffer_pool_reads_again:
+    key: innodb/buffer_pool_reads
+    unit: event
+  no_key:
+    unit: event
+  com_select:
+    key: performance/com_select
+    unit: query
+    kind: histogram
+digest:
+  calls:
+    key: digest/calls
+    unit: query
+    kind: counter
+`)
+
+		_, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(`replace: unknown metric mapping category "nonsense"`))
+		Expect(err.Error()).To(ContainSubstring(`mysql.innodb_buffer_pool_reads: unknown unit "furlong"`))
+		Expect(err.Error()).To(ContainSubstring(`mysql.innodb_buffer_pool_reads: duplicate key "innodb/buffer_pool_reads" already used by mysql.buffer_pool_reads_again`))
+		Expect(err.Error()).To(ContainSubstring(`mysql.no_key: key is required`))
+		Expect(err.Error()).To(ContainSubstring(`mysql.com_select: unknown kind "histogram"`))
+		Expect(err.Error()).To(ContainSubstring(`digest.calls: kind "counter" is not supported in the digest category`))
+	})
+
+	It("reports duplicate entries in the file", func() {
+		writeMappingFile(`
+mysql:
+  questions: {key: performance/questions, unit: query}
+  questions: {key: performance/questions, unit: query}
+`)
+
+		_, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).To(MatchError(ContainSubstring(`mapping key "questions" already defined`)))
+	})
+
+	It("reports unknown categories", func() {
+		writeMappingFile(`
+mysqll:
+  questions: {key: performance/questions, unit: query}
+`)
+
+		_, err := metrics.LoadMetricMappingConfig(mappingFilepath, base)
+		Expect(err).To(MatchError(ContainSubstring("field mysqll not found")))
+	})
+
+	It("returns an error when the file does not exist", func() {
+		_, err := metrics.LoadMetricMappingConfig("path/does/not/exist", base)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("accepts the default mappings", func() {
+		for _, definitions := range []map[string]metrics.MetricDefinition{
+			base.MysqlMetricMappings,
+			base.GaleraMetricMappings,
+			base.LeaderFollowerMetricMappings,
+			base.DiskUsageMetricMappings,
+			base.DiskPerformanceMetricMappings,
+			base.BrokerMetricMappings,
+			base.CPUMetricMappings,
+			base.CPUCoreMetricMappings,
+			base.MemoryMetricMappings,
+			base.BackupMetricMappings,
+			base.DigestMetricMappings,
+			base.SchemaSizeMetricMappings,
+			base.TableSizeMetricMappings,
+			base.TransactionMetricMappings,
+			base.InnoDBStatusMetricMappings,
+			base.CollectorMetricMappings,
+			base.TelemetryMetricMappings,
+		} {
+			for _, definition := range definitions {
+				Expect(metrics.KnownUnits).To(ContainElement(definition.Unit))
+			}
+		}
+	})
+})
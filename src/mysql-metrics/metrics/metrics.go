@@ -1,9 +1,10 @@
 package metrics
 
 type Metric struct {
-	Key      string  `json:"key"`
-	Value    float64 `json:"value"`
-	Unit     string  `json:"unit"`
+	Key      string            `json:"key"`
+	Value    float64           `json:"value"`
+	Unit     string            `json:"unit"`
+	Tags     map[string]string `json:"tags,omitempty"`
 	RawValue string
 	Error    error
 }
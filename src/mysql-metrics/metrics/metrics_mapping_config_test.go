@@ -32,8 +32,19 @@ var _ = Describe("DefaultMetricsMappingConfig()", func() {
 		Expect(len(leaderFollowerMetricMappings)).To(Equal(6))
 		Expect(len(diskMetricMappings)).To(Equal(20))
 		Expect(len(brokerMetricMappings)).To(Equal(1))
-		Expect(len(cpuMetricMappings)).To(Equal(1))
+		Expect(len(cpuMetricMappings)).To(Equal(12))
 	})
+	It("maps cumulative status variables as counters", func() {
+		metricMappingConfig := metrics.DefaultMetricMappingConfig()
+
+		counters := metricMappingConfig.Counters()
+		Expect(counters["mysql"]).To(ContainElements("com_select", "innodb_data_read", "slow_queries", "innodb_row_lock_waits"))
+		Expect(counters["mysql"]).NotTo(ContainElements("threads_running", "innodb_row_lock_current_waits", "queries"))
+		Expect(counters["galera"]).To(ContainElements("wsrep_flow_control_sent", "wsrep_flow_control_recv"))
+		Expect(counters["innodb_status"]).To(ContainElements("semaphore_signal_count", "rw_shared_os_waits"))
+		Expect(counters["innodb_status"]).NotTo(ContainElement("history_list_length"))
+	})
+
 	Describe("docs", func() {
 		var metricsDocString string
 		var metricMappingConfig *metrics.MetricMappingConfig
@@ -89,5 +100,56 @@ var _ = Describe("DefaultMetricsMappingConfig()", func() {
 				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
 			}
 		})
+
+		It("have all CPU Core Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.CPUCoreMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all Memory Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.MemoryMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all Digest Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.DigestMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all Schema and Table Size Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.SchemaSizeMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+			for _, emittedMetric := range metricMappingConfig.TableSizeMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all Transaction Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.TransactionMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all InnoDB Status Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.InnoDBStatusMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all Collector Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.CollectorMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
+
+		It("have all Telemetry Metrics", func() {
+			for _, emittedMetric := range metricMappingConfig.TelemetryMetricMappings {
+				Expect(metricsDocString).To(ContainSubstring(emittedMetric.Key))
+			}
+		})
 	})
 })
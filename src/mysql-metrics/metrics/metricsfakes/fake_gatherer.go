@@ -2,16 +2,22 @@
 package metricsfakes
 
 import (
+	"context"
 	"sync"
 	"time"
 
+	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/digest"
+	"github.com/cloudfoundry/mysql-metrics/innodbstatus"
 	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
 )
 
 type FakeGatherer struct {
-	BrokerStatsStub        func() (map[string]string, error)
+	BrokerStatsStub        func(context.Context) (map[string]string, error)
 	brokerStatsMutex       sync.RWMutex
 	brokerStatsArgsForCall []struct {
+		arg1 context.Context
 	}
 	brokerStatsReturns struct {
 		result1 map[string]string
@@ -21,21 +27,38 @@ type FakeGatherer struct {
 		result1 map[string]string
 		result2 error
 	}
-	CPUStatsStub        func() (map[string]string, error)
+	CPUStatsStub        func() (map[string]string, []map[string]string, error)
 	cPUStatsMutex       sync.RWMutex
 	cPUStatsArgsForCall []struct {
 	}
 	cPUStatsReturns struct {
 		result1 map[string]string
-		result2 error
+		result2 []map[string]string
+		result3 error
 	}
 	cPUStatsReturnsOnCall map[int]struct {
 		result1 map[string]string
+		result2 []map[string]string
+		result3 error
+	}
+	CustomQueryStub        func(context.Context, config.CustomQuery) ([]map[string]string, error)
+	customQueryMutex       sync.RWMutex
+	customQueryArgsForCall []struct {
+		arg1 context.Context
+		arg2 config.CustomQuery
+	}
+	customQueryReturns struct {
+		result1 []map[string]string
 		result2 error
 	}
-	DatabaseMetadataStub        func() (map[string]string, map[string]string, error)
+	customQueryReturnsOnCall map[int]struct {
+		result1 []map[string]string
+		result2 error
+	}
+	DatabaseMetadataStub        func(context.Context) (map[string]string, map[string]string, error)
 	databaseMetadataMutex       sync.RWMutex
 	databaseMetadataArgsForCall []struct {
+		arg1 context.Context
 	}
 	databaseMetadataReturns struct {
 		result1 map[string]string
@@ -71,9 +94,10 @@ type FakeGatherer struct {
 		result1 map[string]string
 		result2 error
 	}
-	FindLastBackupTimestampStub        func() (time.Time, error)
+	FindLastBackupTimestampStub        func(context.Context) (time.Time, error)
 	findLastBackupTimestampMutex       sync.RWMutex
 	findLastBackupTimestampArgsForCall []struct {
+		arg1 context.Context
 	}
 	findLastBackupTimestampReturns struct {
 		result1 time.Time
@@ -83,9 +107,10 @@ type FakeGatherer struct {
 		result1 time.Time
 		result2 error
 	}
-	FollowerMetadataStub        func() (map[string]string, map[string]string, error)
+	FollowerMetadataStub        func(context.Context) (map[string]string, map[string]string, error)
 	followerMetadataMutex       sync.RWMutex
 	followerMetadataArgsForCall []struct {
+		arg1 context.Context
 	}
 	followerMetadataReturns struct {
 		result1 map[string]string
@@ -97,9 +122,23 @@ type FakeGatherer struct {
 		result2 map[string]string
 		result3 error
 	}
-	IsDatabaseAvailableStub        func() bool
+	InnoDBStatusStub        func(context.Context) (map[string]string, error)
+	innoDBStatusMutex       sync.RWMutex
+	innoDBStatusArgsForCall []struct {
+		arg1 context.Context
+	}
+	innoDBStatusReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	innoDBStatusReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
+	IsDatabaseAvailableStub        func(context.Context) bool
 	isDatabaseAvailableMutex       sync.RWMutex
 	isDatabaseAvailableArgsForCall []struct {
+		arg1 context.Context
 	}
 	isDatabaseAvailableReturns struct {
 		result1 bool
@@ -107,9 +146,10 @@ type FakeGatherer struct {
 	isDatabaseAvailableReturnsOnCall map[int]struct {
 		result1 bool
 	}
-	IsDatabaseFollowerStub        func() (bool, error)
+	IsDatabaseFollowerStub        func(context.Context) (bool, error)
 	isDatabaseFollowerMutex       sync.RWMutex
 	isDatabaseFollowerArgsForCall []struct {
+		arg1 context.Context
 	}
 	isDatabaseFollowerReturns struct {
 		result1 bool
@@ -119,21 +159,88 @@ type FakeGatherer struct {
 		result1 bool
 		result2 error
 	}
+	LatestInnoDBStatusStub        func() *innodbstatus.Status
+	latestInnoDBStatusMutex       sync.RWMutex
+	latestInnoDBStatusArgsForCall []struct {
+	}
+	latestInnoDBStatusReturns struct {
+		result1 *innodbstatus.Status
+	}
+	latestInnoDBStatusReturnsOnCall map[int]struct {
+		result1 *innodbstatus.Status
+	}
+	MemoryStatsStub        func(context.Context, string, bool) (map[string]string, error)
+	memoryStatsMutex       sync.RWMutex
+	memoryStatsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 bool
+	}
+	memoryStatsReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	memoryStatsReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
+	StatementDigestsStub        func(context.Context) (digest.Summary, error)
+	statementDigestsMutex       sync.RWMutex
+	statementDigestsArgsForCall []struct {
+		arg1 context.Context
+	}
+	statementDigestsReturns struct {
+		result1 digest.Summary
+		result2 error
+	}
+	statementDigestsReturnsOnCall map[int]struct {
+		result1 digest.Summary
+		result2 error
+	}
+	TableSizesStub        func(context.Context, bool) ([]tablesize.Table, error)
+	tableSizesMutex       sync.RWMutex
+	tableSizesArgsForCall []struct {
+		arg1 context.Context
+		arg2 bool
+	}
+	tableSizesReturns struct {
+		result1 []tablesize.Table
+		result2 error
+	}
+	tableSizesReturnsOnCall map[int]struct {
+		result1 []tablesize.Table
+		result2 error
+	}
+	TransactionStatsStub        func(context.Context, []int) (map[string]string, error)
+	transactionStatsMutex       sync.RWMutex
+	transactionStatsArgsForCall []struct {
+		arg1 context.Context
+		arg2 []int
+	}
+	transactionStatsReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	transactionStatsReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeGatherer) BrokerStats() (map[string]string, error) {
+func (fake *FakeGatherer) BrokerStats(arg1 context.Context) (map[string]string, error) {
 	fake.brokerStatsMutex.Lock()
 	ret, specificReturn := fake.brokerStatsReturnsOnCall[len(fake.brokerStatsArgsForCall)]
 	fake.brokerStatsArgsForCall = append(fake.brokerStatsArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.BrokerStatsStub
 	fakeReturns := fake.brokerStatsReturns
-	fake.recordInvocation("BrokerStats", []interface{}{})
+	fake.recordInvocation("BrokerStats", []interface{}{arg1})
 	fake.brokerStatsMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -147,12 +254,19 @@ func (fake *FakeGatherer) BrokerStatsCallCount() int {
 	return len(fake.brokerStatsArgsForCall)
 }
 
-func (fake *FakeGatherer) BrokerStatsCalls(stub func() (map[string]string, error)) {
+func (fake *FakeGatherer) BrokerStatsCalls(stub func(context.Context) (map[string]string, error)) {
 	fake.brokerStatsMutex.Lock()
 	defer fake.brokerStatsMutex.Unlock()
 	fake.BrokerStatsStub = stub
 }
 
+func (fake *FakeGatherer) BrokerStatsArgsForCall(i int) context.Context {
+	fake.brokerStatsMutex.RLock()
+	defer fake.brokerStatsMutex.RUnlock()
+	argsForCall := fake.brokerStatsArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGatherer) BrokerStatsReturns(result1 map[string]string, result2 error) {
 	fake.brokerStatsMutex.Lock()
 	defer fake.brokerStatsMutex.Unlock()
@@ -179,7 +293,7 @@ func (fake *FakeGatherer) BrokerStatsReturnsOnCall(i int, result1 map[string]str
 	}{result1, result2}
 }
 
-func (fake *FakeGatherer) CPUStats() (map[string]string, error) {
+func (fake *FakeGatherer) CPUStats() (map[string]string, []map[string]string, error) {
 	fake.cPUStatsMutex.Lock()
 	ret, specificReturn := fake.cPUStatsReturnsOnCall[len(fake.cPUStatsArgsForCall)]
 	fake.cPUStatsArgsForCall = append(fake.cPUStatsArgsForCall, struct {
@@ -192,9 +306,9 @@ func (fake *FakeGatherer) CPUStats() (map[string]string, error) {
 		return stub()
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1, ret.result2, ret.result3
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
 }
 
 func (fake *FakeGatherer) CPUStatsCallCount() int {
@@ -203,49 +317,118 @@ func (fake *FakeGatherer) CPUStatsCallCount() int {
 	return len(fake.cPUStatsArgsForCall)
 }
 
-func (fake *FakeGatherer) CPUStatsCalls(stub func() (map[string]string, error)) {
+func (fake *FakeGatherer) CPUStatsCalls(stub func() (map[string]string, []map[string]string, error)) {
 	fake.cPUStatsMutex.Lock()
 	defer fake.cPUStatsMutex.Unlock()
 	fake.CPUStatsStub = stub
 }
 
-func (fake *FakeGatherer) CPUStatsReturns(result1 map[string]string, result2 error) {
+func (fake *FakeGatherer) CPUStatsReturns(result1 map[string]string, result2 []map[string]string, result3 error) {
 	fake.cPUStatsMutex.Lock()
 	defer fake.cPUStatsMutex.Unlock()
 	fake.CPUStatsStub = nil
 	fake.cPUStatsReturns = struct {
 		result1 map[string]string
-		result2 error
-	}{result1, result2}
+		result2 []map[string]string
+		result3 error
+	}{result1, result2, result3}
 }
 
-func (fake *FakeGatherer) CPUStatsReturnsOnCall(i int, result1 map[string]string, result2 error) {
+func (fake *FakeGatherer) CPUStatsReturnsOnCall(i int, result1 map[string]string, result2 []map[string]string, result3 error) {
 	fake.cPUStatsMutex.Lock()
 	defer fake.cPUStatsMutex.Unlock()
 	fake.CPUStatsStub = nil
 	if fake.cPUStatsReturnsOnCall == nil {
 		fake.cPUStatsReturnsOnCall = make(map[int]struct {
 			result1 map[string]string
-			result2 error
+			result2 []map[string]string
+			result3 error
 		})
 	}
 	fake.cPUStatsReturnsOnCall[i] = struct {
 		result1 map[string]string
+		result2 []map[string]string
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeGatherer) CustomQuery(arg1 context.Context, arg2 config.CustomQuery) ([]map[string]string, error) {
+	fake.customQueryMutex.Lock()
+	ret, specificReturn := fake.customQueryReturnsOnCall[len(fake.customQueryArgsForCall)]
+	fake.customQueryArgsForCall = append(fake.customQueryArgsForCall, struct {
+		arg1 context.Context
+		arg2 config.CustomQuery
+	}{arg1, arg2})
+	stub := fake.CustomQueryStub
+	fakeReturns := fake.customQueryReturns
+	fake.recordInvocation("CustomQuery", []interface{}{arg1, arg2})
+	fake.customQueryMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGatherer) CustomQueryCallCount() int {
+	fake.customQueryMutex.RLock()
+	defer fake.customQueryMutex.RUnlock()
+	return len(fake.customQueryArgsForCall)
+}
+
+func (fake *FakeGatherer) CustomQueryCalls(stub func(context.Context, config.CustomQuery) ([]map[string]string, error)) {
+	fake.customQueryMutex.Lock()
+	defer fake.customQueryMutex.Unlock()
+	fake.CustomQueryStub = stub
+}
+
+func (fake *FakeGatherer) CustomQueryArgsForCall(i int) (context.Context, config.CustomQuery) {
+	fake.customQueryMutex.RLock()
+	defer fake.customQueryMutex.RUnlock()
+	argsForCall := fake.customQueryArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGatherer) CustomQueryReturns(result1 []map[string]string, result2 error) {
+	fake.customQueryMutex.Lock()
+	defer fake.customQueryMutex.Unlock()
+	fake.CustomQueryStub = nil
+	fake.customQueryReturns = struct {
+		result1 []map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) CustomQueryReturnsOnCall(i int, result1 []map[string]string, result2 error) {
+	fake.customQueryMutex.Lock()
+	defer fake.customQueryMutex.Unlock()
+	fake.CustomQueryStub = nil
+	if fake.customQueryReturnsOnCall == nil {
+		fake.customQueryReturnsOnCall = make(map[int]struct {
+			result1 []map[string]string
+			result2 error
+		})
+	}
+	fake.customQueryReturnsOnCall[i] = struct {
+		result1 []map[string]string
 		result2 error
 	}{result1, result2}
 }
 
-func (fake *FakeGatherer) DatabaseMetadata() (map[string]string, map[string]string, error) {
+func (fake *FakeGatherer) DatabaseMetadata(arg1 context.Context) (map[string]string, map[string]string, error) {
 	fake.databaseMetadataMutex.Lock()
 	ret, specificReturn := fake.databaseMetadataReturnsOnCall[len(fake.databaseMetadataArgsForCall)]
 	fake.databaseMetadataArgsForCall = append(fake.databaseMetadataArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.DatabaseMetadataStub
 	fakeReturns := fake.databaseMetadataReturns
-	fake.recordInvocation("DatabaseMetadata", []interface{}{})
+	fake.recordInvocation("DatabaseMetadata", []interface{}{arg1})
 	fake.databaseMetadataMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -259,12 +442,19 @@ func (fake *FakeGatherer) DatabaseMetadataCallCount() int {
 	return len(fake.databaseMetadataArgsForCall)
 }
 
-func (fake *FakeGatherer) DatabaseMetadataCalls(stub func() (map[string]string, map[string]string, error)) {
+func (fake *FakeGatherer) DatabaseMetadataCalls(stub func(context.Context) (map[string]string, map[string]string, error)) {
 	fake.databaseMetadataMutex.Lock()
 	defer fake.databaseMetadataMutex.Unlock()
 	fake.DatabaseMetadataStub = stub
 }
 
+func (fake *FakeGatherer) DatabaseMetadataArgsForCall(i int) context.Context {
+	fake.databaseMetadataMutex.RLock()
+	defer fake.databaseMetadataMutex.RUnlock()
+	argsForCall := fake.databaseMetadataArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGatherer) DatabaseMetadataReturns(result1 map[string]string, result2 map[string]string, result3 error) {
 	fake.databaseMetadataMutex.Lock()
 	defer fake.databaseMetadataMutex.Unlock()
@@ -406,17 +596,18 @@ func (fake *FakeGatherer) DiskStatsReturnsOnCall(i int, result1 map[string]strin
 	}{result1, result2}
 }
 
-func (fake *FakeGatherer) FindLastBackupTimestamp() (time.Time, error) {
+func (fake *FakeGatherer) FindLastBackupTimestamp(arg1 context.Context) (time.Time, error) {
 	fake.findLastBackupTimestampMutex.Lock()
 	ret, specificReturn := fake.findLastBackupTimestampReturnsOnCall[len(fake.findLastBackupTimestampArgsForCall)]
 	fake.findLastBackupTimestampArgsForCall = append(fake.findLastBackupTimestampArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.FindLastBackupTimestampStub
 	fakeReturns := fake.findLastBackupTimestampReturns
-	fake.recordInvocation("FindLastBackupTimestamp", []interface{}{})
+	fake.recordInvocation("FindLastBackupTimestamp", []interface{}{arg1})
 	fake.findLastBackupTimestampMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -430,12 +621,19 @@ func (fake *FakeGatherer) FindLastBackupTimestampCallCount() int {
 	return len(fake.findLastBackupTimestampArgsForCall)
 }
 
-func (fake *FakeGatherer) FindLastBackupTimestampCalls(stub func() (time.Time, error)) {
+func (fake *FakeGatherer) FindLastBackupTimestampCalls(stub func(context.Context) (time.Time, error)) {
 	fake.findLastBackupTimestampMutex.Lock()
 	defer fake.findLastBackupTimestampMutex.Unlock()
 	fake.FindLastBackupTimestampStub = stub
 }
 
+func (fake *FakeGatherer) FindLastBackupTimestampArgsForCall(i int) context.Context {
+	fake.findLastBackupTimestampMutex.RLock()
+	defer fake.findLastBackupTimestampMutex.RUnlock()
+	argsForCall := fake.findLastBackupTimestampArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGatherer) FindLastBackupTimestampReturns(result1 time.Time, result2 error) {
 	fake.findLastBackupTimestampMutex.Lock()
 	defer fake.findLastBackupTimestampMutex.Unlock()
@@ -462,17 +660,18 @@ func (fake *FakeGatherer) FindLastBackupTimestampReturnsOnCall(i int, result1 ti
 	}{result1, result2}
 }
 
-func (fake *FakeGatherer) FollowerMetadata() (map[string]string, map[string]string, error) {
+func (fake *FakeGatherer) FollowerMetadata(arg1 context.Context) (map[string]string, map[string]string, error) {
 	fake.followerMetadataMutex.Lock()
 	ret, specificReturn := fake.followerMetadataReturnsOnCall[len(fake.followerMetadataArgsForCall)]
 	fake.followerMetadataArgsForCall = append(fake.followerMetadataArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.FollowerMetadataStub
 	fakeReturns := fake.followerMetadataReturns
-	fake.recordInvocation("FollowerMetadata", []interface{}{})
+	fake.recordInvocation("FollowerMetadata", []interface{}{arg1})
 	fake.followerMetadataMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2, ret.result3
@@ -486,12 +685,19 @@ func (fake *FakeGatherer) FollowerMetadataCallCount() int {
 	return len(fake.followerMetadataArgsForCall)
 }
 
-func (fake *FakeGatherer) FollowerMetadataCalls(stub func() (map[string]string, map[string]string, error)) {
+func (fake *FakeGatherer) FollowerMetadataCalls(stub func(context.Context) (map[string]string, map[string]string, error)) {
 	fake.followerMetadataMutex.Lock()
 	defer fake.followerMetadataMutex.Unlock()
 	fake.FollowerMetadataStub = stub
 }
 
+func (fake *FakeGatherer) FollowerMetadataArgsForCall(i int) context.Context {
+	fake.followerMetadataMutex.RLock()
+	defer fake.followerMetadataMutex.RUnlock()
+	argsForCall := fake.followerMetadataArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGatherer) FollowerMetadataReturns(result1 map[string]string, result2 map[string]string, result3 error) {
 	fake.followerMetadataMutex.Lock()
 	defer fake.followerMetadataMutex.Unlock()
@@ -521,17 +727,82 @@ func (fake *FakeGatherer) FollowerMetadataReturnsOnCall(i int, result1 map[strin
 	}{result1, result2, result3}
 }
 
-func (fake *FakeGatherer) IsDatabaseAvailable() bool {
+func (fake *FakeGatherer) InnoDBStatus(arg1 context.Context) (map[string]string, error) {
+	fake.innoDBStatusMutex.Lock()
+	ret, specificReturn := fake.innoDBStatusReturnsOnCall[len(fake.innoDBStatusArgsForCall)]
+	fake.innoDBStatusArgsForCall = append(fake.innoDBStatusArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.InnoDBStatusStub
+	fakeReturns := fake.innoDBStatusReturns
+	fake.recordInvocation("InnoDBStatus", []interface{}{arg1})
+	fake.innoDBStatusMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGatherer) InnoDBStatusCallCount() int {
+	fake.innoDBStatusMutex.RLock()
+	defer fake.innoDBStatusMutex.RUnlock()
+	return len(fake.innoDBStatusArgsForCall)
+}
+
+func (fake *FakeGatherer) InnoDBStatusCalls(stub func(context.Context) (map[string]string, error)) {
+	fake.innoDBStatusMutex.Lock()
+	defer fake.innoDBStatusMutex.Unlock()
+	fake.InnoDBStatusStub = stub
+}
+
+func (fake *FakeGatherer) InnoDBStatusArgsForCall(i int) context.Context {
+	fake.innoDBStatusMutex.RLock()
+	defer fake.innoDBStatusMutex.RUnlock()
+	argsForCall := fake.innoDBStatusArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeGatherer) InnoDBStatusReturns(result1 map[string]string, result2 error) {
+	fake.innoDBStatusMutex.Lock()
+	defer fake.innoDBStatusMutex.Unlock()
+	fake.InnoDBStatusStub = nil
+	fake.innoDBStatusReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) InnoDBStatusReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.innoDBStatusMutex.Lock()
+	defer fake.innoDBStatusMutex.Unlock()
+	fake.InnoDBStatusStub = nil
+	if fake.innoDBStatusReturnsOnCall == nil {
+		fake.innoDBStatusReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.innoDBStatusReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) IsDatabaseAvailable(arg1 context.Context) bool {
 	fake.isDatabaseAvailableMutex.Lock()
 	ret, specificReturn := fake.isDatabaseAvailableReturnsOnCall[len(fake.isDatabaseAvailableArgsForCall)]
 	fake.isDatabaseAvailableArgsForCall = append(fake.isDatabaseAvailableArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.IsDatabaseAvailableStub
 	fakeReturns := fake.isDatabaseAvailableReturns
-	fake.recordInvocation("IsDatabaseAvailable", []interface{}{})
+	fake.recordInvocation("IsDatabaseAvailable", []interface{}{arg1})
 	fake.isDatabaseAvailableMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1
@@ -545,12 +816,19 @@ func (fake *FakeGatherer) IsDatabaseAvailableCallCount() int {
 	return len(fake.isDatabaseAvailableArgsForCall)
 }
 
-func (fake *FakeGatherer) IsDatabaseAvailableCalls(stub func() bool) {
+func (fake *FakeGatherer) IsDatabaseAvailableCalls(stub func(context.Context) bool) {
 	fake.isDatabaseAvailableMutex.Lock()
 	defer fake.isDatabaseAvailableMutex.Unlock()
 	fake.IsDatabaseAvailableStub = stub
 }
 
+func (fake *FakeGatherer) IsDatabaseAvailableArgsForCall(i int) context.Context {
+	fake.isDatabaseAvailableMutex.RLock()
+	defer fake.isDatabaseAvailableMutex.RUnlock()
+	argsForCall := fake.isDatabaseAvailableArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGatherer) IsDatabaseAvailableReturns(result1 bool) {
 	fake.isDatabaseAvailableMutex.Lock()
 	defer fake.isDatabaseAvailableMutex.Unlock()
@@ -574,17 +852,18 @@ func (fake *FakeGatherer) IsDatabaseAvailableReturnsOnCall(i int, result1 bool)
 	}{result1}
 }
 
-func (fake *FakeGatherer) IsDatabaseFollower() (bool, error) {
+func (fake *FakeGatherer) IsDatabaseFollower(arg1 context.Context) (bool, error) {
 	fake.isDatabaseFollowerMutex.Lock()
 	ret, specificReturn := fake.isDatabaseFollowerReturnsOnCall[len(fake.isDatabaseFollowerArgsForCall)]
 	fake.isDatabaseFollowerArgsForCall = append(fake.isDatabaseFollowerArgsForCall, struct {
-	}{})
+		arg1 context.Context
+	}{arg1})
 	stub := fake.IsDatabaseFollowerStub
 	fakeReturns := fake.isDatabaseFollowerReturns
-	fake.recordInvocation("IsDatabaseFollower", []interface{}{})
+	fake.recordInvocation("IsDatabaseFollower", []interface{}{arg1})
 	fake.isDatabaseFollowerMutex.Unlock()
 	if stub != nil {
-		return stub()
+		return stub(arg1)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -598,12 +877,19 @@ func (fake *FakeGatherer) IsDatabaseFollowerCallCount() int {
 	return len(fake.isDatabaseFollowerArgsForCall)
 }
 
-func (fake *FakeGatherer) IsDatabaseFollowerCalls(stub func() (bool, error)) {
+func (fake *FakeGatherer) IsDatabaseFollowerCalls(stub func(context.Context) (bool, error)) {
 	fake.isDatabaseFollowerMutex.Lock()
 	defer fake.isDatabaseFollowerMutex.Unlock()
 	fake.IsDatabaseFollowerStub = stub
 }
 
+func (fake *FakeGatherer) IsDatabaseFollowerArgsForCall(i int) context.Context {
+	fake.isDatabaseFollowerMutex.RLock()
+	defer fake.isDatabaseFollowerMutex.RUnlock()
+	argsForCall := fake.isDatabaseFollowerArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FakeGatherer) IsDatabaseFollowerReturns(result1 bool, result2 error) {
 	fake.isDatabaseFollowerMutex.Lock()
 	defer fake.isDatabaseFollowerMutex.Unlock()
@@ -630,27 +916,327 @@ func (fake *FakeGatherer) IsDatabaseFollowerReturnsOnCall(i int, result1 bool, r
 	}{result1, result2}
 }
 
+func (fake *FakeGatherer) LatestInnoDBStatus() *innodbstatus.Status {
+	fake.latestInnoDBStatusMutex.Lock()
+	ret, specificReturn := fake.latestInnoDBStatusReturnsOnCall[len(fake.latestInnoDBStatusArgsForCall)]
+	fake.latestInnoDBStatusArgsForCall = append(fake.latestInnoDBStatusArgsForCall, struct {
+	}{})
+	stub := fake.LatestInnoDBStatusStub
+	fakeReturns := fake.latestInnoDBStatusReturns
+	fake.recordInvocation("LatestInnoDBStatus", []interface{}{})
+	fake.latestInnoDBStatusMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeGatherer) LatestInnoDBStatusCallCount() int {
+	fake.latestInnoDBStatusMutex.RLock()
+	defer fake.latestInnoDBStatusMutex.RUnlock()
+	return len(fake.latestInnoDBStatusArgsForCall)
+}
+
+func (fake *FakeGatherer) LatestInnoDBStatusCalls(stub func() *innodbstatus.Status) {
+	fake.latestInnoDBStatusMutex.Lock()
+	defer fake.latestInnoDBStatusMutex.Unlock()
+	fake.LatestInnoDBStatusStub = stub
+}
+
+func (fake *FakeGatherer) LatestInnoDBStatusReturns(result1 *innodbstatus.Status) {
+	fake.latestInnoDBStatusMutex.Lock()
+	defer fake.latestInnoDBStatusMutex.Unlock()
+	fake.LatestInnoDBStatusStub = nil
+	fake.latestInnoDBStatusReturns = struct {
+		result1 *innodbstatus.Status
+	}{result1}
+}
+
+func (fake *FakeGatherer) LatestInnoDBStatusReturnsOnCall(i int, result1 *innodbstatus.Status) {
+	fake.latestInnoDBStatusMutex.Lock()
+	defer fake.latestInnoDBStatusMutex.Unlock()
+	fake.LatestInnoDBStatusStub = nil
+	if fake.latestInnoDBStatusReturnsOnCall == nil {
+		fake.latestInnoDBStatusReturnsOnCall = make(map[int]struct {
+			result1 *innodbstatus.Status
+		})
+	}
+	fake.latestInnoDBStatusReturnsOnCall[i] = struct {
+		result1 *innodbstatus.Status
+	}{result1}
+}
+
+func (fake *FakeGatherer) MemoryStats(arg1 context.Context, arg2 string, arg3 bool) (map[string]string, error) {
+	fake.memoryStatsMutex.Lock()
+	ret, specificReturn := fake.memoryStatsReturnsOnCall[len(fake.memoryStatsArgsForCall)]
+	fake.memoryStatsArgsForCall = append(fake.memoryStatsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
+	stub := fake.MemoryStatsStub
+	fakeReturns := fake.memoryStatsReturns
+	fake.recordInvocation("MemoryStats", []interface{}{arg1, arg2, arg3})
+	fake.memoryStatsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGatherer) MemoryStatsCallCount() int {
+	fake.memoryStatsMutex.RLock()
+	defer fake.memoryStatsMutex.RUnlock()
+	return len(fake.memoryStatsArgsForCall)
+}
+
+func (fake *FakeGatherer) MemoryStatsCalls(stub func(context.Context, string, bool) (map[string]string, error)) {
+	fake.memoryStatsMutex.Lock()
+	defer fake.memoryStatsMutex.Unlock()
+	fake.MemoryStatsStub = stub
+}
+
+func (fake *FakeGatherer) MemoryStatsArgsForCall(i int) (context.Context, string, bool) {
+	fake.memoryStatsMutex.RLock()
+	defer fake.memoryStatsMutex.RUnlock()
+	argsForCall := fake.memoryStatsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeGatherer) MemoryStatsReturns(result1 map[string]string, result2 error) {
+	fake.memoryStatsMutex.Lock()
+	defer fake.memoryStatsMutex.Unlock()
+	fake.MemoryStatsStub = nil
+	fake.memoryStatsReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) MemoryStatsReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.memoryStatsMutex.Lock()
+	defer fake.memoryStatsMutex.Unlock()
+	fake.MemoryStatsStub = nil
+	if fake.memoryStatsReturnsOnCall == nil {
+		fake.memoryStatsReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.memoryStatsReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) StatementDigests(arg1 context.Context) (digest.Summary, error) {
+	fake.statementDigestsMutex.Lock()
+	ret, specificReturn := fake.statementDigestsReturnsOnCall[len(fake.statementDigestsArgsForCall)]
+	fake.statementDigestsArgsForCall = append(fake.statementDigestsArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.StatementDigestsStub
+	fakeReturns := fake.statementDigestsReturns
+	fake.recordInvocation("StatementDigests", []interface{}{arg1})
+	fake.statementDigestsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGatherer) StatementDigestsCallCount() int {
+	fake.statementDigestsMutex.RLock()
+	defer fake.statementDigestsMutex.RUnlock()
+	return len(fake.statementDigestsArgsForCall)
+}
+
+func (fake *FakeGatherer) StatementDigestsCalls(stub func(context.Context) (digest.Summary, error)) {
+	fake.statementDigestsMutex.Lock()
+	defer fake.statementDigestsMutex.Unlock()
+	fake.StatementDigestsStub = stub
+}
+
+func (fake *FakeGatherer) StatementDigestsArgsForCall(i int) context.Context {
+	fake.statementDigestsMutex.RLock()
+	defer fake.statementDigestsMutex.RUnlock()
+	argsForCall := fake.statementDigestsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeGatherer) StatementDigestsReturns(result1 digest.Summary, result2 error) {
+	fake.statementDigestsMutex.Lock()
+	defer fake.statementDigestsMutex.Unlock()
+	fake.StatementDigestsStub = nil
+	fake.statementDigestsReturns = struct {
+		result1 digest.Summary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) StatementDigestsReturnsOnCall(i int, result1 digest.Summary, result2 error) {
+	fake.statementDigestsMutex.Lock()
+	defer fake.statementDigestsMutex.Unlock()
+	fake.StatementDigestsStub = nil
+	if fake.statementDigestsReturnsOnCall == nil {
+		fake.statementDigestsReturnsOnCall = make(map[int]struct {
+			result1 digest.Summary
+			result2 error
+		})
+	}
+	fake.statementDigestsReturnsOnCall[i] = struct {
+		result1 digest.Summary
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) TableSizes(arg1 context.Context, arg2 bool) ([]tablesize.Table, error) {
+	fake.tableSizesMutex.Lock()
+	ret, specificReturn := fake.tableSizesReturnsOnCall[len(fake.tableSizesArgsForCall)]
+	fake.tableSizesArgsForCall = append(fake.tableSizesArgsForCall, struct {
+		arg1 context.Context
+		arg2 bool
+	}{arg1, arg2})
+	stub := fake.TableSizesStub
+	fakeReturns := fake.tableSizesReturns
+	fake.recordInvocation("TableSizes", []interface{}{arg1, arg2})
+	fake.tableSizesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGatherer) TableSizesCallCount() int {
+	fake.tableSizesMutex.RLock()
+	defer fake.tableSizesMutex.RUnlock()
+	return len(fake.tableSizesArgsForCall)
+}
+
+func (fake *FakeGatherer) TableSizesCalls(stub func(context.Context, bool) ([]tablesize.Table, error)) {
+	fake.tableSizesMutex.Lock()
+	defer fake.tableSizesMutex.Unlock()
+	fake.TableSizesStub = stub
+}
+
+func (fake *FakeGatherer) TableSizesArgsForCall(i int) (context.Context, bool) {
+	fake.tableSizesMutex.RLock()
+	defer fake.tableSizesMutex.RUnlock()
+	argsForCall := fake.tableSizesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGatherer) TableSizesReturns(result1 []tablesize.Table, result2 error) {
+	fake.tableSizesMutex.Lock()
+	defer fake.tableSizesMutex.Unlock()
+	fake.TableSizesStub = nil
+	fake.tableSizesReturns = struct {
+		result1 []tablesize.Table
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) TableSizesReturnsOnCall(i int, result1 []tablesize.Table, result2 error) {
+	fake.tableSizesMutex.Lock()
+	defer fake.tableSizesMutex.Unlock()
+	fake.TableSizesStub = nil
+	if fake.tableSizesReturnsOnCall == nil {
+		fake.tableSizesReturnsOnCall = make(map[int]struct {
+			result1 []tablesize.Table
+			result2 error
+		})
+	}
+	fake.tableSizesReturnsOnCall[i] = struct {
+		result1 []tablesize.Table
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) TransactionStats(arg1 context.Context, arg2 []int) (map[string]string, error) {
+	var arg2Copy []int
+	if arg2 != nil {
+		arg2Copy = make([]int, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.transactionStatsMutex.Lock()
+	ret, specificReturn := fake.transactionStatsReturnsOnCall[len(fake.transactionStatsArgsForCall)]
+	fake.transactionStatsArgsForCall = append(fake.transactionStatsArgsForCall, struct {
+		arg1 context.Context
+		arg2 []int
+	}{arg1, arg2Copy})
+	stub := fake.TransactionStatsStub
+	fakeReturns := fake.transactionStatsReturns
+	fake.recordInvocation("TransactionStats", []interface{}{arg1, arg2Copy})
+	fake.transactionStatsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeGatherer) TransactionStatsCallCount() int {
+	fake.transactionStatsMutex.RLock()
+	defer fake.transactionStatsMutex.RUnlock()
+	return len(fake.transactionStatsArgsForCall)
+}
+
+func (fake *FakeGatherer) TransactionStatsCalls(stub func(context.Context, []int) (map[string]string, error)) {
+	fake.transactionStatsMutex.Lock()
+	defer fake.transactionStatsMutex.Unlock()
+	fake.TransactionStatsStub = stub
+}
+
+func (fake *FakeGatherer) TransactionStatsArgsForCall(i int) (context.Context, []int) {
+	fake.transactionStatsMutex.RLock()
+	defer fake.transactionStatsMutex.RUnlock()
+	argsForCall := fake.transactionStatsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeGatherer) TransactionStatsReturns(result1 map[string]string, result2 error) {
+	fake.transactionStatsMutex.Lock()
+	defer fake.transactionStatsMutex.Unlock()
+	fake.TransactionStatsStub = nil
+	fake.transactionStatsReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeGatherer) TransactionStatsReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.transactionStatsMutex.Lock()
+	defer fake.transactionStatsMutex.Unlock()
+	fake.TransactionStatsStub = nil
+	if fake.transactionStatsReturnsOnCall == nil {
+		fake.transactionStatsReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.transactionStatsReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeGatherer) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.brokerStatsMutex.RLock()
-	defer fake.brokerStatsMutex.RUnlock()
-	fake.cPUStatsMutex.RLock()
-	defer fake.cPUStatsMutex.RUnlock()
-	fake.databaseMetadataMutex.RLock()
-	defer fake.databaseMetadataMutex.RUnlock()
-	fake.diskPerformanceStatsMutex.RLock()
-	defer fake.diskPerformanceStatsMutex.RUnlock()
-	fake.diskStatsMutex.RLock()
-	defer fake.diskStatsMutex.RUnlock()
-	fake.findLastBackupTimestampMutex.RLock()
-	defer fake.findLastBackupTimestampMutex.RUnlock()
-	fake.followerMetadataMutex.RLock()
-	defer fake.followerMetadataMutex.RUnlock()
-	fake.isDatabaseAvailableMutex.RLock()
-	defer fake.isDatabaseAvailableMutex.RUnlock()
-	fake.isDatabaseFollowerMutex.RLock()
-	defer fake.isDatabaseFollowerMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -8,12 +8,13 @@ import (
 )
 
 type FakeSender struct {
-	SendValueStub        func(string, float64, string) error
+	SendValueStub        func(string, float64, string, map[string]string) error
 	sendValueMutex       sync.RWMutex
 	sendValueArgsForCall []struct {
 		arg1 string
 		arg2 float64
 		arg3 string
+		arg4 map[string]string
 	}
 	sendValueReturns struct {
 		result1 error
@@ -25,20 +26,21 @@ type FakeSender struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeSender) SendValue(arg1 string, arg2 float64, arg3 string) error {
+func (fake *FakeSender) SendValue(arg1 string, arg2 float64, arg3 string, arg4 map[string]string) error {
 	fake.sendValueMutex.Lock()
 	ret, specificReturn := fake.sendValueReturnsOnCall[len(fake.sendValueArgsForCall)]
 	fake.sendValueArgsForCall = append(fake.sendValueArgsForCall, struct {
 		arg1 string
 		arg2 float64
 		arg3 string
-	}{arg1, arg2, arg3})
+		arg4 map[string]string
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.SendValueStub
 	fakeReturns := fake.sendValueReturns
-	fake.recordInvocation("SendValue", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("SendValue", []interface{}{arg1, arg2, arg3, arg4})
 	fake.sendValueMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -52,17 +54,17 @@ func (fake *FakeSender) SendValueCallCount() int {
 	return len(fake.sendValueArgsForCall)
 }
 
-func (fake *FakeSender) SendValueCalls(stub func(string, float64, string) error) {
+func (fake *FakeSender) SendValueCalls(stub func(string, float64, string, map[string]string) error) {
 	fake.sendValueMutex.Lock()
 	defer fake.sendValueMutex.Unlock()
 	fake.SendValueStub = stub
 }
 
-func (fake *FakeSender) SendValueArgsForCall(i int) (string, float64, string) {
+func (fake *FakeSender) SendValueArgsForCall(i int) (string, float64, string, map[string]string) {
 	fake.sendValueMutex.RLock()
 	defer fake.sendValueMutex.RUnlock()
 	argsForCall := fake.sendValueArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeSender) SendValueReturns(result1 error) {
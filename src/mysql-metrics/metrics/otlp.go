@@ -0,0 +1,265 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	otlpScopeName     = "mysql-metrics"
+	otlpExportTimeout = 10 * time.Second
+	otlpMetricsPath   = "/v1/metrics"
+)
+
+// otlpUnits are the UCUM units, as OpenTelemetry expects them, of the units
+// metrics are mapped with.
+var otlpUnits = map[string]string{
+	"boolean":        "1",
+	"byte":           "By",
+	"bytes":          "By",
+	"connection":     "{connection}",
+	"event":          "{event}",
+	"file":           "{file}",
+	"float":          "1",
+	"hit":            "{hit}",
+	"integer":        "1",
+	"kb":             "KiBy",
+	"lock":           "{lock}",
+	"megabyte":       "MBy",
+	"metric":         "1",
+	"microsecond":    "us",
+	"millisecond":    "ms",
+	"node":           "{node}",
+	"number":         "1",
+	"ops_per_second": "{ops}/s",
+	"page":           "{page}",
+	"percentage":     "%",
+	"query":          "{query}",
+	"second":         "s",
+	"seconds":        "s",
+	"table":          "{table}",
+	"thread":         "{thread}",
+}
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . OTLPExporter
+type OTLPExporter interface {
+	Export(ctx context.Context, request *colmetricspb.ExportMetricsServiceRequest) error
+}
+
+// OTLPSender collects the values sent during a cycle and exports them to an
+// OpenTelemetry collector as a single batch when flushed.
+type OTLPSender struct {
+	exporter OTLPExporter
+	resource *resourcepb.Resource
+	now      func() time.Time
+
+	mu      sync.Mutex
+	pending []*metricspb.Metric
+}
+
+func NewOTLPSender(exporter OTLPExporter, origin, sourceID, instanceID string) *OTLPSender {
+	return &OTLPSender{
+		exporter: exporter,
+		resource: &resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{
+				stringAttribute("service.name", origin),
+				stringAttribute("service.instance.id", instanceID),
+				stringAttribute("source_id", sourceID),
+			},
+		},
+		now: time.Now,
+	}
+}
+
+func (sender *OTLPSender) SendValue(name string, value float64, unit string, tags map[string]string) error {
+	var attributes []*commonpb.KeyValue
+	for _, key := range slices.Sorted(maps.Keys(tags)) {
+		attributes = append(attributes, stringAttribute(key, tags[key]))
+	}
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.pending = append(sender.pending, &metricspb.Metric{
+		Name: strings.TrimPrefix(name, "/"),
+		Unit: otlpUnit(unit),
+		Data: &metricspb.Metric_Gauge{
+			Gauge: &metricspb.Gauge{
+				DataPoints: []*metricspb.NumberDataPoint{
+					{
+						TimeUnixNano: uint64(sender.now().UnixNano()),
+						Attributes:   attributes,
+						Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: value},
+					},
+				},
+			},
+		},
+	})
+
+	return nil
+}
+
+// Flush exports every value sent since the last flush in one request. Values
+// are dropped after a failed export rather than being retried next cycle.
+func (sender *OTLPSender) Flush() error {
+	sender.mu.Lock()
+	pending := sender.pending
+	sender.pending = nil
+	sender.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	request := &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: sender.resource,
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope:   &commonpb.InstrumentationScope{Name: otlpScopeName},
+						Metrics: pending,
+					},
+				},
+			},
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), otlpExportTimeout)
+	defer cancel()
+
+	if err := sender.exporter.Export(ctx, request); err != nil {
+		return fmt.Errorf("failed to export %d metrics over otlp: %w", len(pending), err)
+	}
+
+	return nil
+}
+
+type OTLPGRPCExporter struct {
+	client colmetricspb.MetricsServiceClient
+}
+
+func NewOTLPGRPCExporter(conn grpc.ClientConnInterface) *OTLPGRPCExporter {
+	return &OTLPGRPCExporter{
+		client: colmetricspb.NewMetricsServiceClient(conn),
+	}
+}
+
+func (e *OTLPGRPCExporter) Export(ctx context.Context, request *colmetricspb.ExportMetricsServiceRequest) error {
+	response, err := e.client.Export(ctx, request)
+	if err != nil {
+		return err
+	}
+
+	return partialSuccessError(response)
+}
+
+type OTLPHTTPExporter struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewOTLPHTTPExporter creates an OTLPHTTPExporter that posts to endpoint. As
+// with other OTLP exporters, an endpoint that is not already the URL of the
+// metrics signal, such as "http://otel:4318", has "/v1/metrics" appended to
+// its path.
+func NewOTLPHTTPExporter(client *http.Client, endpoint string) *OTLPHTTPExporter {
+	return &OTLPHTTPExporter{
+		client:   client,
+		endpoint: otlpMetricsURL(endpoint),
+	}
+}
+
+func otlpMetricsURL(endpoint string) string {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil || strings.HasSuffix(endpointURL.Path, otlpMetricsPath) {
+		return endpoint
+	}
+
+	endpointURL.Path = strings.TrimSuffix(endpointURL.Path, "/") + otlpMetricsPath
+	return endpointURL.String()
+}
+
+func (e *OTLPHTTPExporter) Export(ctx context.Context, request *colmetricspb.ExportMetricsServiceRequest) error {
+	body, err := proto.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/x-protobuf")
+
+	httpResponse, err := e.client.Do(httpRequest)
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	responseBody, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+
+	if httpResponse.StatusCode < 200 || httpResponse.StatusCode > 299 {
+		return fmt.Errorf("otlp endpoint %s returned %s", e.endpoint, httpResponse.Status)
+	}
+
+	response := &colmetricspb.ExportMetricsServiceResponse{}
+	if err := proto.Unmarshal(responseBody, response); err != nil {
+		return fmt.Errorf("failed to decode otlp response: %w", err)
+	}
+
+	return partialSuccessError(response)
+}
+
+func partialSuccessError(response *colmetricspb.ExportMetricsServiceResponse) error {
+	partialSuccess := response.GetPartialSuccess()
+	if partialSuccess.GetRejectedDataPoints() == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("otlp endpoint rejected %d data points: %s",
+		partialSuccess.GetRejectedDataPoints(), partialSuccess.GetErrorMessage())
+}
+
+// otlpUnit returns the UCUM unit of unit. The rates of counters, whose units
+// have a "_per_second" suffix, are per "s".
+func otlpUnit(unit string) string {
+	if converted, ok := otlpUnits[unit]; ok {
+		return converted
+	}
+
+	if base, ok := strings.CutSuffix(unit, "_per_second"); ok {
+		if converted, ok := otlpUnits[base]; ok {
+			return converted + "/s"
+		}
+	}
+
+	return unit
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
@@ -0,0 +1,258 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/metrics/metricsfakes"
+)
+
+type otlpReceiver struct {
+	colmetricspb.UnimplementedMetricsServiceServer
+
+	mu       sync.Mutex
+	requests []*colmetricspb.ExportMetricsServiceRequest
+	response *colmetricspb.ExportMetricsServiceResponse
+}
+
+func (r *otlpReceiver) Export(_ context.Context, request *colmetricspb.ExportMetricsServiceRequest) (*colmetricspb.ExportMetricsServiceResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, request)
+	if r.response != nil {
+		return r.response, nil
+	}
+	return &colmetricspb.ExportMetricsServiceResponse{}, nil
+}
+
+func (r *otlpReceiver) Requests() []*colmetricspb.ExportMetricsServiceRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.requests
+}
+
+var _ = Describe("OTLPSender", func() {
+	var (
+		fakeExporter *metricsfakes.FakeOTLPExporter
+		sender       *metrics.OTLPSender
+	)
+
+	BeforeEach(func() {
+		fakeExporter = new(metricsfakes.FakeOTLPExporter)
+		sender = metrics.NewOTLPSender(fakeExporter, "p-mysql", "source-id", "instance-id")
+	})
+
+	It("does not export anything when no values were sent", func() {
+		Expect(sender.Flush()).To(Succeed())
+		Expect(fakeExporter.ExportCallCount()).To(Equal(0))
+	})
+
+	It("exports every value sent since the last flush as one batch", func() {
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/performance/queries", 42.5, "metric", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(fakeExporter.ExportCallCount()).To(Equal(1))
+		_, request := fakeExporter.ExportArgsForCall(0)
+		Expect(request.ResourceMetrics).To(HaveLen(1))
+
+		resourceMetrics := request.ResourceMetrics[0]
+		attributes := map[string]string{}
+		for _, attribute := range resourceMetrics.Resource.Attributes {
+			attributes[attribute.Key] = attribute.Value.GetStringValue()
+		}
+		Expect(attributes).To(Equal(map[string]string{
+			"service.name":        "p-mysql",
+			"service.instance.id": "instance-id",
+			"source_id":           "source-id",
+		}))
+
+		Expect(resourceMetrics.ScopeMetrics).To(HaveLen(1))
+		Expect(resourceMetrics.ScopeMetrics[0].Scope.Name).To(Equal("mysql-metrics"))
+
+		exported := resourceMetrics.ScopeMetrics[0].Metrics
+		Expect(exported).To(HaveLen(2))
+		Expect(exported[0].Name).To(Equal("p-mysql/available"))
+		Expect(exported[0].Unit).To(Equal("1"))
+		Expect(exported[0].GetGauge().DataPoints).To(HaveLen(1))
+		Expect(exported[0].GetGauge().DataPoints[0].GetAsDouble()).To(Equal(1.0))
+		Expect(exported[0].GetGauge().DataPoints[0].TimeUnixNano).NotTo(BeZero())
+		Expect(exported[1].Name).To(Equal("p-mysql/performance/queries"))
+		Expect(exported[1].GetGauge().DataPoints[0].GetAsDouble()).To(Equal(42.5))
+
+		Expect(sender.Flush()).To(Succeed())
+		Expect(fakeExporter.ExportCallCount()).To(Equal(1))
+	})
+
+	It("exports the tags of a value as attributes of its data point", func() {
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", map[string]string{"target": "replica", "az": "z1"})).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		_, request := fakeExporter.ExportArgsForCall(0)
+		dataPoint := request.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetGauge().DataPoints[0]
+		Expect(dataPoint.Attributes).To(HaveLen(2))
+		Expect(dataPoint.Attributes[0].Key).To(Equal("az"))
+		Expect(dataPoint.Attributes[0].Value.GetStringValue()).To(Equal("z1"))
+		Expect(dataPoint.Attributes[1].Key).To(Equal("target"))
+		Expect(dataPoint.Attributes[1].Value.GetStringValue()).To(Equal("replica"))
+	})
+
+	DescribeTable("exports units as UCUM units",
+		func(unit, expected string) {
+			Expect(sender.SendValue("/p-mysql/metric", 1, unit, nil)).To(Succeed())
+			Expect(sender.Flush()).To(Succeed())
+
+			_, request := fakeExporter.ExportArgsForCall(0)
+			Expect(request.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Unit).To(Equal(expected))
+		},
+		Entry("percentages", "percentage", "%"),
+		Entry("bytes", "byte", "By"),
+		Entry("kilobytes", "kb", "KiBy"),
+		Entry("durations", "millisecond", "ms"),
+		Entry("counts", "query", "{query}"),
+		Entry("dimensionless values", "number", "1"),
+		Entry("rates", "ops_per_second", "{ops}/s"),
+		Entry("the rates of counters", "byte_per_second", "By/s"),
+		Entry("unknown units", "furlong", "furlong"),
+	)
+
+	It("returns export errors and drops the failed batch", func() {
+		fakeExporter.ExportReturnsOnCall(0, errors.New("collector unavailable"))
+
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(MatchError(ContainSubstring("collector unavailable")))
+
+		Expect(sender.SendValue("/p-mysql/available", 0, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		_, request := fakeExporter.ExportArgsForCall(1)
+		Expect(request.ResourceMetrics[0].ScopeMetrics[0].Metrics).To(HaveLen(1))
+	})
+})
+
+var _ = Describe("OTLPGRPCExporter", func() {
+	var (
+		receiver *otlpReceiver
+		exporter *metrics.OTLPGRPCExporter
+	)
+
+	BeforeEach(func() {
+		receiver = &otlpReceiver{}
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		server := grpc.NewServer()
+		colmetricspb.RegisterMetricsServiceServer(server, receiver)
+		go func() { _ = server.Serve(listener) }()
+		DeferCleanup(server.Stop)
+
+		conn, err := grpc.NewClient(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(conn.Close)
+
+		exporter = metrics.NewOTLPGRPCExporter(conn)
+	})
+
+	It("exports batches to an otlp receiver", func() {
+		sender := metrics.NewOTLPSender(exporter, "p-mysql", "source-id", "instance-id")
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(receiver.Requests()).To(HaveLen(1))
+		Expect(receiver.Requests()[0].ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Name).To(Equal("p-mysql/available"))
+	})
+
+	It("returns an error when data points are rejected", func() {
+		receiver.response = &colmetricspb.ExportMetricsServiceResponse{
+			PartialSuccess: &colmetricspb.ExportMetricsPartialSuccess{
+				RejectedDataPoints: 2,
+				ErrorMessage:       "bad points",
+			},
+		}
+
+		err := exporter.Export(context.Background(), &colmetricspb.ExportMetricsServiceRequest{})
+		Expect(err).To(MatchError("otlp endpoint rejected 2 data points: bad points"))
+	})
+})
+
+var _ = Describe("OTLPHTTPExporter", func() {
+	var (
+		receiver   *otlpReceiver
+		statusCode int
+		server     *httptest.Server
+	)
+
+	BeforeEach(func() {
+		receiver = &otlpReceiver{}
+		statusCode = http.StatusOK
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			Expect(r.Method).To(Equal(http.MethodPost))
+			Expect(r.URL.Path).To(Equal("/v1/metrics"))
+			Expect(r.Header.Get("Content-Type")).To(Equal("application/x-protobuf"))
+
+			body, err := io.ReadAll(r.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			request := &colmetricspb.ExportMetricsServiceRequest{}
+			Expect(proto.Unmarshal(body, request)).To(Succeed())
+
+			response, err := receiver.Export(r.Context(), request)
+			Expect(err).NotTo(HaveOccurred())
+
+			responseBody, err := proto.Marshal(response)
+			Expect(err).NotTo(HaveOccurred())
+
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			w.WriteHeader(statusCode)
+			_, _ = w.Write(responseBody)
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	It("posts batches to an otlp receiver", func() {
+		exporter := metrics.NewOTLPHTTPExporter(server.Client(), server.URL+"/v1/metrics")
+		sender := metrics.NewOTLPSender(exporter, "p-mysql", "source-id", "instance-id")
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(receiver.Requests()).To(HaveLen(1))
+		Expect(receiver.Requests()[0].ResourceMetrics[0].ScopeMetrics[0].Metrics[0].Name).To(Equal("p-mysql/available"))
+	})
+
+	It("appends the path of the metrics signal to a base url", func() {
+		exporter := metrics.NewOTLPHTTPExporter(server.Client(), server.URL)
+		Expect(exporter.Export(context.Background(), &colmetricspb.ExportMetricsServiceRequest{})).To(Succeed())
+
+		exporter = metrics.NewOTLPHTTPExporter(server.Client(), server.URL+"/")
+		Expect(exporter.Export(context.Background(), &colmetricspb.ExportMetricsServiceRequest{})).To(Succeed())
+
+		Expect(receiver.Requests()).To(HaveLen(2))
+	})
+
+	It("returns an error for non-success responses", func() {
+		statusCode = http.StatusServiceUnavailable
+
+		exporter := metrics.NewOTLPHTTPExporter(server.Client(), server.URL+"/v1/metrics")
+		err := exporter.Export(context.Background(), &colmetricspb.ExportMetricsServiceRequest{})
+		Expect(err).To(MatchError(ContainSubstring("503 Service Unavailable")))
+	})
+})
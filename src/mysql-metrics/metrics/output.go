@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+const (
+	OutputJSON       = "json"
+	OutputText       = "text"
+	OutputPrometheus = "prom"
+)
+
+// OutputFormats are the formats WriteOutput can print metrics in.
+var OutputFormats = []string{OutputJSON, OutputText, OutputPrometheus}
+
+// OutputWriter keeps the metrics of the most recent cycle instead of sending
+// them, including those whose value could not be parsed, so that they can be
+// printed with WriteOutput.
+type OutputWriter struct {
+	origin string
+
+	mu      sync.Mutex
+	metrics []*Metric
+}
+
+func NewOutputWriter(origin string) *OutputWriter {
+	return &OutputWriter{origin: origin}
+}
+
+func (writer *OutputWriter) Write(metrics []*Metric) error {
+	written := make([]*Metric, 0, len(metrics))
+	for _, metric := range metrics {
+		withOrigin := *metric
+		withOrigin.Key = fmt.Sprintf("/%s/%s", writer.origin, metric.Key)
+		written = append(written, &withOrigin)
+	}
+
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	writer.metrics = written
+	return nil
+}
+
+// Metrics returns the metrics of the most recent cycle, with the origin
+// prepended to their keys as when they are sent.
+func (writer *OutputWriter) Metrics() []*Metric {
+	writer.mu.Lock()
+	defer writer.mu.Unlock()
+
+	return writer.metrics
+}
+
+type outputMetric struct {
+	Key      string            `json:"key"`
+	Value    *float64          `json:"value"`
+	Unit     string            `json:"unit"`
+	RawValue string            `json:"raw_value"`
+	Error    string            `json:"error,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+}
+
+// WriteOutput prints metrics to w in format, one of OutputFormats. The json
+// and text formats describe every metric with its raw value and the error
+// that kept it from being parsed, if any. The prom format prints the metrics
+// that would be served by the prometheus sender, preceded by a comment for
+// every metric that could not be parsed.
+func WriteOutput(w io.Writer, format string, metrics []*Metric) error {
+	switch format {
+	case OutputJSON:
+		return writeJSONOutput(w, metrics)
+	case OutputText:
+		return writeTextOutput(w, metrics)
+	case OutputPrometheus:
+		return writePrometheusOutput(w, metrics)
+	default:
+		return fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(OutputFormats, ", "))
+	}
+}
+
+func writeJSONOutput(w io.Writer, metrics []*Metric) error {
+	output := make([]outputMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		described := outputMetric{
+			Key:      metric.Key,
+			Unit:     metric.Unit,
+			RawValue: metric.RawValue,
+			Tags:     metric.Tags,
+		}
+		if metric.Error != nil {
+			described.Error = metric.Error.Error()
+		} else if !math.IsNaN(metric.Value) && !math.IsInf(metric.Value, 0) {
+			described.Value = &metric.Value
+		}
+		output = append(output, described)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+func writeTextOutput(w io.Writer, metrics []*Metric) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tUNIT\tRAW VALUE\tTAGS\tERROR")
+
+	for _, metric := range metrics {
+		value := strconv.FormatFloat(metric.Value, 'g', -1, 64)
+		errorMessage := ""
+		if metric.Error != nil {
+			value = "-"
+			errorMessage = metric.Error.Error()
+		}
+
+		tags := make([]string, 0, len(metric.Tags))
+		for _, name := range slices.Sorted(maps.Keys(metric.Tags)) {
+			tags = append(tags, name+"="+metric.Tags[name])
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%q\t%s\t%s\n", metric.Key, value, metric.Unit, metric.RawValue, strings.Join(tags, ","), errorMessage)
+	}
+
+	return tw.Flush()
+}
+
+func writePrometheusOutput(w io.Writer, metrics []*Metric) error {
+	sender := NewPrometheusSender(0)
+
+	var b strings.Builder
+	for _, metric := range metrics {
+		if metric.Error != nil {
+			fmt.Fprintf(&b, "# error %s raw_value=%q: %s\n", metric.Key, metric.RawValue, metric.Error)
+			continue
+		}
+		if err := sender.SendValue(metric.Key, metric.Value, metric.Unit, metric.Tags); err != nil {
+			fmt.Fprintf(&b, "# error %s: %s\n", metric.Key, err)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return err
+	}
+
+	_ = sender.Flush()
+	return sender.WriteMetrics(w)
+}
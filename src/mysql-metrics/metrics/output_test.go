@@ -0,0 +1,81 @@
+package metrics_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+)
+
+var _ = Describe("OutputWriter", func() {
+	It("keeps the metrics of the last cycle with the origin prepended to their keys", func() {
+		writer := metrics.NewOutputWriter("p-mysql")
+
+		Expect(writer.Write([]*metrics.Metric{{Key: "available", Value: 1}})).To(Succeed())
+		written := []*metrics.Metric{{Key: "available", Value: 0}, {Key: "variables/read_only", Error: errors.New("could not convert")}}
+		Expect(writer.Write(written)).To(Succeed())
+
+		Expect(writer.Metrics()).To(Equal([]*metrics.Metric{
+			{Key: "/p-mysql/available", Value: 0},
+			{Key: "/p-mysql/variables/read_only", Error: errors.New("could not convert")},
+		}))
+		Expect(written[0].Key).To(Equal("available"))
+	})
+})
+
+var _ = Describe("WriteOutput", func() {
+	var (
+		output       []*metrics.Metric
+		buffer       *bytes.Buffer
+		parseFailure = errors.New("could not convert raw value")
+	)
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		output = []*metrics.Metric{
+			{Key: "/p-mysql/available", Value: 1, Unit: "boolean", RawValue: "1", Tags: map[string]string{"az": "z1", "deployment": "pxc"}},
+			{Key: "/p-mysql/galera/wsrep_cluster_status", Unit: "number", RawValue: "Unknown", Error: parseFailure},
+			{Key: "/p-mysql/performance/queries_rate", Value: math.NaN(), Unit: "metric"},
+		}
+	})
+
+	It("describes every metric as json", func() {
+		Expect(metrics.WriteOutput(buffer, metrics.OutputJSON, output)).To(Succeed())
+
+		Expect(buffer.String()).To(MatchJSON(`[
+			{"key": "/p-mysql/available", "value": 1, "unit": "boolean", "raw_value": "1", "tags": {"az": "z1", "deployment": "pxc"}},
+			{"key": "/p-mysql/galera/wsrep_cluster_status", "value": null, "unit": "number", "raw_value": "Unknown", "error": "could not convert raw value"},
+			{"key": "/p-mysql/performance/queries_rate", "value": null, "unit": "metric", "raw_value": ""}
+		]`))
+	})
+
+	It("describes every metric as a table", func() {
+		Expect(metrics.WriteOutput(buffer, metrics.OutputText, output)).To(Succeed())
+
+		lines := bytes.Split(bytes.TrimSpace(buffer.Bytes()), []byte("\n"))
+		Expect(lines).To(HaveLen(4))
+		Expect(string(lines[0])).To(MatchRegexp(`^KEY\s+VALUE\s+UNIT\s+RAW VALUE\s+TAGS\s+ERROR$`))
+		Expect(string(lines[1])).To(MatchRegexp(`^/p-mysql/available\s+1\s+boolean\s+"1"\s+az=z1,deployment=pxc\s*$`))
+		Expect(string(lines[2])).To(MatchRegexp(`^/p-mysql/galera/wsrep_cluster_status\s+-\s+number\s+"Unknown"\s+could not convert raw value$`))
+		Expect(string(lines[3])).To(MatchRegexp(`^/p-mysql/performance/queries_rate\s+NaN\s+metric\s+""`))
+	})
+
+	It("prints the metrics in the prometheus format, listing those that could not be parsed", func() {
+		Expect(metrics.WriteOutput(buffer, metrics.OutputPrometheus, output)).To(Succeed())
+
+		Expect(buffer.String()).To(Equal(`# error /p-mysql/galera/wsrep_cluster_status raw_value="Unknown": could not convert raw value
+# TYPE p_mysql_available gauge
+p_mysql_available{az="z1",deployment="pxc",unit="boolean"} 1
+# TYPE p_mysql_performance_queries_rate gauge
+p_mysql_performance_queries_rate{unit="metric"} NaN
+`))
+	})
+
+	It("rejects unknown formats", func() {
+		Expect(metrics.WriteOutput(buffer, "yaml", output)).To(MatchError(`unknown output format "yaml", expected one of json, text, prom`))
+	})
+})
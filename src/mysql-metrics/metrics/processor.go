@@ -1,145 +1,422 @@
 package metrics
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/cloudfoundry/mysql-metrics/alert"
 	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/digest"
+	"github.com/cloudfoundry/mysql-metrics/innodbstatus"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Gatherer
 type Gatherer interface {
-	DatabaseMetadata() (globalStatus map[string]string, globalVariables map[string]string, err error)
-	FollowerMetadata() (slaveStatus map[string]string, heartbeatStatus map[string]string, err error)
-	IsDatabaseFollower() (bool, error)
-	IsDatabaseAvailable() bool
+	DatabaseMetadata(ctx context.Context) (globalStatus map[string]string, globalVariables map[string]string, err error)
+	FollowerMetadata(ctx context.Context) (slaveStatus map[string]string, heartbeatStatus map[string]string, err error)
+	IsDatabaseFollower(ctx context.Context) (bool, error)
+	IsDatabaseAvailable(ctx context.Context) bool
 	DiskStats() (map[string]string, error)
 	DiskPerformanceStats() (map[string]string, error)
-	BrokerStats() (map[string]string, error)
-	CPUStats() (map[string]string, error)
-	FindLastBackupTimestamp() (time.Time, error)
+	BrokerStats(ctx context.Context) (map[string]string, error)
+	CPUStats() (map[string]string, []map[string]string, error)
+	MemoryStats(ctx context.Context, pidFile string, databaseAvailable bool) (map[string]string, error)
+	FindLastBackupTimestamp(ctx context.Context) (time.Time, error)
+	CustomQuery(ctx context.Context, query config.CustomQuery) ([]map[string]string, error)
+	StatementDigests(ctx context.Context) (digest.Summary, error)
+	TableSizes(ctx context.Context, includeFileSizes bool) ([]tablesize.Table, error)
+	TransactionStats(ctx context.Context, thresholds []int) (map[string]string, error)
+	InnoDBStatus(ctx context.Context) (map[string]string, error)
+	LatestInnoDBStatus() *innodbstatus.Status
 }
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . MetricsComputer
 type MetricsComputer interface {
-	ComputeAvailabilityMetric(bool) *Metric
-	ComputeIsFollowerMetric(bool) *Metric
-	ComputeGlobalMetrics(map[string]string) []*Metric
-	ComputeLeaderFollowerMetrics(map[string]string) []*Metric
-	ComputeDiskMetrics(map[string]string) []*Metric
-	ComputeBrokerMetrics(map[string]string) []*Metric
-	ComputeGaleraMetrics(map[string]string) []*Metric
-	ComputeCPUMetrics(map[string]string) []*Metric
-	ComputeBackupMetric(time.Time) *Metric
-	ComputeDiskPerformanceMetrics(map[string]string) []*Metric
+	ComputeMetricsFromMapping(map[string]string, map[string]MetricDefinition) []*Metric
 }
 
 type Processor struct {
-	gatherer        Gatherer
-	metricsComputer MetricsComputer
-	metricsWriter   Writer
-	config          *config.Config
+	gatherer          Gatherer
+	state             *atomic.Pointer[processorState]
+	metricsComputer   MetricsComputer
+	metricsWriter     Writer
+	collectorMappings map[string]MetricDefinition
+	telemetryMappings map[string]MetricDefinition
+	alertMappings     map[string]MetricDefinition
+	alerts            *alert.Engine
+	running           *sync.Map
+	telemetry         *telemetry
+	states            *stateTracker
 }
 
 func NewProcessor(
 	gatherer Gatherer,
+	registry *Registry,
 	metricsComputer MetricsComputer,
 	metricsWriter Writer,
 	configuration *config.Config,
+	metricMappingConfig *MetricMappingConfig,
+	alerts *alert.Engine,
 ) Processor {
+	state := &atomic.Pointer[processorState]{}
+	state.Store(newProcessorState(configuration, registry))
+
 	return Processor{
-		gatherer:        gatherer,
-		metricsComputer: metricsComputer,
-		metricsWriter:   metricsWriter,
-		config:          configuration,
+		gatherer:          gatherer,
+		state:             state,
+		metricsComputer:   metricsComputer,
+		metricsWriter:     metricsWriter,
+		collectorMappings: metricMappingConfig.CollectorMetricMappings,
+		telemetryMappings: metricMappingConfig.TelemetryMetricMappings,
+		alertMappings:     metricMappingConfig.AlertMetricMappings,
+		alerts:            alerts,
+		running:           &sync.Map{},
+		telemetry:         newTelemetry(time.Now()),
+		states:            newStateTracker(),
 	}
 }
 
-func (p Processor) Process() error {
+// processorState is the configuration a cycle runs with, which is replaced as a
+// whole when the config is reloaded.
+type processorState struct {
+	config   *config.Config
+	registry *Registry
+	rules    []alert.Rule
+}
+
+func newProcessorState(configuration *config.Config, registry *Registry) *processorState {
+	// Validate rejects configs whose alert rules cannot be parsed.
+	rules, _ := configuration.Rules()
+
+	return &processorState{config: configuration, registry: registry, rules: rules}
+}
+
+// Reload replaces the config and registry used from the next cycle on. A cycle
+// in progress completes with those it started with. Alerts whose rule did not
+// change keep their state.
+func (p Processor) Reload(configuration *config.Config, registry *Registry) {
+	p.state.Store(newProcessorState(configuration, registry))
+}
+
+type collectorResult struct {
+	samples  []Sample
+	err      error
+	timedOut bool
+	duration time.Duration
+}
+
+// Process runs every enabled collector and writes the metrics of those that
+// completed, along with metrics describing each collector and the cycle. When
+// the writer can emit events, it is also given an event for every state
+// metric whose value changed since the previous cycle.
+func (p Processor) Process(ctx context.Context) error {
 	var collectedMetrics []*Metric
 	var collectedErrors error
 
-	if p.config.EmitDiskMetrics {
-		diskStatMap, err := p.gatherer.DiskStats()
-		if err != nil {
-			collectedErrors = errors.Join(collectedErrors, err)
-		}
-		collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeDiskMetrics(diskStatMap)...)
+	start := time.Now()
+	state := p.state.Load()
 
-		diskPerformanceStatMap, err := p.gatherer.DiskPerformanceStats()
-		if err != nil {
-			collectedErrors = errors.Join(collectedErrors, err)
-		}
-		collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeDiskPerformanceMetrics(diskPerformanceStatMap)...)
+	cycle := Cycle{
+		Config:            state.config,
+		DatabaseAvailable: p.gatherer.IsDatabaseAvailable(ctx),
+	}
+	if cycle.DatabaseAvailable {
+		p.telemetry.databaseRead(start)
 	}
 
-	if p.config.EmitBrokerMetrics {
-		brokerStatMap, err := p.gatherer.BrokerStats()
-		if err != nil {
-			collectedErrors = errors.Join(collectedErrors, err)
+	var collectors []Collector
+	for _, c := range state.registry.Collectors() {
+		if c.Enabled(cycle) {
+			collectors = append(collectors, c)
 		}
-		collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeBrokerMetrics(brokerStatMap)...)
 	}
 
-	if p.config.EmitCPUMetrics {
-		cpuStatMap, err := p.gatherer.CPUStats()
-		if err != nil {
-			collectedErrors = errors.Join(collectedErrors, err)
+	results := p.runCollectors(ctx, cycle, collectors)
+	for i, result := range results {
+		for _, sample := range result.samples {
+			collectedMetrics = append(collectedMetrics, p.compute(sample, collectors[i].Mappings())...)
 		}
-		collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeCPUMetrics(cpuStatMap)...)
+
+		name := collectors[i].Name()
+		collectorErrors := p.telemetry.collectorFailed(name, result.err)
+
+		collectedMetrics = append(collectedMetrics, p.compute(Sample{
+			Values: map[string]string{
+				"timed_out": boolValue(result.timedOut),
+				"duration":  milliseconds(result.duration),
+				"errors":    strconv.Itoa(collectorErrors),
+			},
+			Tags: map[string]string{"collector": name},
+		}, p.collectorMappings)...)
+		collectedErrors = errors.Join(collectedErrors, result.err)
 	}
 
-	if p.config.EmitBackupMetrics {
-		backupTimestamp, err := p.gatherer.FindLastBackupTimestamp()
-		if err != nil {
-			collectedErrors = errors.Join(collectedErrors, err)
-		} else {
-			collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeBackupMetric(backupTimestamp))
-		}
+	senderFailures, countsSenderFailures := p.senderFailures()
+	collectedMetrics = append(collectedMetrics, p.compute(Sample{Values: p.telemetryValues(start, collectedMetrics)}, p.telemetryMappings)...)
+
+	tags := state.config.MetricTags()
+	if role := nodeRole(collectors, results); role != "" && state.config.NodeRole == "" {
+		tags = mergeTags(tags, map[string]string{"node_role": role})
+	}
+
+	values := untaggedValues(collectedMetrics)
+	alertMetrics, err := p.evaluateAlerts(state, values, tags, start)
+	collectedMetrics = append(collectedMetrics, alertMetrics...)
+	collectedErrors = errors.Join(collectedErrors, err)
+
+	for _, metric := range collectedMetrics {
+		metric.Tags = mergeTags(tags, metric.Tags)
 	}
 
-	isAvailable := p.gatherer.IsDatabaseAvailable()
-	if p.config.EmitMysqlMetrics {
-		collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeAvailabilityMetric(isAvailable))
+	if err := p.metricsWriter.Write(collectedMetrics); err != nil {
+		collectedErrors = errors.Join(collectedErrors, err)
+	}
 
-		if isAvailable {
-			globalStatus, globalVariables, err := p.gatherer.DatabaseMetadata()
-			if err != nil {
+	if events := p.states.changes(collectedMetrics); len(events) > 0 {
+		if eventWriter, ok := p.metricsWriter.(EventWriter); ok {
+			if err := eventWriter.WriteEvents(events); err != nil {
 				collectedErrors = errors.Join(collectedErrors, err)
 			}
+		}
+	}
 
-			collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeGlobalMetrics(globalStatus)...)
-			collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeGlobalMetrics(globalVariables)...)
+	failuresAfterWrite, _ := p.senderFailures()
+	p.telemetry.record(cycleReport{
+		start:             start,
+		databaseAvailable: cycle.DatabaseAvailable,
+		collectors:        collectors,
+		results:           results,
+		values:            values,
+		err:               collectedErrors,
+		sendFailed:        countsSenderFailures && failuresAfterWrite > senderFailures,
+	}, state.config.UnhealthyCycleLimit(), state.config.ValueExpiry())
 
-			if p.config.EmitGaleraMetrics {
-				collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeGaleraMetrics(globalStatus)...)
-				collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeGaleraMetrics(globalVariables)...)
-			}
+	return collectedErrors
+}
+
+// Status describes the most recent cycles. It may be called concurrently with
+// Process.
+func (p Processor) Status() Status {
+	status := p.telemetry.snapshot()
+	if slices.Contains(status.EnabledCollectors, "innodb_status") {
+		status.InnoDBStatus = p.gatherer.LatestInnoDBStatus()
+	}
+
+	return status
+}
+
+// evaluateAlerts evaluates the alert rules of state against the values of
+// the cycle, returning the state of every alert as metrics when alert metrics
+// are enabled.
+func (p Processor) evaluateAlerts(state *processorState, values map[string]float64, tags map[string]string, now time.Time) ([]*Metric, error) {
+	if p.alerts == nil {
+		return nil, nil
+	}
+
+	states, err := p.alerts.Evaluate(state.rules, values, tags, now)
+	if !state.config.EmitAlertMetrics {
+		return nil, err
+	}
+
+	var alertMetrics []*Metric
+	for _, rule := range state.rules {
+		alertMetrics = append(alertMetrics, p.compute(Sample{
+			Values: map[string]string{"state": alertStateValue(states[rule.Name])},
+			Tags:   map[string]string{"alert": rule.Name},
+		}, p.alertMappings)...)
+	}
+
+	return alertMetrics, err
+}
+
+// alertStateValue is the value the state of an alert is emitted as.
+func alertStateValue(state alert.State) string {
+	switch state {
+	case alert.Firing:
+		return "2"
+	case alert.Pending:
+		return "1"
+	default:
+		return "0"
+	}
+}
+
+// telemetryValues describes the cycle that started at start and collected
+// collectedMetrics.
+func (p Processor) telemetryValues(start time.Time, collectedMetrics []*Metric) map[string]string {
+	var metricErrors int
+	for _, metric := range collectedMetrics {
+		if metric.Error != nil {
+			metricErrors++
 		}
 	}
 
-	if p.config.EmitLeaderFollowerMetrics {
-		isFollower, err := p.gatherer.IsDatabaseFollower()
-		if err != nil {
-			collectedErrors = errors.Join(collectedErrors, err)
+	now := time.Now()
+	values := map[string]string{
+		"cycle_duration":                   milliseconds(now.Sub(start)),
+		"metric_errors":                    strconv.Itoa(metricErrors),
+		"seconds_since_last_database_read": strconv.FormatFloat(p.telemetry.sinceDatabaseRead(now).Seconds(), 'f', 0, 64),
+	}
+	if senderFailures, ok := p.senderFailures(); ok {
+		values["sender_failures"] = strconv.FormatInt(senderFailures, 10)
+	}
+
+	return values
+}
+
+// senderFailures returns how often the writer failed to send metrics, when it
+// counts them.
+func (p Processor) senderFailures() (int64, bool) {
+	counter, ok := p.metricsWriter.(SenderFailureCounter)
+	if !ok {
+		return 0, false
+	}
+
+	return counter.SenderFailures(), true
+}
+
+func (p Processor) compute(sample Sample, collectorMappings map[string]MetricDefinition) []*Metric {
+	mappings := sample.Mappings
+	if mappings == nil {
+		mappings = collectorMappings
+	}
+
+	computed := p.metricsComputer.ComputeMetricsFromMapping(sample.Values, mappings)
+	for _, metric := range computed {
+		metric.Tags = sample.Tags
+	}
+
+	return computed
+}
+
+// untaggedValues returns the values of the metrics that describe the whole
+// node rather than one of the series of a metric, by key. Alert rules and the
+// status are evaluated against them.
+func untaggedValues(collectedMetrics []*Metric) map[string]float64 {
+	values := make(map[string]float64, len(collectedMetrics))
+	for _, metric := range collectedMetrics {
+		if metric.Error == nil && len(metric.Tags) == 0 {
+			values[metric.Key] = metric.Value
 		}
+	}
 
-		collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeIsFollowerMetric(isFollower))
+	return values
+}
 
-		if isFollower {
-			slaveStatus, heartbeatStatus, err := p.gatherer.FollowerMetadata()
-			if err != nil {
-				collectedErrors = errors.Join(collectedErrors, err)
+// mergeTags returns the tags of the config with those of a series added. The
+// tags of the series take precedence.
+func mergeTags(configTags, seriesTags map[string]string) map[string]string {
+	if len(seriesTags) == 0 {
+		return configTags
+	}
+
+	merged := make(map[string]string, len(configTags)+len(seriesTags))
+	maps.Copy(merged, configTags)
+	maps.Copy(merged, seriesTags)
+	return merged
+}
+
+// nodeRole returns "follower" or "leader" as reported by the leader_follower
+// collector, or "" when it did not run or failed.
+func nodeRole(collectors []Collector, results []collectorResult) string {
+	for i, c := range collectors {
+		if c.Name() != "leader_follower" || results[i].err != nil {
+			continue
+		}
+
+		for _, sample := range results[i].samples {
+			switch sample.Values["is_follower"] {
+			case "1":
+				return "follower"
+			case "0":
+				return "leader"
 			}
+		}
+	}
 
-			collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeLeaderFollowerMetrics(heartbeatStatus)...)
-			collectedMetrics = append(collectedMetrics, p.metricsComputer.ComputeLeaderFollowerMetrics(slaveStatus)...)
+	return ""
+}
+
+// runCollectors runs collectors concurrently and returns their results in the
+// same order.
+func (p Processor) runCollectors(ctx context.Context, cycle Cycle, collectors []Collector) []collectorResult {
+	results := make([]collectorResult, len(collectors))
+
+	var wg sync.WaitGroup
+	for i, c := range collectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = p.runCollector(ctx, cycle, c)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runCollector waits for c until its timeout elapses. A collector that does
+// not return in time keeps running in the background, and is skipped by later
+// cycles until it returns so that it never runs twice at once.
+func (p Processor) runCollector(ctx context.Context, cycle Cycle, c Collector) collectorResult {
+	name := c.Name()
+	if _, running := p.running.LoadOrStore(name, struct{}{}); running {
+		return collectorResult{
+			err:      fmt.Errorf("collector %s is still running from a previous cycle", name),
+			timedOut: true,
 		}
 	}
 
-	if err := p.metricsWriter.Write(collectedMetrics); err != nil {
-		collectedErrors = errors.Join(collectedErrors, err)
+	timeout := cycle.Config.CollectorTimeoutDuration()
+	if t, ok := c.(TimeoutCollector); ok {
+		timeout = t.Timeout(cycle)
 	}
 
-	return collectedErrors
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+
+	done := make(chan collectorResult, 1)
+	go func() {
+		defer p.running.Delete(name)
+
+		samples, err := c.Collect(ctx, cycle)
+		done <- collectorResult{samples: samples, err: err}
+	}()
+
+	var result collectorResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = fmt.Errorf("collector %s timed out: %w", name, ctx.Err())
+	}
+	result.timedOut = errors.Is(result.err, context.DeadlineExceeded)
+	result.duration = time.Since(start)
+
+	return result
+}
+
+// countErrors returns the number of errors joined in err.
+func countErrors(err error) int {
+	if err == nil {
+		return 0
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return len(joined.Unwrap())
+	}
+
+	return 1
+}
+
+func milliseconds(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
 }
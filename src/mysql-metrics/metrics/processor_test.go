@@ -1,551 +1,967 @@
 package metrics_test
 
 import (
+	"context"
 	"errors"
+	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	"github.com/cloudfoundry/mysql-metrics/alert"
+	"github.com/cloudfoundry/mysql-metrics/alert/alertfakes"
 	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/digest"
+	"github.com/cloudfoundry/mysql-metrics/innodbstatus"
 	"github.com/cloudfoundry/mysql-metrics/metrics"
 	"github.com/cloudfoundry/mysql-metrics/metrics/metricsfakes"
+	"github.com/cloudfoundry/mysql-metrics/metrics_computer"
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
 )
 
 var _ = Describe("Processor", func() {
 	var (
 		processor           metrics.Processor
+		registry            *metrics.Registry
 		fakeGatherer        *metricsfakes.FakeGatherer
 		fakeMetricsComputer *metricsfakes.FakeMetricsComputer
 		fakeMetricsWriter   *metricsfakes.FakeWriter
+		cpuCollector        *metricsfakes.FakeCollector
+		brokerCollector     *metricsfakes.FakeCollector
 		configuration       *config.Config
+		ctx                 context.Context
 	)
 
+	newCollector := func(name string, mappings map[string]metrics.MetricDefinition) *metricsfakes.FakeCollector {
+		collector := &metricsfakes.FakeCollector{}
+		collector.NameReturns(name)
+		collector.EnabledReturns(true)
+		collector.MappingsReturns(mappings)
+		return collector
+	}
+
+	// computed returns the metrics the fake computer was asked to compute, one
+	// per mapped value, in the order they were computed.
+	computed := func() []metrics.MetricDefinition {
+		var definitions []metrics.MetricDefinition
+		for i := range fakeMetricsComputer.ComputeMetricsFromMappingCallCount() {
+			values, mappings := fakeMetricsComputer.ComputeMetricsFromMappingArgsForCall(i)
+			for name := range values {
+				if mapping, ok := mappings[name]; ok {
+					definitions = append(definitions, mapping)
+				}
+			}
+		}
+		return definitions
+	}
+
 	BeforeEach(func() {
 		fakeGatherer = &metricsfakes.FakeGatherer{}
 		fakeMetricsComputer = &metricsfakes.FakeMetricsComputer{}
 		fakeMetricsWriter = &metricsfakes.FakeWriter{}
 		configuration = &config.Config{}
+		ctx = context.Background()
+
+		cpuCollector = newCollector("cpu", map[string]metrics.MetricDefinition{
+			"cpu_utilization_percent": {Key: "cpu_utilization_percent", Unit: "percentage"},
+		})
+		cpuCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"cpu_utilization_percent": "12"}}}, nil)
+
+		brokerCollector = newCollector("broker", map[string]metrics.MetricDefinition{
+			"service_plans_disk_allocated": {Key: "broker/disk_allocated", Unit: "megabyte"},
+		})
+		brokerCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"service_plans_disk_allocated": "100"}}}, nil)
+
+		registry = metrics.NewRegistry()
+		Expect(registry.Register(brokerCollector)).To(Succeed())
+		Expect(registry.Register(cpuCollector)).To(Succeed())
+
+		fakeMetricsComputer.ComputeMetricsFromMappingStub = func(values map[string]string, mappings map[string]metrics.MetricDefinition) []*metrics.Metric {
+			var computedMetrics []*metrics.Metric
+			for name, mapping := range mappings {
+				if _, ok := values[name]; ok {
+					computedMetrics = append(computedMetrics, &metrics.Metric{Key: mapping.Key, Unit: mapping.Unit})
+				}
+			}
+			return computedMetrics
+		}
+
+		metricMappingConfig := &metrics.MetricMappingConfig{
+			CollectorMetricMappings: map[string]metrics.MetricDefinition{
+				"timed_out": {Key: "collector/timed_out", Unit: "boolean"},
+			},
+		}
 
 		processor = metrics.NewProcessor(
 			fakeGatherer,
+			registry,
 			fakeMetricsComputer,
 			fakeMetricsWriter,
 			configuration,
+			metricMappingConfig,
+			nil,
 		)
 	})
 
 	Describe("Process()", func() {
-		Context("when backup metrics are enabled", func() {
-			BeforeEach(func() {
-				configuration.EmitBackupMetrics = true
-			})
+		It("writes the metrics of every enabled collector along with the collector metrics", func() {
+			Expect(processor.Process(ctx)).To(Succeed())
+
+			Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
+			Expect(fakeMetricsWriter.WriteArgsForCall(0)).To(Equal([]*metrics.Metric{
+				{Key: "broker/disk_allocated", Unit: "megabyte"},
+				{Key: "collector/timed_out", Unit: "boolean", Tags: map[string]string{"collector": "broker"}},
+				{Key: "cpu_utilization_percent", Unit: "percentage"},
+				{Key: "collector/timed_out", Unit: "boolean", Tags: map[string]string{"collector": "cpu"}},
+			}))
+		})
 
-			Context("when there is no error returned from the gatherer", func() {
-				It("returns backup metrics", func() {
-					backupTimestampGathered := time.Now()
-					backupTimestampReturn := float64(backupTimestampGathered.Unix())
+		It("passes the collectors the cycle", func() {
+			fakeGatherer.IsDatabaseAvailableReturns(true)
 
-					backupTimestampMetric := &metrics.Metric{
-						Key:   "last_successful_backup",
-						Value: backupTimestampReturn,
-					}
+			Expect(processor.Process(ctx)).To(Succeed())
 
-					fakeMetricsComputer.ComputeBackupMetricReturns(backupTimestampMetric)
-					fakeGatherer.FindLastBackupTimestampReturns(backupTimestampGathered, nil)
-					err := processor.Process()
-					Expect(err).NotTo(HaveOccurred())
+			cycle := cpuCollector.EnabledArgsForCall(0)
+			Expect(cycle).To(Equal(metrics.Cycle{Config: configuration, DatabaseAvailable: true}))
+			_, cycle = cpuCollector.CollectArgsForCall(0)
+			Expect(cycle).To(Equal(metrics.Cycle{Config: configuration, DatabaseAvailable: true}))
+		})
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(1))
-					Expect(metricsToEmit[0]).To(Equal(backupTimestampMetric))
-				})
-			})
+		It("tags every metric with the tags of the config", func() {
+			configuration.InstanceID = "vm-123456"
+			configuration.Tags = map[string]string{"team": "data"}
 
-			Context("when there is an error returned from the gatherer", func() {
-				It("does not have a metric written", func() {
-					fakeGatherer.FindLastBackupTimestampReturns(time.Time{}, errors.New("some-error"))
-					err := processor.Process()
-					Expect(err).To(HaveOccurred())
-					Expect(err.Error()).To(ContainSubstring("some-error"))
+			Expect(processor.Process(ctx)).To(Succeed())
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(0))
-				})
-			})
+			written := fakeMetricsWriter.WriteArgsForCall(0)
+			Expect(written).NotTo(BeEmpty())
+			for _, metric := range written {
+				Expect(metric.Tags).To(HaveKeyWithValue("instance_id", "vm-123456"), metric.Key)
+				Expect(metric.Tags).To(HaveKeyWithValue("team", "data"), metric.Key)
+			}
+		})
 
+		It("tags the metrics of a sample with its tags along with those of the config", func() {
+			configuration.InstanceID = "vm-123456"
+			cpuCollector.CollectReturns([]metrics.Sample{
+				{Values: map[string]string{"cpu_utilization_percent": "12"}},
+				{Values: map[string]string{"cpu_utilization_percent": "30"}, Tags: map[string]string{"core": "0"}},
+			}, nil)
+
+			Expect(processor.Process(ctx)).To(Succeed())
+
+			written := fakeMetricsWriter.WriteArgsForCall(0)
+			Expect(written).To(ContainElements(
+				&metrics.Metric{Key: "cpu_utilization_percent", Unit: "percentage", Tags: map[string]string{"instance_id": "vm-123456"}},
+				&metrics.Metric{Key: "cpu_utilization_percent", Unit: "percentage", Tags: map[string]string{"instance_id": "vm-123456", "core": "0"}},
+				&metrics.Metric{Key: "collector/timed_out", Unit: "boolean", Tags: map[string]string{"instance_id": "vm-123456", "collector": "cpu"}},
+			))
 		})
 
-		Context("when broker metrics are enabled", func() {
+		Context("with a leader_follower collector", func() {
+			var leaderFollowerCollector *metricsfakes.FakeCollector
+
 			BeforeEach(func() {
-				configuration.EmitBrokerMetrics = true
+				leaderFollowerCollector = newCollector("leader_follower", map[string]metrics.MetricDefinition{
+					"is_follower": {Key: "follower/is_follower", Unit: "boolean"},
+				})
+				leaderFollowerCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"is_follower": "1"}}}, nil)
+				Expect(registry.Register(leaderFollowerCollector)).To(Succeed())
 			})
 
-			It("returns broker metrics", func() {
-				brokerStatsReturn := map[string]string{
-					"service_plans_disk_allocated": "200",
-				}
+			It("tags every metric with the role of the node", func() {
+				Expect(processor.Process(ctx)).To(Succeed())
 
-				servicePlansDiskAllocatedMetric := &metrics.Metric{
-					Key:   "ephemeral_disk_free",
-					Value: 1024,
+				for _, metric := range fakeMetricsWriter.WriteArgsForCall(0) {
+					Expect(metric.Tags).To(HaveKeyWithValue("node_role", "follower"), metric.Key)
 				}
 
-				fakeMetricsComputer.ComputeBrokerMetricsReturnsOnCall(0, []*metrics.Metric{servicePlansDiskAllocatedMetric})
-				fakeGatherer.BrokerStatsReturns(brokerStatsReturn, nil)
-				err := processor.Process()
-				Expect(err).NotTo(HaveOccurred())
+				leaderFollowerCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"is_follower": "0"}}}, nil)
+				Expect(processor.Process(ctx)).To(Succeed())
 
-				Expect(fakeMetricsComputer.ComputeBrokerMetricsCallCount()).To(Equal(1))
-				computeBrokerMetricsArgs := fakeMetricsComputer.ComputeBrokerMetricsArgsForCall(0)
-				Expect(computeBrokerMetricsArgs).To(Equal(brokerStatsReturn))
-
-				Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-				metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-				Expect(len(metricsToEmit)).To(Equal(1))
-				Expect(metricsToEmit[0]).To(Equal(servicePlansDiskAllocatedMetric))
+				for _, metric := range fakeMetricsWriter.WriteArgsForCall(1) {
+					Expect(metric.Tags).To(HaveKeyWithValue("node_role", "leader"), metric.Key)
+				}
 			})
-		})
 
-		Context("when cpu metrics are enabled", func() {
-			BeforeEach(func() {
-				configuration.EmitCPUMetrics = true
-			})
+			It("prefers the configured node role", func() {
+				configuration.NodeRole = "primary"
 
-			It("returns cpu metrics", func() {
-				cpuStatsReturn := map[string]string{
-					"cpu_utilization_percent": "71",
-				}
+				Expect(processor.Process(ctx)).To(Succeed())
 
-				cpuUtilizationMetric := &metrics.Metric{
-					Key:   "cpu_utilization_percent",
-					Value: 71,
+				for _, metric := range fakeMetricsWriter.WriteArgsForCall(0) {
+					Expect(metric.Tags).To(HaveKeyWithValue("node_role", "primary"), metric.Key)
 				}
+			})
 
-				fakeMetricsComputer.ComputeCPUMetricsReturnsOnCall(0, []*metrics.Metric{cpuUtilizationMetric})
-				fakeGatherer.CPUStatsReturns(cpuStatsReturn, nil)
-				err := processor.Process()
-				Expect(err).NotTo(HaveOccurred())
+			It("does not tag the role of the node when it cannot be told", func() {
+				leaderFollowerCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"is_follower": "0"}}}, errors.New("access denied"))
 
-				Expect(fakeMetricsComputer.ComputeCPUMetricsCallCount()).To(Equal(1))
-				computeCPUMetricsArgs := fakeMetricsComputer.ComputeCPUMetricsArgsForCall(0)
-				Expect(computeCPUMetricsArgs).To(Equal(cpuStatsReturn))
+				Expect(processor.Process(ctx)).NotTo(Succeed())
 
-				Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-				metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-				Expect(len(metricsToEmit)).To(Equal(1))
-				Expect(metricsToEmit[0]).To(Equal(cpuUtilizationMetric))
+				for _, metric := range fakeMetricsWriter.WriteArgsForCall(0) {
+					Expect(metric.Tags).NotTo(HaveKey("node_role"), metric.Key)
+				}
 			})
 		})
 
-		Context("when disk metrics are enabled", func() {
-			BeforeEach(func() {
-				configuration.EmitDiskMetrics = true
-			})
+		It("does not run collectors that are not enabled", func() {
+			brokerCollector.EnabledReturns(false)
 
-			It("returns disk metrics", func() {
-				diskStatsReturn := map[string]string{
-					"ephemeral_disk_free": "1024",
-				}
+			Expect(processor.Process(ctx)).To(Succeed())
 
-				diskMetric := &metrics.Metric{
-					Key:   "ephemeral_disk_free",
-					Value: 1024,
-				}
+			Expect(brokerCollector.CollectCallCount()).To(Equal(0))
+			Expect(fakeMetricsWriter.WriteArgsForCall(0)).To(Equal([]*metrics.Metric{
+				{Key: "cpu_utilization_percent", Unit: "percentage"},
+				{Key: "collector/timed_out", Unit: "boolean", Tags: map[string]string{"collector": "cpu"}},
+			}))
+		})
 
-				fakeMetricsComputer.ComputeDiskMetricsReturnsOnCall(0, []*metrics.Metric{diskMetric})
-				fakeGatherer.DiskStatsReturns(diskStatsReturn, nil)
-				err := processor.Process()
-				Expect(err).NotTo(HaveOccurred())
+		It("computes samples with their own mappings", func() {
+			cpuCollector.CollectReturns([]metrics.Sample{
+				{Values: map[string]string{"cpu_utilization_percent": "12"}},
+				{
+					Values:   map[string]string{"steal": "1"},
+					Mappings: map[string]metrics.MetricDefinition{"steal": {Key: "cpu/steal", Unit: "percentage"}},
+				},
+			}, nil)
+
+			Expect(processor.Process(ctx)).To(Succeed())
+
+			Expect(computed()).To(ContainElements(
+				metrics.MetricDefinition{Key: "cpu_utilization_percent", Unit: "percentage"},
+				metrics.MetricDefinition{Key: "cpu/steal", Unit: "percentage"},
+			))
+		})
 
-				Expect(fakeMetricsComputer.ComputeDiskMetricsCallCount()).To(Equal(1))
-				computeDiskMetricsArgs := fakeMetricsComputer.ComputeDiskMetricsArgsForCall(0)
-				Expect(computeDiskMetricsArgs).To(Equal(diskStatsReturn))
+		It("collects errors but emits whatever metrics it can", func() {
+			brokerCollector.CollectReturns(nil, errors.New("broker stats failed"))
+			cpuCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"cpu_utilization_percent": "12"}}}, errors.New("cpu stats failed"))
 
-				Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-				metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-				Expect(len(metricsToEmit)).To(Equal(1))
-				Expect(metricsToEmit[0]).To(Equal(diskMetric))
-			})
+			err := processor.Process(ctx)
+			Expect(err).To(MatchError(ContainSubstring("broker stats failed")))
+			Expect(err).To(MatchError(ContainSubstring("cpu stats failed")))
 
-			It("returns disk performance metrics", func() {
-				fakeGatherer.DiskPerformanceStatsReturns(map[string]string{
-					"persistent_disk_read_latency_ms":  "0.40",
-					"persistent_disk_write_latency_ms": "0.80",
-					"persistent_disk_read_iops":        "2250.00",
-					"persistent_disk_write_iops":       "600.00",
-				}, nil)
-				fakeMetricsComputer.ComputeDiskMetricsReturnsOnCall(0, []*metrics.Metric{
-					{
-						Key:   "persistent_disk_read_latency_ms",
-						Value: 0.40,
-					},
-					{
-						Key:   "persistent_disk_write_latency_ms",
-						Value: 0.80,
-					},
-					{
-						Key:   "persistent_disk_read_iops",
-						Value: 2250.00,
-					},
-					{
-						Key:   "persistent_disk_write_iops",
-						Value: 600.00,
-					},
-				})
+			Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
+			Expect(fakeMetricsWriter.WriteArgsForCall(0)).To(ContainElement(&metrics.Metric{Key: "cpu_utilization_percent", Unit: "percentage"}))
+		})
 
-				err := processor.Process()
-				Expect(err).NotTo(HaveOccurred())
+		It("returns an error if Write returns an error", func() {
+			fakeMetricsWriter.WriteReturns(errors.New("write failed"))
 
-				metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-				Expect(metricsToEmit).To(ConsistOf([]*metrics.Metric{
-					{
-						Key:   "persistent_disk_read_latency_ms",
-						Value: 0.40,
-					},
-					{
-						Key:   "persistent_disk_write_latency_ms",
-						Value: 0.80,
-					},
-					{
-						Key:   "persistent_disk_read_iops",
-						Value: 2250.00,
-					},
-					{
-						Key:   "persistent_disk_write_iops",
-						Value: 600.00,
-					},
-				}))
-			})
+			Expect(processor.Process(ctx)).To(MatchError(ContainSubstring("write failed")))
+		})
+
+		It("queries the database with the context of the cycle", func() {
+			type key struct{}
+			ctx = context.WithValue(ctx, key{}, "cycle")
+
+			Expect(processor.Process(ctx)).To(Succeed())
+			Expect(fakeGatherer.IsDatabaseAvailableArgsForCall(0)).To(Equal(ctx))
+			collectCtx, _ := cpuCollector.CollectArgsForCall(0)
+			Expect(collectCtx.Value(key{})).To(Equal("cycle"))
 		})
 
-		Context("when the database is available", func() {
+		Context("telemetry", func() {
+			// computedValues returns the values of every sample computed with
+			// the mapping named name.
+			computedValues := func(name string) []map[string]string {
+				var computedValues []map[string]string
+				for i := range fakeMetricsComputer.ComputeMetricsFromMappingCallCount() {
+					values, mappings := fakeMetricsComputer.ComputeMetricsFromMappingArgsForCall(i)
+					if _, ok := mappings[name]; ok {
+						computedValues = append(computedValues, values)
+					}
+				}
+				return computedValues
+			}
+
 			BeforeEach(func() {
-				isAvailableReturns := true
-				fakeGatherer.IsDatabaseAvailableReturns(isAvailableReturns)
+				metricMappingConfig := metrics.DefaultMetricMappingConfig()
+				processor = metrics.NewProcessor(fakeGatherer, registry, fakeMetricsComputer, fakeMetricsWriter, configuration, metricMappingConfig, nil)
 			})
 
-			Context("When mysql metrics are enabled", func() {
-				BeforeEach(func() {
-					configuration.EmitMysqlMetrics = true
-				})
+			It("reports the duration and the errors of every collector", func() {
+				brokerCollector.CollectReturns(nil, errors.Join(errors.New("broker stats failed"), errors.New("broker is down")))
 
-				It("emits mysql metrics", func() {
-					isAvailableReturns := true
-					globalStatusReturn := map[string]string{
-						"a": "b",
-					}
-					globalVariablesReturn := map[string]string{
-						"c": "d",
-					}
+				Expect(processor.Process(ctx)).NotTo(Succeed())
+				Expect(processor.Process(ctx)).NotTo(Succeed())
 
-					availabilityMetric := &metrics.Metric{
-						Key:   "Available",
-						Value: 1.0,
-					}
+				collectorValues := computedValues("duration")
+				Expect(collectorValues).To(HaveLen(4))
+				Expect(collectorValues[2]).To(HaveKeyWithValue("errors", "4"))
+				Expect(collectorValues[3]).To(HaveKeyWithValue("errors", "0"))
+				Expect(collectorValues[3]).To(HaveKeyWithValue("duration", MatchRegexp(`^\d+\.\d{3}$`)))
+			})
 
-					globalStatusMetric := &metrics.Metric{
-						Key: "GlobalStatus",
+			It("reports the cycle", func() {
+				fakeGatherer.IsDatabaseAvailableReturns(true)
+				fakeMetricsComputer.ComputeMetricsFromMappingStub = func(values map[string]string, _ map[string]metrics.MetricDefinition) []*metrics.Metric {
+					if values["cpu_utilization_percent"] != "" {
+						return []*metrics.Metric{{Key: "cpu_utilization_percent", Error: errors.New("could not convert raw value")}}
 					}
+					return nil
+				}
 
-					globalVariablesMetric := &metrics.Metric{
-						Key: "GlobalVariables",
-					}
+				Expect(processor.Process(ctx)).To(Succeed())
 
-					fakeGatherer.DatabaseMetadataReturns(globalStatusReturn, globalVariablesReturn, nil)
-					fakeGatherer.IsDatabaseAvailableReturns(isAvailableReturns)
+				telemetryValues := computedValues("cycle_duration")
+				Expect(telemetryValues).To(HaveLen(1))
+				Expect(telemetryValues[0]).To(HaveKeyWithValue("cycle_duration", MatchRegexp(`^\d+\.\d{3}$`)))
+				Expect(telemetryValues[0]).To(HaveKeyWithValue("metric_errors", "1"))
+				Expect(telemetryValues[0]).To(HaveKeyWithValue("seconds_since_last_database_read", "0"))
+				Expect(telemetryValues[0]).NotTo(HaveKey("sender_failures"))
+			})
 
-					fakeMetricsComputer.ComputeAvailabilityMetricReturns(availabilityMetric)
-					fakeMetricsComputer.ComputeGlobalMetricsReturnsOnCall(0, []*metrics.Metric{globalStatusMetric})
-					fakeMetricsComputer.ComputeGlobalMetricsReturnsOnCall(1, []*metrics.Metric{globalVariablesMetric})
+			It("reports the sender failures of writers that count them", func() {
+				writer := &failureCountingWriter{FakeWriter: fakeMetricsWriter, failures: 3}
+				processor = metrics.NewProcessor(fakeGatherer, registry, fakeMetricsComputer, writer, configuration, metrics.DefaultMetricMappingConfig(), nil)
 
-					err := processor.Process()
-					Expect(err).NotTo(HaveOccurred())
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(computedValues("sender_failures")[0]).To(HaveKeyWithValue("sender_failures", "3"))
+			})
+		})
 
-					Expect(fakeGatherer.DatabaseMetadataCallCount()).To(Equal(1))
+		Context("status", func() {
+			BeforeEach(func() {
+				fakeGatherer.IsDatabaseAvailableReturns(true)
+			})
 
-					Expect(fakeMetricsComputer.ComputeAvailabilityMetricCallCount()).To(Equal(1))
-					computeAvailabilityMetricArgs := fakeMetricsComputer.ComputeAvailabilityMetricArgsForCall(0)
-					Expect(computeAvailabilityMetricArgs).To(Equal(isAvailableReturns))
+			It("is healthy before the first cycle", func() {
+				Expect(processor.Status().Healthy).To(BeTrue())
+			})
 
-					Expect(fakeMetricsComputer.ComputeGlobalMetricsCallCount()).To(Equal(2))
-					computeGlobalStatusMetricArgs := fakeMetricsComputer.ComputeGlobalMetricsArgsForCall(0)
-					Expect(computeGlobalStatusMetricArgs).To(Equal(globalStatusReturn))
+			It("describes the last cycle", func() {
+				brokerCollector.CollectReturns(nil, errors.New("broker stats failed"))
+
+				Expect(processor.Process(ctx)).NotTo(Succeed())
+				brokerCollector.CollectReturns([]metrics.Sample{{Values: map[string]string{"service_plans_disk_allocated": "100"}}}, nil)
+				Expect(processor.Process(ctx)).To(Succeed())
+
+				status := processor.Status()
+				Expect(status.Healthy).To(BeTrue())
+				Expect(status.LastCycle).To(BeTemporally("~", time.Now(), time.Second))
+				Expect(status.LastError).To(BeEmpty())
+				Expect(status.DatabaseAvailable).To(BeTrue())
+				Expect(status.EnabledCollectors).To(Equal([]string{"broker", "cpu"}))
+				Expect(status.Collectors).To(Equal(map[string]metrics.CollectorStatus{
+					"broker": {Errors: 1},
+					"cpu":    {},
+				}))
+				Expect(status.Values).To(Equal(map[string]float64{
+					"broker/disk_allocated":   0,
+					"cpu_utilization_percent": 0,
+				}))
+			})
 
-					computeGlobalVariableMetricArgs := fakeMetricsComputer.ComputeGlobalMetricsArgsForCall(1)
-					Expect(computeGlobalVariableMetricArgs).To(Equal(globalVariablesReturn))
+			It("keeps the values of collectors that did not run in the last cycle", func() {
+				configuration.MetricsFrequency = 30
+				Expect(processor.Process(ctx)).To(Succeed())
+				brokerCollector.EnabledReturns(false)
+				Expect(processor.Process(ctx)).To(Succeed())
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(3))
-					Expect(metricsToEmit).To(ContainElement(availabilityMetric))
-					Expect(metricsToEmit).To(ContainElement(globalStatusMetric))
-					Expect(metricsToEmit).To(ContainElement(globalVariablesMetric))
-				})
+				status := processor.Status()
+				Expect(status.EnabledCollectors).To(Equal([]string{"cpu"}))
+				Expect(status.Values).To(HaveKey("broker/disk_allocated"))
 			})
 
-			Context("When galera metrics are enabled", func() {
-				BeforeEach(func() {
-					configuration.EmitMysqlMetrics = true
-					configuration.EmitGaleraMetrics = true
-				})
-
-				It("emits galera metrics", func() {
-					isAvailableReturns := true
-					globalStatusReturn := map[string]string{
-						"wsrep_status": "good",
-					}
-					globalVariablesReturn := map[string]string{
-						"wsrep_enabled": "on",
-					}
+			It("drops the values that were not collected for twice the longest collector interval", func() {
+				Expect(processor.Process(ctx)).To(Succeed())
+				brokerCollector.EnabledReturns(false)
+				Expect(processor.Process(ctx)).To(Succeed())
 
-					availabilityMetric := &metrics.Metric{
-						Key:   "Available",
-						Value: 1.0,
-					}
+				status := processor.Status()
+				Expect(status.Values).NotTo(HaveKey("broker/disk_allocated"))
+				Expect(status.Values).To(HaveKey("cpu_utilization_percent"))
+			})
 
-					globalGaleraStatusMetric := &metrics.Metric{
-						Key: "wsrep_status",
-					}
+			It("includes the latest innodb status while the innodb_status collector is enabled", func() {
+				innoDBStatus := &innodbstatus.Status{
+					HistoryListLength: 42,
+					LatestDeadlock:    &innodbstatus.Deadlock{RolledBack: "2"},
+				}
+				fakeGatherer.LatestInnoDBStatusReturns(innoDBStatus)
+				innoDBCollector := newCollector("innodb_status", nil)
+				Expect(registry.Register(innoDBCollector)).To(Succeed())
 
-					globalGaleraVariablesMetric := &metrics.Metric{
-						Key: "wsrep_enabled",
-					}
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(processor.Status().InnoDBStatus).To(Equal(innoDBStatus))
 
-					globalStatusMetric := &metrics.Metric{
-						Key: "GlobalStatus",
-					}
+				innoDBCollector.EnabledReturns(false)
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(processor.Status().InnoDBStatus).To(BeNil())
+			})
 
-					globalVariablesMetric := &metrics.Metric{
-						Key: "GlobalVariables",
-					}
+			It("becomes unhealthy once the configured number of cycles failed in a row", func() {
+				configuration.UnhealthyCycles = 2
+				cpuCollector.CollectReturns(nil, errors.New("cpu stats failed"))
 
-					fakeGatherer.DatabaseMetadataReturns(globalStatusReturn, globalVariablesReturn, nil)
-					fakeGatherer.IsDatabaseAvailableReturns(isAvailableReturns)
+				Expect(processor.Process(ctx)).NotTo(Succeed())
+				Expect(processor.Status().Healthy).To(BeTrue())
 
-					fakeMetricsComputer.ComputeAvailabilityMetricReturns(availabilityMetric)
-					fakeMetricsComputer.ComputeGaleraMetricsReturnsOnCall(0, []*metrics.Metric{globalGaleraStatusMetric})
-					fakeMetricsComputer.ComputeGaleraMetricsReturnsOnCall(1, []*metrics.Metric{globalGaleraVariablesMetric})
-					fakeMetricsComputer.ComputeGlobalMetricsReturnsOnCall(0, []*metrics.Metric{globalStatusMetric})
-					fakeMetricsComputer.ComputeGlobalMetricsReturnsOnCall(1, []*metrics.Metric{globalVariablesMetric})
+				Expect(processor.Process(ctx)).NotTo(Succeed())
+				status := processor.Status()
+				Expect(status.Healthy).To(BeFalse())
+				Expect(status.ConsecutiveFailures).To(Equal(2))
+				Expect(status.LastError).To(Equal("cpu stats failed"))
+				Expect(status.Collectors["cpu"]).To(Equal(metrics.CollectorStatus{Errors: 2, LastError: "cpu stats failed"}))
 
-					err := processor.Process()
-					Expect(err).NotTo(HaveOccurred())
+				cpuCollector.CollectReturns(nil, nil)
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(processor.Status().Healthy).To(BeTrue())
+				Expect(processor.Status().ConsecutiveFailures).To(BeZero())
+			})
 
-					Expect(fakeGatherer.DatabaseMetadataCallCount()).To(Equal(1))
+			It("counts cycles without the database as failed", func() {
+				fakeGatherer.IsDatabaseAvailableReturns(false)
 
-					Expect(fakeMetricsComputer.ComputeGlobalMetricsCallCount()).To(Equal(2))
-					computeGaleraStatusMetricArgs := fakeMetricsComputer.ComputeGaleraMetricsArgsForCall(0)
-					Expect(computeGaleraStatusMetricArgs).To(Equal(globalStatusReturn))
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(processor.Status().ConsecutiveFailures).To(Equal(1))
+				Expect(processor.Status().DatabaseAvailable).To(BeFalse())
+			})
 
-					computeGaleraVariableMetricArgs := fakeMetricsComputer.ComputeGaleraMetricsArgsForCall(1)
-					Expect(computeGaleraVariableMetricArgs).To(Equal(globalVariablesReturn))
+			It("counts cycles whose metrics could not all be sent as failed", func() {
+				writer := &failureCountingWriter{FakeWriter: fakeMetricsWriter}
+				fakeMetricsWriter.WriteStub = func([]*metrics.Metric) error {
+					writer.failures++
+					return nil
+				}
+				processor = metrics.NewProcessor(fakeGatherer, registry, fakeMetricsComputer, writer, configuration, &metrics.MetricMappingConfig{}, nil)
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(5))
-					Expect(metricsToEmit).To(ContainElement(availabilityMetric))
-					Expect(metricsToEmit).To(ContainElement(globalStatusMetric))
-					Expect(metricsToEmit).To(ContainElement(globalVariablesMetric))
-					Expect(metricsToEmit).To(ContainElement(globalGaleraStatusMetric))
-					Expect(metricsToEmit).To(ContainElement(globalGaleraVariablesMetric))
-				})
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(processor.Status().ConsecutiveFailures).To(Equal(1))
 			})
+		})
 
-			Context("When leader follower metrics are enabled", func() {
-				BeforeEach(func() {
-					configuration.EmitLeaderFollowerMetrics = true
+		Context("state changes", func() {
+			var (
+				writer    *eventRecordingWriter
+				available string
+			)
+
+			BeforeEach(func() {
+				writer = &eventRecordingWriter{FakeWriter: fakeMetricsWriter}
+				configuration.Tags = map[string]string{"az": "z1"}
+
+				stateCollector := newCollector("mysql", map[string]metrics.MetricDefinition{
+					"available":            {Key: "available", Unit: "boolean"},
+					"wsrep_cluster_status": {Key: "galera/wsrep_cluster_status", Unit: "number"},
 				})
+				available = "1"
+				stateCollector.CollectStub = func(context.Context, metrics.Cycle) ([]metrics.Sample, error) {
+					return []metrics.Sample{{Values: map[string]string{"available": available, "wsrep_cluster_status": available}}}, nil
+				}
 
-				It("emits leader follower metrics", func() {
-					isFollowerReturns := true
-					heartbeatStatusReturn := map[string]string{
-						"e": "f",
-					}
+				registry = metrics.NewRegistry()
+				Expect(registry.Register(stateCollector)).To(Succeed())
 
-					slaveStatusReturn := map[string]string{
-						"g": "h",
+				fakeMetricsComputer.ComputeMetricsFromMappingStub = func(values map[string]string, mappings map[string]metrics.MetricDefinition) []*metrics.Metric {
+					var computedMetrics []*metrics.Metric
+					for name, mapping := range mappings {
+						if value, ok := values[name]; ok {
+							floatValue, err := strconv.ParseFloat(value, 64)
+							computedMetrics = append(computedMetrics, &metrics.Metric{Key: mapping.Key, Unit: mapping.Unit, Value: floatValue, Error: err})
+						}
 					}
+					return computedMetrics
+				}
 
-					followerMetric := &metrics.Metric{
-						Key:   "is_follower",
-						Value: 1.0,
-					}
+				processor = metrics.NewProcessor(fakeGatherer, registry, fakeMetricsComputer, writer, configuration, &metrics.MetricMappingConfig{}, nil)
+			})
 
-					heartbeatStatusMetric := &metrics.Metric{
-						Key: "HeartbeatStatus",
-					}
+			It("does not write events before a state metric changes", func() {
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(processor.Process(ctx)).To(Succeed())
 
-					slaveStatusMetric := &metrics.Metric{
-						Key: "SlaveStatus",
-					}
+				Expect(writer.events).To(BeEmpty())
+			})
 
-					fakeGatherer.IsDatabaseFollowerReturns(isFollowerReturns, nil)
-					fakeGatherer.FollowerMetadataReturns(slaveStatusReturn, heartbeatStatusReturn, nil)
+			It("writes an event with the old and new value when a state metric changes", func() {
+				Expect(processor.Process(ctx)).To(Succeed())
+				available = "0"
+				Expect(processor.Process(ctx)).To(Succeed())
+
+				Expect(writer.events).To(ConsistOf(
+					&metrics.Event{
+						Key:      "available",
+						Title:    "node became unavailable",
+						OldValue: 1,
+						NewValue: 0,
+						OldState: "1",
+						NewState: "0",
+						Tags:     map[string]string{"az": "z1"},
+					},
+					&metrics.Event{
+						Key:      "galera/wsrep_cluster_status",
+						Title:    "node left Primary component",
+						OldValue: 1,
+						NewValue: 0,
+						OldState: "PRIMARY",
+						NewState: "NON_PRIMARY",
+						Tags:     map[string]string{"az": "z1"},
+					},
+				))
+			})
 
-					fakeMetricsComputer.ComputeIsFollowerMetricReturns(followerMetric)
-					fakeMetricsComputer.ComputeLeaderFollowerMetricsReturnsOnCall(0, []*metrics.Metric{heartbeatStatusMetric})
-					fakeMetricsComputer.ComputeLeaderFollowerMetricsReturnsOnCall(1, []*metrics.Metric{slaveStatusMetric})
+			It("compares against the last value that could be computed", func() {
+				Expect(processor.Process(ctx)).To(Succeed())
+				available = "unknown"
+				Expect(processor.Process(ctx)).To(Succeed())
+				available = "1"
+				Expect(processor.Process(ctx)).To(Succeed())
 
-					err := processor.Process()
-					Expect(err).NotTo(HaveOccurred())
+				Expect(writer.events).To(BeEmpty())
+			})
+		})
 
-					Expect(fakeMetricsComputer.ComputeLeaderFollowerMetricsCallCount()).To(Equal(2))
+		Context("alerts", func() {
+			var sink *alertfakes.FakeSink
 
-					computeHeartbeatStatusMetricArgs := fakeMetricsComputer.ComputeLeaderFollowerMetricsArgsForCall(0)
-					Expect(computeHeartbeatStatusMetricArgs).To(Equal(heartbeatStatusReturn))
+			BeforeEach(func() {
+				sink = new(alertfakes.FakeSink)
+				configuration.AlertRules = []config.AlertRule{{Name: "cpu_high", Expr: "cpu_utilization_percent > 10"}}
+				configuration.Tags = map[string]string{"az": "z1"}
+
+				fakeMetricsComputer.ComputeMetricsFromMappingStub = func(values map[string]string, mappings map[string]metrics.MetricDefinition) []*metrics.Metric {
+					var computedMetrics []*metrics.Metric
+					for name, mapping := range mappings {
+						if value, ok := values[name]; ok {
+							floatValue, _ := strconv.ParseFloat(value, 64)
+							computedMetrics = append(computedMetrics, &metrics.Metric{Key: mapping.Key, Unit: mapping.Unit, Value: floatValue})
+						}
+					}
+					return computedMetrics
+				}
+			})
 
-					computeSlaveStatusMetricArgs := fakeMetricsComputer.ComputeLeaderFollowerMetricsArgsForCall(1)
-					Expect(computeSlaveStatusMetricArgs).To(Equal(slaveStatusReturn))
+			newAlertingProcessor := func() {
+				processor = metrics.NewProcessor(fakeGatherer, registry, fakeMetricsComputer, fakeMetricsWriter, configuration, &metrics.MetricMappingConfig{
+					AlertMetricMappings: map[string]metrics.MetricDefinition{"state": {Key: "alert/state", Unit: "integer"}},
+				}, alert.NewEngine(sink))
+			}
+
+			It("notifies the sinks of alerts whose rule holds for the metrics of the cycle", func() {
+				newAlertingProcessor()
+				Expect(processor.Process(ctx)).To(Succeed())
+
+				Expect(sink.NotifyCallCount()).To(Equal(1))
+				alerts := sink.NotifyArgsForCall(0)
+				Expect(alerts).To(HaveLen(1))
+				Expect(alerts[0].Rule).To(Equal("cpu_high"))
+				Expect(alerts[0].State).To(Equal(alert.Firing))
+				Expect(alerts[0].Value).To(Equal(12.0))
+				Expect(alerts[0].Tags).To(Equal(map[string]string{"az": "z1"}))
+			})
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(3))
-					Expect(metricsToEmit).To(ContainElement(heartbeatStatusMetric))
-					Expect(metricsToEmit).To(ContainElement(slaveStatusMetric))
-					Expect(metricsToEmit).To(ContainElement(followerMetric))
-				})
+			It("emits the state of every alert when alert metrics are enabled", func() {
+				newAlertingProcessor()
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(fakeMetricsWriter.WriteArgsForCall(0)).NotTo(ContainElement(HaveField("Key", "alert/state")))
+
+				configuration.EmitAlertMetrics = true
+				newAlertingProcessor()
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(fakeMetricsWriter.WriteArgsForCall(1)).To(ContainElement(&metrics.Metric{
+					Key:   "alert/state",
+					Unit:  "integer",
+					Value: 2,
+					Tags:  map[string]string{"az": "z1", "alert": "cpu_high"},
+				}))
 			})
-		})
 
-		Context("when database is not available", func() {
-			BeforeEach(func() {
-				isAvailableReturns := false
-				fakeGatherer.IsDatabaseAvailableReturns(isAvailableReturns)
+			It("evaluates the rules against the metrics without tags of their own", func() {
+				cpuCollector.CollectReturns([]metrics.Sample{
+					{Values: map[string]string{"cpu_utilization_percent": "5"}},
+					{Values: map[string]string{"cpu_utilization_percent": "90"}, Tags: map[string]string{"core": "0"}},
+				}, nil)
+				newAlertingProcessor()
+
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(sink.NotifyCallCount()).To(Equal(0))
 			})
 
-			Context("When mysql metrics are enabled", func() {
-				BeforeEach(func() {
-					configuration.EmitMysqlMetrics = true
-				})
+			It("fails the cycle when a sink cannot be notified", func() {
+				sink.NotifyReturns(errors.New("webhook unavailable"))
+				newAlertingProcessor()
+
+				Expect(processor.Process(ctx)).To(MatchError("webhook unavailable"))
+				Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
+			})
+
+			It("evaluates the rules of a reloaded config", func() {
+				newAlertingProcessor()
+				reloaded := &config.Config{AlertRules: []config.AlertRule{{Name: "cpu_idle", Expr: "cpu_utilization_percent < 10"}}}
+				processor.Reload(reloaded, registry)
+
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(sink.NotifyCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("running collectors", func() {
+			var release chan struct{}
 
-				It("Emits an available=false metric", func() {
-					isAvailable := false
+			// timedOut returns the timed_out value of every collector the
+			// last cycle wrote, by collector name.
+			timedOut := func() map[string]string {
+				values := map[string]string{}
+				for _, metric := range fakeMetricsWriter.WriteArgsForCall(fakeMetricsWriter.WriteCallCount() - 1) {
+					if metric.Key == "collector/timed_out" {
+						values[metric.Tags["collector"]] = strconv.FormatFloat(metric.Value, 'f', -1, 64)
+					}
+				}
+				return values
+			}
 
-					availabilityMetric := &metrics.Metric{
-						Key:   "Available",
-						Value: 0,
+			BeforeEach(func() {
+				fakeMetricsComputer.ComputeMetricsFromMappingStub = func(values map[string]string, mappings map[string]metrics.MetricDefinition) []*metrics.Metric {
+					var computedMetrics []*metrics.Metric
+					for name, mapping := range mappings {
+						if value, ok := values[name]; ok {
+							floatValue, _ := strconv.ParseFloat(value, 64)
+							computedMetrics = append(computedMetrics, &metrics.Metric{Key: mapping.Key, Unit: mapping.Unit, Value: floatValue})
+						}
 					}
+					return computedMetrics
+				}
 
-					fakeGatherer.IsDatabaseAvailableReturns(isAvailable)
-					fakeMetricsComputer.ComputeAvailabilityMetricReturns(availabilityMetric)
+				release = make(chan struct{})
+				blocked := release
+				DeferCleanup(func() { close(blocked) })
+				brokerCollector.CollectStub = func(context.Context, metrics.Cycle) ([]metrics.Sample, error) {
+					<-blocked
+					return nil, nil
+				}
+			})
 
-					err := processor.Process()
-					Expect(err).NotTo(HaveOccurred())
+			It("runs every collector concurrently", func() {
+				cpuCollector.CollectStub = func(context.Context, metrics.Cycle) ([]metrics.Sample, error) {
+					release <- struct{}{}
+					return nil, nil
+				}
 
-					Expect(fakeGatherer.DatabaseMetadataCallCount()).To(Equal(0))
+				Expect(processor.Process(ctx)).To(Succeed())
+				Expect(timedOut()).To(Equal(map[string]string{
+					"broker": "0",
+					"cpu":    "0",
+				}))
+			})
 
-					Expect(fakeMetricsComputer.ComputeAvailabilityMetricCallCount()).To(Equal(1))
-					computeAvailabilityMetricArgs := fakeMetricsComputer.ComputeAvailabilityMetricArgsForCall(0)
-					Expect(computeAvailabilityMetricArgs).To(Equal(isAvailable))
+			It("gives collectors with their own timeout that long", func() {
+				configuration.CollectorTimeout = 60
+				slowCollector := &timeoutCollector{FakeCollector: newCollector("slow", nil), timeout: 50 * time.Millisecond}
+				slowCollector.CollectStub = func(ctx context.Context, _ metrics.Cycle) ([]metrics.Sample, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				brokerCollector.EnabledReturns(false)
+				Expect(registry.Register(slowCollector)).To(Succeed())
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(1))
-					Expect(metricsToEmit[0]).To(Equal(availabilityMetric))
-				})
+				Expect(processor.Process(ctx)).To(MatchError(ContainSubstring("collector slow timed out")))
 			})
 
-			Context("When leader follower metrics are enabled", func() {
+			Context("when a collector does not complete in time", func() {
 				BeforeEach(func() {
-					configuration.EmitLeaderFollowerMetrics = true
-					configuration.EmitMysqlMetrics = true
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+					DeferCleanup(cancel)
 				})
 
-				It("emits is_follower metric", func() {
-					isFollowerReturns := false
-					isAvailableReturns := false
+				It("writes the metrics of the other collectors and reports the timeout", func() {
+					err := processor.Process(ctx)
+					Expect(err).To(MatchError(ContainSubstring("collector broker timed out")))
+					Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
 
-					followerMetric := &metrics.Metric{
-						Key:   "is_follower",
-						Value: 0.0,
-					}
+					Expect(fakeMetricsWriter.WriteArgsForCall(0)).To(ContainElement(&metrics.Metric{Key: "cpu_utilization_percent", Unit: "percentage", Value: 12}))
+					Expect(timedOut()).To(Equal(map[string]string{
+						"broker": "1",
+						"cpu":    "0",
+					}))
+				})
 
-					availabilityMetric := &metrics.Metric{
-						Key:   "Available",
-						Value: 0.0,
-					}
+				It("skips the collector until it returns", func() {
+					Expect(processor.Process(ctx)).NotTo(Succeed())
 
-					fakeGatherer.IsDatabaseFollowerReturns(isFollowerReturns, nil)
-					fakeGatherer.IsDatabaseAvailableReturns(isAvailableReturns)
+					err := processor.Process(context.Background())
+					Expect(err).To(MatchError("collector broker is still running from a previous cycle"))
+					Expect(brokerCollector.CollectCallCount()).To(Equal(1))
+					Expect(cpuCollector.CollectCallCount()).To(Equal(2))
 
-					fakeMetricsComputer.ComputeIsFollowerMetricReturns(followerMetric)
-					fakeMetricsComputer.ComputeAvailabilityMetricReturns(availabilityMetric)
+					brokerCollector.CollectCalls(nil)
+					release <- struct{}{}
+					Eventually(func() error { return processor.Process(context.Background()) }).Should(Succeed())
+					Expect(brokerCollector.CollectCallCount()).To(Equal(2))
+				})
+			})
+		})
+	})
 
-					err := processor.Process()
-					Expect(err).NotTo(HaveOccurred())
+	Describe("Reload()", func() {
+		It("runs the next cycle with the new config and registry", func() {
+			reloaded := &config.Config{EmitCPUMetrics: true}
+			reloadedRegistry := metrics.NewRegistry()
+			Expect(reloadedRegistry.Register(cpuCollector)).To(Succeed())
 
-					Expect(fakeMetricsComputer.ComputeLeaderFollowerMetricsCallCount()).To(Equal(0))
+			processor.Reload(reloaded, reloadedRegistry)
 
-					Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-					metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-					Expect(len(metricsToEmit)).To(Equal(2))
-					Expect(metricsToEmit).To(ContainElement(availabilityMetric))
-					Expect(metricsToEmit).To(ContainElement(followerMetric))
-				})
-			})
+			Expect(processor.Process(ctx)).To(Succeed())
+
+			Expect(brokerCollector.CollectCallCount()).To(Equal(0))
+			_, cycle := cpuCollector.CollectArgsForCall(0)
+			Expect(cycle.Config).To(BeIdenticalTo(reloaded))
 		})
+	})
+})
 
-		Context("error cases", func() {
-			BeforeEach(func() {
-				configuration.EmitGaleraMetrics = true
-				configuration.EmitMysqlMetrics = true
-				configuration.EmitDiskMetrics = true
-				configuration.EmitLeaderFollowerMetrics = true
-			})
-			It("collects errors but emits whatever metrics it can", func() {
-				isAvailable := true
-				isFollower := true
+var _ = Describe("Processor with the default collectors", func() {
+	var (
+		fakeGatherer  *metricsfakes.FakeGatherer
+		fakeWriter    *metricsfakes.FakeWriter
+		configuration *config.Config
+	)
 
-				availabilityMetric := &metrics.Metric{
-					Key:   "Available",
-					Value: 1,
-				}
+	process := func() error {
+		mappings := metrics.DefaultMetricMappingConfig()
+		registry, err := metrics.NewDefaultRegistry(fakeGatherer, mappings, configuration)
+		Expect(err).NotTo(HaveOccurred())
+
+		processor := metrics.NewProcessor(fakeGatherer, registry, metrics_computer.NewMetricsComputer(), fakeWriter, configuration, mappings, nil)
+		return processor.Process(context.Background())
+	}
+
+	// written returns the metrics of the collectors that reached the writer,
+	// leaving out those describing the collectors and the cycle.
+	written := func() []*metrics.Metric {
+		Expect(fakeWriter.WriteCallCount()).To(Equal(1))
+
+		var collected []*metrics.Metric
+		for _, metric := range fakeWriter.WriteArgsForCall(0) {
+			if !strings.HasPrefix(metric.Key, "collector/") && !strings.HasPrefix(metric.Key, "telemetry/") {
+				collected = append(collected, metric)
+			}
+		}
+		return collected
+	}
+
+	// units returns the unit of every written metric by its key.
+	units := func() map[string]string {
+		units := map[string]string{}
+		for _, metric := range written() {
+			units[metric.Key] = metric.Unit
+		}
+		return units
+	}
 
-				followerMetric := &metrics.Metric{
-					Key:   "is_follower",
-					Value: 1,
-				}
+	BeforeEach(func() {
+		fakeGatherer = &metricsfakes.FakeGatherer{}
+		fakeGatherer.IsDatabaseAvailableReturns(true)
+		fakeWriter = &metricsfakes.FakeWriter{}
+		configuration = &config.Config{}
+	})
+
+	DescribeTable("writes the metrics of every category with their units",
+		func(setup func(), expected map[string]string) {
+			setup()
+
+			Expect(process()).To(Succeed())
+			Expect(units()).To(Equal(expected))
+		},
+		Entry("disk", func() {
+			configuration.EmitDiskMetrics = true
+			fakeGatherer.DiskStatsReturns(map[string]string{"persistent_disk_used_percent": "40"}, nil)
+			fakeGatherer.DiskPerformanceStatsReturns(map[string]string{"persistent_disk_read_latency_ms": "2"}, nil)
+		}, map[string]string{
+			"system/persistent_disk_used_percent":    "percentage",
+			"system/persistent_disk_read_latency_ms": "millisecond",
+		}),
+		Entry("broker", func() {
+			configuration.EmitBrokerMetrics = true
+			fakeGatherer.BrokerStatsReturns(map[string]string{"service_plans_disk_allocated": "100"}, nil)
+		}, map[string]string{
+			"broker/disk_allocated_service_plans": "megabyte",
+		}),
+		Entry("cpu", func() {
+			configuration.EmitCPUMetrics = true
+			configuration.EmitCPUCoreMetrics = true
+			fakeGatherer.CPUStatsReturns(
+				map[string]string{"cpu_utilization_percent": "12"},
+				[]map[string]string{{"core": "0", "utilization_percent": "80"}},
+				nil,
+			)
+		}, map[string]string{
+			"performance/cpu_utilization_percent": "percentage",
+			"cpu_core/utilization_percent":        "percentage",
+		}),
+		Entry("memory", func() {
+			configuration.EmitMemoryMetrics = true
+			fakeGatherer.MemoryStatsReturns(map[string]string{"memory_total": "1000"}, nil)
+		}, map[string]string{
+			"system/memory_total": "kb",
+		}),
+		Entry("backup", func() {
+			configuration.EmitBackupMetrics = true
+			fakeGatherer.FindLastBackupTimestampReturns(time.Unix(1700000000, 0), nil)
+		}, map[string]string{
+			"last_successful_backup": "seconds",
+		}),
+		Entry("mysql", func() {
+			configuration.EmitMysqlMetrics = true
+			fakeGatherer.DatabaseMetadataReturns(map[string]string{"threads_connected": "5"}, map[string]string{"max_connections": "100"}, nil)
+		}, map[string]string{
+			"available":                     "boolean",
+			"performance/threads_connected": "connection",
+			"variables/max_connections":     "integer",
+		}),
+		Entry("galera", func() {
+			configuration.EmitMysqlMetrics = true
+			configuration.EmitGaleraMetrics = true
+			fakeGatherer.DatabaseMetadataReturns(map[string]string{"wsrep_cluster_size": "3"}, map[string]string{}, nil)
+		}, map[string]string{
+			"available":                 "boolean",
+			"galera/wsrep_cluster_size": "node",
+		}),
+		Entry("leader_follower", func() {
+			configuration.EmitLeaderFollowerMetrics = true
+			fakeGatherer.IsDatabaseFollowerReturns(true, nil)
+			fakeGatherer.FollowerMetadataReturns(map[string]string{"seconds_behind_master": "2"}, map[string]string{"seconds_since_leader_heartbeat": "1"}, nil)
+		}, map[string]string{
+			"follower/is_follower":                    "boolean",
+			"follower/seconds_behind_master":          "integer",
+			"follower/seconds_since_leader_heartbeat": "integer",
+		}),
+		Entry("transactions", func() {
+			configuration.EmitTransactionMetrics = true
+			fakeGatherer.TransactionStatsReturns(map[string]string{"transactions_open": "2", "transactions_older_than_60": "1"}, nil)
+		}, map[string]string{
+			"transactions/open":          "number",
+			"transactions/older_than_60": "number",
+		}),
+		Entry("innodb_status", func() {
+			configuration.EmitInnoDBStatusMetrics = true
+			fakeGatherer.InnoDBStatusReturns(map[string]string{"history_list_length": "42"}, nil)
+		}, map[string]string{
+			"innodb_status/history_list_length": "number",
+		}),
+		Entry("digest", func() {
+			configuration.EmitDigestMetrics = true
+			fakeGatherer.StatementDigestsReturns(digest.Summary{
+				Elapsed: 30 * time.Second,
+				Digests: []digest.Delta{{Schema: "app", Digest: "abc", Calls: 1, TimerWait: 9000}},
+			}, nil)
+		}, map[string]string{
+			"digest/calls":         "query",
+			"digest/latency":       "millisecond",
+			"digest/avg_latency":   "millisecond",
+			"digest/rows_examined": "number",
+			"digest/errors":        "number",
+		}),
+		Entry("table_size", func() {
+			configuration.EmitTableSizeMetrics = true
+			fakeGatherer.TableSizesReturns([]tablesize.Table{{Schema: "app", Name: "users", DataLength: 1000}}, nil)
+		}, map[string]string{
+			"schema/tables":       "table",
+			"schema/rows":         "number",
+			"schema/data_length":  "byte",
+			"schema/index_length": "byte",
+			"schema/data_free":    "byte",
+			"schema/total_length": "byte",
+			"table/rows":          "number",
+			"table/data_length":   "byte",
+			"table/index_length":  "byte",
+			"table/data_free":     "byte",
+			"table/total_length":  "byte",
+		}),
+		Entry("custom queries", func() {
+			configuration.CustomQueries = []config.CustomQuery{{
+				Name:         "queue_depth",
+				ValueColumns: []config.CustomQueryColumn{{Column: "pending", Unit: "number"}},
+				LabelColumns: []string{"queue"},
+			}}
+			fakeGatherer.CustomQueryReturns([]map[string]string{{"queue": "emails", "pending": "12"}}, nil)
+		}, map[string]string{
+			"custom/queue_depth/pending": "number",
+		}),
+	)
 
-				fakeGatherer.IsDatabaseAvailableReturns(isAvailable)
-				fakeGatherer.IsDatabaseFollowerReturns(isFollower, nil)
-				fakeGatherer.DatabaseMetadataReturns(nil, nil, errors.New("DatabaseMetadata failed"))
-				fakeGatherer.FollowerMetadataReturns(nil, nil, errors.New("FollowerMetadata failed"))
-				fakeGatherer.DiskStatsReturns(nil, errors.New("Disk Stats failed"))
-				fakeMetricsComputer.ComputeAvailabilityMetricReturns(availabilityMetric)
-				fakeMetricsComputer.ComputeIsFollowerMetricReturns(followerMetric)
+	Context("when the database is unavailable", func() {
+		BeforeEach(func() {
+			fakeGatherer.IsDatabaseAvailableReturns(false)
 
-				err := processor.Process()
-				Expect(err.Error()).To(ContainSubstring("DatabaseMetadata failed"))
-				Expect(err.Error()).To(ContainSubstring("FollowerMetadata failed"))
-				Expect(err.Error()).To(ContainSubstring("Disk Stats failed"))
-				Expect(err).ToNot(BeNil())
+			configuration.EmitCPUMetrics = true
+			configuration.EmitMysqlMetrics = true
+			configuration.EmitGaleraMetrics = true
+			configuration.EmitLeaderFollowerMetrics = true
+			configuration.EmitTransactionMetrics = true
+			configuration.EmitInnoDBStatusMetrics = true
+			configuration.EmitDigestMetrics = true
+			configuration.EmitTableSizeMetrics = true
 
-				Expect(fakeMetricsWriter.WriteCallCount()).To(Equal(1))
-				metricsToEmit := fakeMetricsWriter.WriteArgsForCall(0)
-				Expect(len(metricsToEmit)).To(Equal(2))
-				Expect(metricsToEmit).To(ContainElement(availabilityMetric))
-				Expect(metricsToEmit).To(ContainElement(followerMetric))
-			})
+			fakeGatherer.CPUStatsReturns(map[string]string{"cpu_utilization_percent": "12"}, nil, nil)
+		})
 
-			It("returns an error if Write returns an error", func() {
-				fakeMetricsWriter.WriteReturns(errors.New("write failed"))
+		It("writes that it is unavailable along with the metrics that do not need it", func() {
+			Expect(process()).To(Succeed())
+
+			Expect(units()).To(Equal(map[string]string{
+				"available":                           "boolean",
+				"follower/is_follower":                "boolean",
+				"performance/cpu_utilization_percent": "percentage",
+			}))
+			Expect(written()).To(ContainElement(And(
+				HaveField("Key", "available"),
+				HaveField("Value", 0.0),
+			)))
+
+			Expect(fakeGatherer.DatabaseMetadataCallCount()).To(Equal(0))
+			Expect(fakeGatherer.TransactionStatsCallCount()).To(Equal(0))
+			Expect(fakeGatherer.InnoDBStatusCallCount()).To(Equal(0))
+			Expect(fakeGatherer.StatementDigestsCallCount()).To(Equal(0))
+			Expect(fakeGatherer.TableSizesCallCount()).To(Equal(0))
+		})
+	})
 
-				err := processor.Process()
-				Expect(err.Error()).To(ContainSubstring("write failed"))
-				Expect(err).ToNot(BeNil())
-			})
+	Context("when the node is not a follower", func() {
+		BeforeEach(func() {
+			configuration.EmitLeaderFollowerMetrics = true
+			fakeGatherer.IsDatabaseFollowerReturns(false, nil)
+		})
 
-			It("returns an error if IsDatabaseFollower returns an error", func() {
-				fakeGatherer.IsDatabaseAvailableReturns(true)
-				fakeGatherer.IsDatabaseFollowerReturns(false, errors.New("failed to determine follower state"))
+		It("only writes whether it is a follower", func() {
+			Expect(process()).To(Succeed())
 
-				err := processor.Process()
-				Expect(err.Error()).To(ContainSubstring("failed to determine follower state"))
-				Expect(err).ToNot(BeNil())
-			})
+			Expect(units()).To(Equal(map[string]string{"follower/is_follower": "boolean"}))
+			Expect(written()[0].Value).To(Equal(0.0))
+			Expect(fakeGatherer.FollowerMetadataCallCount()).To(Equal(0))
 		})
 	})
 })
+
+type timeoutCollector struct {
+	*metricsfakes.FakeCollector
+	timeout time.Duration
+}
+
+func (c *timeoutCollector) Timeout(metrics.Cycle) time.Duration {
+	return c.timeout
+}
+
+type failureCountingWriter struct {
+	*metricsfakes.FakeWriter
+	failures int64
+}
+
+func (w *failureCountingWriter) SenderFailures() int64 {
+	return w.failures
+}
+
+type eventRecordingWriter struct {
+	*metricsfakes.FakeWriter
+	events []*metrics.Event
+}
+
+func (w *eventRecordingWriter) WriteEvents(events []*metrics.Event) error {
+	w.events = append(w.events, events...)
+	return nil
+}
@@ -0,0 +1,278 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusSender serves the latest value of every series in the Prometheus
+// text exposition format. Values sent with different tags are kept as separate
+// series of the metric. The values sent during a cycle are only served once
+// the cycle is flushed. A series that was not sent again is served until it is
+// older than the max age of the sender, so that the values of collectors that
+// run less often than every cycle, or that failed in the latest one, are still
+// scraped, while a series such as a digest that left the top-N goes away.
+type PrometheusSender struct {
+	mu      sync.RWMutex
+	maxAge  time.Duration
+	now     func() time.Time
+	pending map[string]map[string]float64
+	samples map[string]map[string]prometheusSample
+}
+
+type prometheusSample struct {
+	value float64
+	sent  time.Time
+}
+
+// NewPrometheusSender creates a PrometheusSender that serves a series until
+// no value of it was flushed for maxAge.
+func NewPrometheusSender(maxAge time.Duration) *PrometheusSender {
+	return &PrometheusSender{
+		maxAge:  maxAge,
+		now:     time.Now,
+		pending: make(map[string]map[string]float64),
+		samples: make(map[string]map[string]prometheusSample),
+	}
+}
+
+// SetMaxAge changes how long a series that is not sent again is served from
+// the next flush on.
+func (sender *PrometheusSender) SetMaxAge(maxAge time.Duration) {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	sender.maxAge = maxAge
+}
+
+func (sender *PrometheusSender) SendValue(name string, value float64, unit string, tags map[string]string) error {
+	metricName := PrometheusMetricName(name)
+	if metricName == "" {
+		return fmt.Errorf("metric name %q cannot be converted to a prometheus metric name", name)
+	}
+
+	labels := prometheusLabels(unit, tags)
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	series, ok := sender.pending[metricName]
+	if !ok {
+		series = make(map[string]float64)
+		sender.pending[metricName] = series
+	}
+	series[labels] = value
+	return nil
+}
+
+// Flush serves the values sent since the last flush in place of the earlier
+// values of their series, and stops serving the series older than the max age.
+func (sender *PrometheusSender) Flush() error {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	now := sender.now()
+	for name, pending := range sender.pending {
+		series, ok := sender.samples[name]
+		if !ok {
+			series = make(map[string]prometheusSample, len(pending))
+			sender.samples[name] = series
+		}
+		for labels, value := range pending {
+			series[labels] = prometheusSample{value: value, sent: now}
+		}
+	}
+	sender.pending = make(map[string]map[string]float64)
+
+	for name, series := range sender.samples {
+		maps.DeleteFunc(series, func(_ string, sample prometheusSample) bool {
+			return now.Sub(sample.sent) > sender.maxAge
+		})
+		if len(series) == 0 {
+			delete(sender.samples, name)
+		}
+	}
+
+	return nil
+}
+
+func (sender *PrometheusSender) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	_ = sender.WriteMetrics(w)
+}
+
+// WriteMetrics writes every metric that is served as a gauge, sorted by
+// metric name.
+func (sender *PrometheusSender) WriteMetrics(w io.Writer) error {
+	merged := make(map[string]map[string]float64)
+	sender.copySamples(merged)
+
+	return writePrometheusSamples(w, merged)
+}
+
+// copySamples copies the value of every series that is served into merged.
+func (sender *PrometheusSender) copySamples(merged map[string]map[string]float64) {
+	sender.mu.RLock()
+	defer sender.mu.RUnlock()
+
+	for name, series := range sender.samples {
+		if merged[name] == nil {
+			merged[name] = make(map[string]float64, len(series))
+		}
+		for labels, sample := range series {
+			merged[name][labels] = sample.value
+		}
+	}
+}
+
+// PrometheusHandler serves the values of every sender it created as one
+// exposition, so that each of them can be flushed independently of the
+// others.
+type PrometheusHandler struct {
+	mu      sync.Mutex
+	maxAge  time.Duration
+	senders []*PrometheusSender
+}
+
+// NewPrometheusHandler creates a PrometheusHandler whose senders serve a
+// series until no value of it was flushed for maxAge.
+func NewPrometheusHandler(maxAge time.Duration) *PrometheusHandler {
+	return &PrometheusHandler{maxAge: maxAge}
+}
+
+// NewSender returns a sender whose values the handler serves along with those
+// of its other senders.
+func (h *PrometheusHandler) NewSender() *PrometheusSender {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sender := NewPrometheusSender(h.maxAge)
+	h.senders = append(h.senders, sender)
+	return sender
+}
+
+// SetMaxAge changes the max age of every sender of the handler.
+func (h *PrometheusHandler) SetMaxAge(maxAge time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.maxAge = maxAge
+	for _, sender := range h.senders {
+		sender.SetMaxAge(maxAge)
+	}
+}
+
+func (h *PrometheusHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", prometheusContentType)
+	_ = h.WriteMetrics(w)
+}
+
+// WriteMetrics writes the metrics served by every sender, with the series of
+// the same metric from different senders grouped under one metric.
+func (h *PrometheusHandler) WriteMetrics(w io.Writer) error {
+	h.mu.Lock()
+	senders := slices.Clone(h.senders)
+	h.mu.Unlock()
+
+	merged := make(map[string]map[string]float64)
+	for _, sender := range senders {
+		sender.copySamples(merged)
+	}
+
+	return writePrometheusSamples(w, merged)
+}
+
+// writePrometheusSamples writes every metric of samples as a gauge, sorted by
+// metric name.
+func writePrometheusSamples(w io.Writer, samples map[string]map[string]float64) error {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(samples)) {
+		series := samples[name]
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", name)
+		for _, labels := range slices.Sorted(maps.Keys(series)) {
+			fmt.Fprintf(&b, "%s%s %s\n", name, labels, formatPrometheusValue(series[labels]))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// PrometheusMetricName converts a slash separated metric key such as
+// "/p-mysql/innodb/data_read" into a valid Prometheus metric name such as
+// "p_mysql_innodb_data_read".
+func PrometheusMetricName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.Trim(key, "/") {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+
+	name := b.String()
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+
+	return name
+}
+
+// prometheusLabels formats the unit and tags of a value as the label set of
+// its series, sorted by label name.
+func prometheusLabels(unit string, tags map[string]string) string {
+	labels := make(map[string]string, len(tags)+1)
+	for key, value := range tags {
+		if name := prometheusLabelName(key); name != "" {
+			labels[name] = value
+		}
+	}
+	if unit != "" {
+		labels["unit"] = unit
+	}
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, fmt.Sprintf("%s=\"%s\"", name, escapePrometheusLabelValue(labels[name])))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// prometheusLabelName converts a tag name into a valid Prometheus label name,
+// which unlike a metric name may not contain colons.
+func prometheusLabelName(key string) string {
+	return strings.ReplaceAll(PrometheusMetricName(key), ":", "_")
+}
+
+func formatPrometheusValue(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+func escapePrometheusLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,226 @@
+package metrics_test
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+)
+
+var _ = Describe("PrometheusSender", func() {
+	var (
+		sender *metrics.PrometheusSender
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		sender = metrics.NewPrometheusSender(0)
+		server = httptest.NewServer(sender)
+		DeferCleanup(server.Close)
+	})
+
+	scrape := func() string {
+		resp, err := http.Get(server.URL + "/metrics")
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return string(body)
+	}
+
+	It("serves nothing before any metric was sent", func() {
+		Expect(scrape()).To(BeEmpty())
+	})
+
+	It("serves every metric as a gauge sorted by name with its unit as a label", func() {
+		Expect(sender.SendValue("/p-mysql/performance/queries", 42, "metric", nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_available gauge
+p_mysql_available{unit="boolean"} 1
+# TYPE p_mysql_performance_queries gauge
+p_mysql_performance_queries{unit="metric"} 42
+`))
+	})
+
+	It("keeps only the latest value of a metric", func() {
+		Expect(sender.SendValue("/p-mysql/performance/queries", 42, "metric", nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/performance/queries", 43.5, "metric", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_performance_queries gauge
+p_mysql_performance_queries{unit="metric"} 43.5
+`))
+	})
+
+	It("serves the values of a cycle once it is flushed", func() {
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+
+		Expect(scrape()).To(BeEmpty())
+	})
+
+	It("only serves the series sent in the most recent cycle without a max age", func() {
+		Expect(sender.SendValue("/p-mysql/digest/calls", 4, "query", map[string]string{"digest": "abc"})).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/digest/calls", 2, "query", map[string]string{"digest": "def"})).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(sender.SendValue("/p-mysql/digest/calls", 5, "query", map[string]string{"digest": "abc"})).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_digest_calls gauge
+p_mysql_digest_calls{digest="abc",unit="query"} 5
+`))
+	})
+
+	It("serves the series that were not sent again until they are older than the max age", func() {
+		sender = metrics.NewPrometheusSender(time.Hour)
+		server.Config.Handler = sender
+
+		Expect(sender.SendValue("/p-mysql/table/data_length", 1000, "byte", map[string]string{"table": "users"})).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(sender.SendValue("/p-mysql/available", 0, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_available gauge
+p_mysql_available{unit="boolean"} 0
+# TYPE p_mysql_table_data_length gauge
+p_mysql_table_data_length{table="users",unit="byte"} 1000
+`))
+	})
+
+	It("stops serving a series once it is older than the max age", func() {
+		sender = metrics.NewPrometheusSender(10 * time.Millisecond)
+		server.Config.Handler = sender
+
+		Expect(sender.SendValue("/p-mysql/table/data_length", 1000, "byte", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		time.Sleep(20 * time.Millisecond)
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(BeEmpty())
+	})
+
+	It("keeps values sent with different tags as separate series", func() {
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", map[string]string{"target": "primary", "az": "z1"})).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/available", 0, "boolean", map[string]string{"target": "replica", "az": "z1"})).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_available gauge
+p_mysql_available{az="z1",target="primary",unit="boolean"} 1
+p_mysql_available{az="z1",target="replica",unit="boolean"} 0
+`))
+	})
+
+	It("converts tag names into valid label names", func() {
+		Expect(sender.SendValue("/p-mysql/available", 1, "", map[string]string{"bosh.deployment:name": "pxc"})).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring(`p_mysql_available{bosh_deployment_name="pxc"} 1`))
+	})
+
+	It("omits the unit label when there is no unit", func() {
+		Expect(sender.SendValue("/p-mysql/available", 0, "", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring("p_mysql_available 0\n"))
+	})
+
+	It("escapes label values and formats special float values", func() {
+		Expect(sender.SendValue("/p-mysql/a", math.NaN(), `we"ird\unit`, nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/b", math.Inf(1), "", nil)).To(Succeed())
+		Expect(sender.SendValue("/p-mysql/c", math.Inf(-1), "", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		output := scrape()
+		Expect(output).To(ContainSubstring(`p_mysql_a{unit="we\"ird\\unit"} NaN`))
+		Expect(output).To(ContainSubstring("p_mysql_b +Inf"))
+		Expect(output).To(ContainSubstring("p_mysql_c -Inf"))
+	})
+
+	It("returns an error for names that cannot be converted", func() {
+		Expect(sender.SendValue("///", 1, "", nil)).To(MatchError(ContainSubstring("cannot be converted")))
+	})
+
+	DescribeTable("PrometheusMetricName",
+		func(key, expected string) {
+			Expect(metrics.PrometheusMetricName(key)).To(Equal(expected))
+		},
+		Entry("slashes and dashes", "/p-mysql/innodb/data_read", "p_mysql_innodb_data_read"),
+		Entry("without a leading slash", "system/persistent_disk_used", "system_persistent_disk_used"),
+		Entry("a leading digit", "/1st/metric", "_1st_metric"),
+		Entry("colons are kept", "/a:b/c", "a:b_c"),
+		Entry("other characters", "/p.mysql/some metric", "p_mysql_some_metric"),
+	)
+})
+
+var _ = Describe("PrometheusHandler", func() {
+	var handler *metrics.PrometheusHandler
+
+	BeforeEach(func() {
+		handler = metrics.NewPrometheusHandler(0)
+	})
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		Expect(recorder.Header().Get("Content-Type")).To(HavePrefix("text/plain; version=0.0.4"))
+		return recorder.Body.String()
+	}
+
+	It("serves the series of every sender grouped by metric", func() {
+		primary := handler.NewSender()
+		replica := handler.NewSender()
+
+		Expect(primary.SendValue("/p-mysql/available", 1, "boolean", map[string]string{"target": "primary"})).To(Succeed())
+		Expect(primary.SendValue("/p-mysql/performance/queries", 42, "metric", map[string]string{"target": "primary"})).To(Succeed())
+		Expect(replica.SendValue("/p-mysql/available", 0, "boolean", map[string]string{"target": "replica"})).To(Succeed())
+		Expect(primary.Flush()).To(Succeed())
+		Expect(replica.Flush()).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_available gauge
+p_mysql_available{target="primary",unit="boolean"} 1
+p_mysql_available{target="replica",unit="boolean"} 0
+# TYPE p_mysql_performance_queries gauge
+p_mysql_performance_queries{target="primary",unit="metric"} 42
+`))
+	})
+
+	It("serves the most recent cycle of every sender, however far along the others are", func() {
+		primary := handler.NewSender()
+		replica := handler.NewSender()
+
+		Expect(primary.SendValue("/p-mysql/available", 1, "boolean", map[string]string{"target": "primary"})).To(Succeed())
+		Expect(primary.Flush()).To(Succeed())
+		Expect(replica.SendValue("/p-mysql/available", 0, "boolean", map[string]string{"target": "replica"})).To(Succeed())
+
+		Expect(scrape()).To(Equal(`# TYPE p_mysql_available gauge
+p_mysql_available{target="primary",unit="boolean"} 1
+`))
+	})
+
+	It("applies a new max age to every sender", func() {
+		sender := handler.NewSender()
+		handler.SetMaxAge(time.Hour)
+
+		Expect(sender.SendValue("/p-mysql/available", 1, "boolean", nil)).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+		Expect(sender.Flush()).To(Succeed())
+
+		Expect(scrape()).To(ContainSubstring(`p_mysql_available{unit="boolean"} 1`))
+	})
+})
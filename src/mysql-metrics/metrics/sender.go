@@ -1,30 +1,95 @@
 package metrics
 
 import (
+	"context"
+	"errors"
+
 	"code.cloudfoundry.org/go-loggregator/v9"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Sender
 type Sender interface {
-	SendValue(name string, value float64, unit string) error
+	SendValue(name string, value float64, unit string, tags map[string]string) error
+}
+
+// Flusher is implemented by senders that batch the values they are sent and
+// need to be told once every metric of a cycle has been sent.
+type Flusher interface {
+	Flush() error
 }
 
 type LoggregatorSender struct {
-	client   *loggregator.IngressClient
-	sourceID string
+	client     *loggregator.IngressClient
+	sourceID   string
+	instanceID string
 }
 
-func NewLoggregatorSender(client *loggregator.IngressClient, sourceID string) *LoggregatorSender {
+func NewLoggregatorSender(client *loggregator.IngressClient, sourceID, instanceID string) *LoggregatorSender {
 	return &LoggregatorSender{
-		client:   client,
-		sourceID: sourceID,
+		client:     client,
+		sourceID:   sourceID,
+		instanceID: instanceID,
 	}
 }
 
-func (sender *LoggregatorSender) SendValue(name string, value float64, unit string) error {
+func (sender *LoggregatorSender) SendValue(name string, value float64, unit string, tags map[string]string) error {
 	sender.client.EmitGauge(
-		loggregator.WithGaugeSourceInfo(sender.sourceID, ""),
+		loggregator.WithGaugeSourceInfo(sender.sourceID, sender.instanceID),
 		loggregator.WithGaugeValue(name, value, unit),
+		loggregator.WithEnvelopeTags(tags),
 	)
 	return nil
 }
+
+// SendEvent emits an event envelope, waiting for the agent to accept it.
+func (sender *LoggregatorSender) SendEvent(title, body string, tags map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), loggregatorSendTimeout)
+	defer cancel()
+
+	return sender.client.EmitEvent(ctx, title, body,
+		loggregator.WithEventSourceInfo(sender.sourceID, sender.instanceID),
+		loggregator.WithEnvelopeTags(tags),
+	)
+}
+
+// MultiSender forwards every value to each of its senders, so that metrics can
+// be emitted to more than one destination at once.
+type MultiSender []Sender
+
+func (senders MultiSender) SendValue(name string, value float64, unit string, tags map[string]string) error {
+	var errs []error
+	for _, sender := range senders {
+		if err := sender.SendValue(name, value, unit, tags); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SendEvent forwards the event to each of its senders that can emit events.
+func (senders MultiSender) SendEvent(title, body string, tags map[string]string) error {
+	var errs []error
+	for _, sender := range senders {
+		if eventSender, ok := sender.(EventSender); ok {
+			if err := eventSender.SendEvent(title, body, tags); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (senders MultiSender) Flush() error {
+	var errs []error
+	for _, sender := range senders {
+		if flusher, ok := sender.(Flusher); ok {
+			if err := flusher.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
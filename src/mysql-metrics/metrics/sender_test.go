@@ -0,0 +1,68 @@
+package metrics_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/metrics/metricsfakes"
+)
+
+var _ = Describe("MultiSender", func() {
+	var (
+		sender1 *metricsfakes.FakeSender
+		sender2 *metricsfakes.FakeSender
+		sender  metrics.MultiSender
+	)
+
+	BeforeEach(func() {
+		sender1 = new(metricsfakes.FakeSender)
+		sender2 = new(metricsfakes.FakeSender)
+		sender = metrics.MultiSender{sender1, sender2}
+	})
+
+	It("sends the value to every sender", func() {
+		Expect(sender.SendValue("/origin/key", 1.5, "unit", map[string]string{"target": "a"})).To(Succeed())
+
+		Expect(sender1.SendValueCallCount()).To(Equal(1))
+		name, value, unit, tags := sender1.SendValueArgsForCall(0)
+		Expect(name).To(Equal("/origin/key"))
+		Expect(value).To(Equal(1.5))
+		Expect(unit).To(Equal("unit"))
+		Expect(tags).To(Equal(map[string]string{"target": "a"}))
+
+		Expect(sender2.SendValueCallCount()).To(Equal(1))
+		name, value, unit, tags = sender2.SendValueArgsForCall(0)
+		Expect(name).To(Equal("/origin/key"))
+		Expect(value).To(Equal(1.5))
+		Expect(unit).To(Equal("unit"))
+		Expect(tags).To(Equal(map[string]string{"target": "a"}))
+	})
+
+	It("keeps sending when a sender fails and returns its error", func() {
+		sender1.SendValueReturns(errors.New("sender1 broke"))
+
+		err := sender.SendValue("/origin/key", 1.5, "unit", nil)
+		Expect(err).To(MatchError(ContainSubstring("sender1 broke")))
+		Expect(sender2.SendValueCallCount()).To(Equal(1))
+	})
+
+	It("flushes the senders that batch values", func() {
+		flushing := &flushingSender{flushErr: errors.New("flush broke")}
+		sender = metrics.MultiSender{sender1, flushing}
+
+		Expect(sender.Flush()).To(MatchError("flush broke"))
+		Expect(flushing.flushCount).To(Equal(1))
+	})
+
+	It("sends events to the senders that emit them", func() {
+		events := &eventSender{}
+		sender = metrics.MultiSender{sender1, events}
+
+		Expect(sender.SendEvent("node became unavailable", "body", map[string]string{"target": "a"})).To(Succeed())
+		Expect(events.events).To(Equal([]string{"node became unavailable: body"}))
+		Expect(events.tags).To(Equal([]map[string]string{{"target": "a"}}))
+	})
+})
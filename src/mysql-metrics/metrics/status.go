@@ -0,0 +1,161 @@
+package metrics
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/mysql-metrics/innodbstatus"
+)
+
+// Status describes the most recent cycles of a Processor.
+type Status struct {
+	// Healthy is false once the last unhealthy_cycles cycles all failed. A
+	// cycle fails when the database is unavailable, a collector returns an
+	// error or a metric cannot be sent.
+	Healthy             bool                       `json:"healthy"`
+	ConsecutiveFailures int                        `json:"consecutive_failed_cycles"`
+	LastCycle           time.Time                  `json:"last_cycle"`
+	LastCycleDuration   float64                    `json:"last_cycle_duration_ms"`
+	LastError           string                     `json:"last_error,omitempty"`
+	DatabaseAvailable   bool                       `json:"database_available"`
+	EnabledCollectors   []string                   `json:"enabled_collectors"`
+	Collectors          map[string]CollectorStatus `json:"collectors"`
+	Values              map[string]float64         `json:"values"`
+	// InnoDBStatus is the latest parsed SHOW ENGINE INNODB STATUS, with the
+	// transactions of the latest deadlock, while innodb_status is enabled.
+	InnoDBStatus *innodbstatus.Status `json:"innodb_status,omitempty"`
+}
+
+type CollectorStatus struct {
+	Errors    int    `json:"errors"`
+	LastError string `json:"last_error,omitempty"`
+	TimedOut  bool   `json:"timed_out"`
+}
+
+// telemetry is the state behind the metrics the processor emits about itself
+// and its Status, which is read concurrently with the cycles that update it.
+type telemetry struct {
+	mu               sync.Mutex
+	lastDatabaseRead time.Time
+	status           Status
+	values           map[string]statusValue
+}
+
+// statusValue is the last value of a metric and the start of the cycle it was
+// collected in.
+type statusValue struct {
+	value     float64
+	collected time.Time
+}
+
+func newTelemetry(now time.Time) *telemetry {
+	return &telemetry{
+		lastDatabaseRead: now,
+		status: Status{
+			Healthy:    true,
+			Collectors: make(map[string]CollectorStatus),
+		},
+		values: make(map[string]statusValue),
+	}
+}
+
+// cycleReport is the outcome of a single cycle of the processor.
+type cycleReport struct {
+	start             time.Time
+	databaseAvailable bool
+	collectors        []Collector
+	results           []collectorResult
+	values            map[string]float64
+	err               error
+	sendFailed        bool
+}
+
+func (t *telemetry) databaseRead(at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastDatabaseRead = at
+}
+
+func (t *telemetry) sinceDatabaseRead(now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return now.Sub(t.lastDatabaseRead)
+}
+
+// collectorFailed adds the errors of a collector's run and returns how many it
+// has returned in total.
+func (t *telemetry) collectorFailed(name string, err error) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	collector := t.status.Collectors[name]
+	collector.Errors += countErrors(err)
+	t.status.Collectors[name] = collector
+
+	return collector.Errors
+}
+
+// record updates the status with the outcome of a cycle. The values of metrics
+// that were not collected for longer than valueExpiry are dropped, so that the
+// values of a disabled collector do not stay in the status forever.
+func (t *telemetry) record(report cycleReport, unhealthyCycles int, valueExpiry time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := &t.status
+	status.LastCycle = report.start
+	status.LastCycleDuration = float64(time.Since(report.start)) / float64(time.Millisecond)
+	status.DatabaseAvailable = report.databaseAvailable
+
+	status.LastError = ""
+	if report.err != nil {
+		status.LastError = report.err.Error()
+	}
+
+	status.EnabledCollectors = make([]string, 0, len(report.collectors))
+	for i, c := range report.collectors {
+		name := c.Name()
+		status.EnabledCollectors = append(status.EnabledCollectors, name)
+
+		collector := status.Collectors[name]
+		collector.LastError = ""
+		if report.results[i].err != nil {
+			collector.LastError = report.results[i].err.Error()
+		}
+		collector.TimedOut = report.results[i].timedOut
+		status.Collectors[name] = collector
+	}
+
+	for key, value := range report.values {
+		t.values[key] = statusValue{value: value, collected: report.start}
+	}
+	maps.DeleteFunc(t.values, func(_ string, value statusValue) bool {
+		return report.start.Sub(value.collected) > valueExpiry
+	})
+
+	if report.err != nil || !report.databaseAvailable || report.sendFailed {
+		status.ConsecutiveFailures++
+	} else {
+		status.ConsecutiveFailures = 0
+	}
+	status.Healthy = status.ConsecutiveFailures < unhealthyCycles
+}
+
+func (t *telemetry) snapshot() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := t.status
+	status.EnabledCollectors = slices.Clone(t.status.EnabledCollectors)
+	status.Collectors = maps.Clone(t.status.Collectors)
+	status.Values = make(map[string]float64, len(t.values))
+	for key, value := range t.values {
+		status.Values[key] = value.value
+	}
+
+	return status
+}
@@ -1,10 +1,15 @@
 package metrics
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Logger
 type Logger interface {
 	Debug(string, map[string]interface{})
+	Info(string, map[string]interface{})
 	Error(string, error)
 }
 
@@ -13,14 +18,28 @@ type Writer interface {
 	Write(metric []*Metric) error
 }
 
+// SenderFailureCounter is implemented by writers that count how often sending
+// metrics failed.
+type SenderFailureCounter interface {
+	SenderFailures() int64
+}
+
 type MetricWriter struct {
-	sender Sender
-	logger Logger
-	origin string
+	sender         Sender
+	logger         Logger
+	origin         string
+	senderFailures atomic.Int64
 }
 
 func NewMetricWriter(sender Sender, logger Logger, origin string) *MetricWriter {
-	return &MetricWriter{sender, logger, origin}
+	return &MetricWriter{sender: sender, logger: logger, origin: origin}
+}
+
+// SenderFailures returns the number of metrics that could not be sent, and of
+// flushes that failed, since the writer was created. A flush that failed to
+// send only some of its values counts each of those values.
+func (writer *MetricWriter) SenderFailures() int64 {
+	return writer.senderFailures.Load()
 }
 
 func (writer *MetricWriter) Write(metrics []*Metric) error {
@@ -32,11 +51,56 @@ func (writer *MetricWriter) Write(metrics []*Metric) error {
 		} else {
 			writer.logger.Debug("Emitted metric", map[string]interface{}{"metric": metric})
 			keyWithOrigin := fmt.Sprintf("/%s/%s", writer.origin, metric.Key)
-			err := writer.sender.SendValue(keyWithOrigin, metric.Value, metric.Unit)
+			err := writer.sender.SendValue(keyWithOrigin, metric.Value, metric.Unit, metric.Tags)
 			if err != nil {
+				writer.senderFailures.Add(1)
 				writer.logger.Error("Error calling metrics sender", err)
 			}
 		}
 	}
+
+	if flusher, ok := writer.sender.(Flusher); ok {
+		if err := flusher.Flush(); err != nil {
+			var flushErr *FlushError
+			if errors.As(err, &flushErr) {
+				writer.senderFailures.Add(int64(flushErr.Failed))
+			} else {
+				writer.senderFailures.Add(1)
+			}
+			writer.logger.Error("Error flushing metrics sender", err)
+		}
+	}
+
+	return nil
+}
+
+// WriteEvents logs every event and sends it to the sender when the sender can
+// emit events. An event that could not be sent counts as a sender failure.
+func (writer *MetricWriter) WriteEvents(events []*Event) error {
+	eventSender, sendsEvents := writer.sender.(EventSender)
+
+	for _, event := range events {
+		keyWithOrigin := fmt.Sprintf("/%s/%s", writer.origin, event.Key)
+		writer.logger.Info("State changed", map[string]interface{}{
+			"key":       keyWithOrigin,
+			"title":     event.Title,
+			"old_value": event.OldValue,
+			"new_value": event.NewValue,
+			"old_state": event.OldState,
+			"new_state": event.NewState,
+			"tags":      event.Tags,
+		})
+
+		if !sendsEvents {
+			continue
+		}
+
+		body := fmt.Sprintf("%s changed from %s to %s", keyWithOrigin, event.OldState, event.NewState)
+		if err := eventSender.SendEvent(event.Title, body, event.Tags); err != nil {
+			writer.senderFailures.Add(1)
+			writer.logger.Error("Error sending event", err)
+		}
+	}
+
 	return nil
 }
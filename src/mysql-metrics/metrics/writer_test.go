@@ -45,6 +45,7 @@ var _ = Describe("MetricWriter", func() {
 			Expect(metricFromDebug).To(Equal(metric))
 
 			Expect(fakeLogger.ErrorCallCount()).To(Equal(0))
+			Expect(metricWriter.SenderFailures()).To(BeZero())
 		})
 	})
 
@@ -69,15 +70,17 @@ var _ = Describe("MetricWriter", func() {
 
 			Expect(fakeSender.SendValueCallCount()).To(Equal(2))
 
-			keyArg, valueArg, unitArg := fakeSender.SendValueArgsForCall(0)
+			keyArg, valueArg, unitArg, tagsArg := fakeSender.SendValueArgsForCall(0)
 			Expect(keyArg).To(Equal(fmt.Sprintf("/%s/%s", origin, key1)))
 			Expect(valueArg).To(Equal(value1))
 			Expect(unitArg).To(Equal(unit1))
+			Expect(tagsArg).To(BeNil())
 
-			keyArg, valueArg, unitArg = fakeSender.SendValueArgsForCall(1)
+			keyArg, valueArg, unitArg, tagsArg = fakeSender.SendValueArgsForCall(1)
 			Expect(keyArg).To(Equal(fmt.Sprintf("/%s/%s", origin, key2)))
 			Expect(valueArg).To(Equal(value2))
 			Expect(unitArg).To(Equal(unit2))
+			Expect(tagsArg).To(BeNil())
 
 			Expect(fakeLogger.DebugCallCount()).To(Equal(2))
 
@@ -94,6 +97,18 @@ var _ = Describe("MetricWriter", func() {
 			Expect(fakeLogger.ErrorCallCount()).To(Equal(0))
 		})
 
+		It("sends every metric with its tags", func() {
+			fakeSender := new(metricsfakes.FakeSender)
+			metricWriter = metrics.NewMetricWriter(fakeSender, new(metricsfakes.FakeLogger), origin)
+
+			tags := map[string]string{"instance_id": "vm-123456"}
+			Expect(metricWriter.Write([]*metrics.Metric{{Key: "available", Value: 1, Unit: "boolean", Tags: tags}})).To(Succeed())
+
+			Expect(fakeSender.SendValueCallCount()).To(Equal(1))
+			_, _, _, tagsArg := fakeSender.SendValueArgsForCall(0)
+			Expect(tagsArg).To(Equal(tags))
+		})
+
 		Describe("when the sender errors", func() {
 			It("log.debug's the metric, but logs an error", func() {
 				fakeSender := new(metricsfakes.FakeSender)
@@ -122,7 +137,147 @@ var _ = Describe("MetricWriter", func() {
 				errorMessage, errorErr := fakeLogger.ErrorArgsForCall(0)
 				Expect(errorMessage).To(Equal("Error calling metrics sender"))
 				Expect(errorErr).To(Equal(dropsondeError))
+
+				Expect(metricWriter.SenderFailures()).To(Equal(int64(1)))
+				Expect(metricWriter.Write([]*metrics.Metric{metric, metric})).To(Succeed())
+				Expect(metricWriter.SenderFailures()).To(Equal(int64(3)))
 			})
 		})
 	})
+
+	Describe("when the sender batches values", func() {
+		var (
+			fakeSender *flushingSender
+			fakeLogger *metricsfakes.FakeLogger
+		)
+
+		BeforeEach(func() {
+			fakeSender = &flushingSender{}
+			fakeLogger = new(metricsfakes.FakeLogger)
+			metricWriter = metrics.NewMetricWriter(fakeSender, fakeLogger, origin)
+		})
+
+		It("flushes the sender once every metric was sent", func() {
+			metric := &metrics.Metric{Key: "metrics-key", Value: 1, Unit: "unit", RawValue: "1"}
+
+			err := metricWriter.Write([]*metrics.Metric{metric, metric})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeSender.SendValueCallCount()).To(Equal(2))
+			Expect(fakeSender.flushCount).To(Equal(1))
+			Expect(fakeLogger.ErrorCallCount()).To(Equal(0))
+		})
+
+		It("logs flush errors", func() {
+			fakeSender.flushErr = errors.New("flush broke")
+
+			err := metricWriter.Write([]*metrics.Metric{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLogger.ErrorCallCount()).To(Equal(1))
+			errorMessage, errorErr := fakeLogger.ErrorArgsForCall(0)
+			Expect(errorMessage).To(Equal("Error flushing metrics sender"))
+			Expect(errorErr).To(MatchError("flush broke"))
+			Expect(metricWriter.SenderFailures()).To(Equal(int64(1)))
+		})
+
+		It("counts every value a flush failed to send", func() {
+			fakeSender.flushErr = &metrics.FlushError{Failed: 3, Total: 5, Err: errors.New("flush broke")}
+
+			err := metricWriter.Write([]*metrics.Metric{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeLogger.ErrorCallCount()).To(Equal(1))
+			Expect(metricWriter.SenderFailures()).To(Equal(int64(3)))
+		})
+	})
+
+	Describe("WriteEvents()", func() {
+		var (
+			fakeLogger *metricsfakes.FakeLogger
+			event      *metrics.Event
+		)
+
+		BeforeEach(func() {
+			fakeLogger = new(metricsfakes.FakeLogger)
+			event = &metrics.Event{
+				Key:      "galera/wsrep_cluster_status",
+				Title:    "node left Primary component",
+				OldValue: 1,
+				NewValue: 0,
+				OldState: "PRIMARY",
+				NewState: "NON_PRIMARY",
+				Tags:     map[string]string{"az": "z1"},
+			}
+		})
+
+		It("logs every event", func() {
+			metricWriter = metrics.NewMetricWriter(new(metricsfakes.FakeSender), fakeLogger, origin)
+
+			Expect(metricWriter.WriteEvents([]*metrics.Event{event})).To(Succeed())
+
+			Expect(fakeLogger.InfoCallCount()).To(Equal(1))
+			message, data := fakeLogger.InfoArgsForCall(0)
+			Expect(message).To(Equal("State changed"))
+			Expect(data).To(Equal(map[string]interface{}{
+				"key":       "/somewhere-nice/galera/wsrep_cluster_status",
+				"title":     "node left Primary component",
+				"old_value": 1.0,
+				"new_value": 0.0,
+				"old_state": "PRIMARY",
+				"new_state": "NON_PRIMARY",
+				"tags":      map[string]string{"az": "z1"},
+			}))
+		})
+
+		It("sends every event to senders that emit events", func() {
+			fakeSender := &eventSender{}
+			metricWriter = metrics.NewMetricWriter(fakeSender, fakeLogger, origin)
+
+			Expect(metricWriter.WriteEvents([]*metrics.Event{event})).To(Succeed())
+
+			Expect(fakeSender.events).To(Equal([]string{
+				"node left Primary component: /somewhere-nice/galera/wsrep_cluster_status changed from PRIMARY to NON_PRIMARY",
+			}))
+			Expect(fakeSender.tags).To(Equal([]map[string]string{{"az": "z1"}}))
+			Expect(fakeLogger.InfoCallCount()).To(Equal(1))
+		})
+
+		It("counts events that could not be sent as sender failures", func() {
+			fakeSender := &eventSender{err: errors.New("agent unavailable")}
+			metricWriter = metrics.NewMetricWriter(fakeSender, fakeLogger, origin)
+
+			Expect(metricWriter.WriteEvents([]*metrics.Event{event, event})).To(Succeed())
+
+			Expect(fakeLogger.ErrorCallCount()).To(Equal(2))
+			errorMessage, errorErr := fakeLogger.ErrorArgsForCall(0)
+			Expect(errorMessage).To(Equal("Error sending event"))
+			Expect(errorErr).To(MatchError("agent unavailable"))
+			Expect(metricWriter.SenderFailures()).To(Equal(int64(2)))
+		})
+	})
 })
+
+type flushingSender struct {
+	metricsfakes.FakeSender
+	flushCount int
+	flushErr   error
+}
+
+func (s *flushingSender) Flush() error {
+	s.flushCount++
+	return s.flushErr
+}
+
+type eventSender struct {
+	metricsfakes.FakeSender
+	events []string
+	tags   []map[string]string
+	err    error
+}
+
+func (s *eventSender) SendEvent(title, body string, tags map[string]string) error {
+	s.events = append(s.events, title+": "+body)
+	s.tags = append(s.tags, tags)
+	return s.err
+}
@@ -6,96 +6,51 @@ import (
 	"errors"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/cloudfoundry/mysql-metrics/metrics"
 )
 
-type MetricsComputer struct {
-	metricMappingConfig metrics.MetricMappingConfig
-}
-
-func NewMetricsComputer(metricMappingConfig metrics.MetricMappingConfig) *MetricsComputer {
-	return &MetricsComputer{
-		metricMappingConfig: metricMappingConfig,
-	}
-}
-
-func (mc *MetricsComputer) ComputeIsFollowerMetric(isFollower bool) *metrics.Metric {
-	isFollowerAsFloat := 0.0
-	if isFollower {
-		isFollowerAsFloat = 1.0
-	}
-	key := mc.metricMappingConfig.LeaderFollowerMetricMappings["is_follower"].Key
-	unit := mc.metricMappingConfig.LeaderFollowerMetricMappings["is_follower"].Unit
-
-	return &metrics.Metric{Key: key, Value: isFollowerAsFloat, Unit: unit}
-}
-
-func (mc *MetricsComputer) ComputeAvailabilityMetric(isAvailable bool) *metrics.Metric {
-	availableAsFloat := 0.0
-	if isAvailable {
-		availableAsFloat = 1.0
-	}
-	key := mc.metricMappingConfig.MysqlMetricMappings["available"].Key
-	unit := mc.metricMappingConfig.MysqlMetricMappings["available"].Unit
-
-	return &metrics.Metric{Key: key, Value: availableAsFloat, Unit: unit}
-}
-
-func (mc *MetricsComputer) ComputeLeaderFollowerMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.LeaderFollowerMetricMappings)
-}
+type MetricsComputer struct{}
 
-func (mc *MetricsComputer) ComputeGlobalMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.MysqlMetricMappings)
-}
-
-func (mc *MetricsComputer) ComputeDiskMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.DiskUsageMetricMappings)
-}
-
-func (mc *MetricsComputer) ComputeDiskPerformanceMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.DiskPerformanceMetricMappings)
-}
-
-func (mc *MetricsComputer) ComputeGaleraMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.GaleraMetricMappings)
-}
-
-func (mc *MetricsComputer) ComputeBrokerMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.BrokerMetricMappings)
-}
-
-func (mc *MetricsComputer) ComputeCPUMetrics(values map[string]string) []*metrics.Metric {
-	return mc.ComputeMetricsFromMapping(values, mc.metricMappingConfig.CPUMetricMappings)
+func NewMetricsComputer() *MetricsComputer {
+	return &MetricsComputer{}
 }
 
 func (mc *MetricsComputer) ComputeMetricsFromMapping(metricValues map[string]string, mappingConfig map[string]metrics.MetricDefinition) []*metrics.Metric {
 	var gatheredMetrics []*metrics.Metric
 	for metricName, mapping := range mappingConfig {
-		rawValue, found := metricValues[metricName]
-		if found {
-			floatValue, err := mc.parseMetricValue(rawValue)
-
-			metric := metrics.Metric{
-				Key:      mapping.Key,
-				Unit:     mapping.Unit,
-				RawValue: rawValue,
-				Value:    floatValue,
-				Error:    err,
-			}
-			gatheredMetrics = append(gatheredMetrics, &metric)
+		gatheredMetrics = mc.appendMetric(gatheredMetrics, metricValues, metricName, mapping)
+
+		if mapping.Kind == metrics.CounterKind {
+			gatheredMetrics = mc.appendMetric(gatheredMetrics, metricValues, metricName+"_delta", metrics.MetricDefinition{
+				Key:  mapping.Key + "_delta",
+				Unit: mapping.Unit,
+			})
+			gatheredMetrics = mc.appendMetric(gatheredMetrics, metricValues, metricName+"_rate", metrics.MetricDefinition{
+				Key:  mapping.Key + "_rate",
+				Unit: mapping.Unit + "_per_second",
+			})
 		}
 	}
 	return gatheredMetrics
 }
 
-func (mc *MetricsComputer) ComputeBackupMetric(backupTimestamp time.Time) *metrics.Metric {
-	backupTimestampSeconds := float64(backupTimestamp.Unix())
-	key := mc.metricMappingConfig.BackupMetricMappings["last_successful_backup"].Key
-	unit := mc.metricMappingConfig.BackupMetricMappings["last_successful_backup"].Unit
-	return &metrics.Metric{Key: key, Value: float64(backupTimestampSeconds), Unit: unit}
+func (mc *MetricsComputer) appendMetric(gatheredMetrics []*metrics.Metric, metricValues map[string]string, metricName string, mapping metrics.MetricDefinition) []*metrics.Metric {
+	rawValue, found := metricValues[metricName]
+	if !found {
+		return gatheredMetrics
+	}
+
+	floatValue, err := mc.parseMetricValue(rawValue)
+
+	metric := metrics.Metric{
+		Key:      mapping.Key,
+		Unit:     mapping.Unit,
+		RawValue: rawValue,
+		Value:    floatValue,
+		Error:    err,
+	}
+	return append(gatheredMetrics, &metric)
 }
 
 func (mc *MetricsComputer) parseMetricValue(rawValue string) (float64, error) {
@@ -1,8 +1,6 @@
 package metrics_computer_test
 
 import (
-	"time"
-
 	"github.com/cloudfoundry/mysql-metrics/metrics"
 	"github.com/cloudfoundry/mysql-metrics/metrics_computer"
 
@@ -12,11 +10,10 @@ import (
 
 var _ = Describe("MetricsComputer", func() {
 	var (
-		metricsComputer     *metrics_computer.MetricsComputer
-		metricMappingConfig metrics.MetricMappingConfig
-		metricMappings      map[string]metrics.MetricDefinition
-		values              map[string]string
-		computedMetrics     []*metrics.Metric
+		metricsComputer *metrics_computer.MetricsComputer
+		metricMappings  map[string]metrics.MetricDefinition
+		values          map[string]string
+		computedMetrics []*metrics.Metric
 	)
 
 	Describe("ComputeMetricsFromMapping", func() {
@@ -25,9 +22,7 @@ var _ = Describe("MetricsComputer", func() {
 				"matchingValue": {Key: "/test_metric", Unit: "testUnit"},
 			}
 
-			metricMappingConfig = metrics.MetricMappingConfig{}
-
-			metricsComputer = metrics_computer.NewMetricsComputer(metricMappingConfig)
+			metricsComputer = metrics_computer.NewMetricsComputer()
 		})
 
 		Context("When the value is a float", func() {
@@ -139,211 +134,35 @@ var _ = Describe("MetricsComputer", func() {
 				Expect(computedMetrics[1].Error).To(MatchError("could not convert raw value"))
 			})
 		})
-	})
-
-	Describe("ComputeAvailabilityMetric", func() {
-		var (
-			mysqlMetricMappings map[string]metrics.MetricDefinition
-		)
-
-		BeforeEach(func() {
-			mysqlMetricMappings = map[string]metrics.MetricDefinition{
-				"available": {Key: "/p.mysql/available", Unit: "testUnit"},
-			}
-			metricMappingConfig = metrics.MetricMappingConfig{
-				MysqlMetricMappings: mysqlMetricMappings,
-			}
-			metricsComputer = metrics_computer.NewMetricsComputer(metricMappingConfig)
-		})
-
-		It("returns an availability metric with the given boolean", func() {
-			availabilityMetric := metricsComputer.ComputeAvailabilityMetric(true)
-			Expect(availabilityMetric.Key).To(Equal("/p.mysql/available"))
-			Expect(availabilityMetric.Unit).To(Equal("testUnit"))
-			Expect(availabilityMetric.Value).To(Equal(1.0))
-
-			availabilityMetric = metricsComputer.ComputeAvailabilityMetric(false)
-			Expect(availabilityMetric.Key).To(Equal("/p.mysql/available"))
-			Expect(availabilityMetric.Unit).To(Equal("testUnit"))
-			Expect(availabilityMetric.Value).To(Equal(0.0))
-		})
-	})
-
-	Describe("ComputeIsFollowerMetric", func() {
-		var (
-			leaderFollowerMetricMappings map[string]metrics.MetricDefinition
-		)
-
-		BeforeEach(func() {
-			leaderFollowerMetricMappings = map[string]metrics.MetricDefinition{
-				"is_follower": {Key: "/p.mysql/follower/is_follower", Unit: "testUnit"},
-			}
-			metricMappingConfig = metrics.MetricMappingConfig{
-				LeaderFollowerMetricMappings: leaderFollowerMetricMappings,
-			}
-			metricsComputer = metrics_computer.NewMetricsComputer(metricMappingConfig)
-		})
-
-		It("returns an is_follower metric with the given boolean", func() {
-			isFollowerMetric := metricsComputer.ComputeIsFollowerMetric(true)
-			Expect(isFollowerMetric.Key).To(Equal("/p.mysql/follower/is_follower"))
-			Expect(isFollowerMetric.Unit).To(Equal("testUnit"))
-			Expect(isFollowerMetric.Value).To(Equal(1.0))
-
-			isFollowerMetric = metricsComputer.ComputeIsFollowerMetric(false)
-			Expect(isFollowerMetric.Key).To(Equal("/p.mysql/follower/is_follower"))
-			Expect(isFollowerMetric.Unit).To(Equal("testUnit"))
-			Expect(isFollowerMetric.Value).To(Equal(0.0))
-		})
-	})
-
-	Describe("ComputeBackupMetric", func() {
-		var (
-			backupMetricMappings map[string]metrics.MetricDefinition
-		)
-
-		BeforeEach(func() {
-			backupMetricMappings = map[string]metrics.MetricDefinition{
-				"last_successful_backup": {Key: "last_successful_backup", Unit: "seconds"},
-			}
-			metricMappingConfig = metrics.MetricMappingConfig{
-				BackupMetricMappings: backupMetricMappings,
-			}
-			metricsComputer = metrics_computer.NewMetricsComputer(metricMappingConfig)
-		})
-
-		It("returns a last_backup_taken metric float seconds", func() {
-			now := time.Now()
-			nowMetricValue := float64(now.Unix())
-			backupMetric := metricsComputer.ComputeBackupMetric(now)
-
-			Expect(backupMetric.Key).To(Equal("last_successful_backup"))
-			Expect(backupMetric.Unit).To(Equal("seconds"))
-			Expect(backupMetric.Value).To(Equal(nowMetricValue))
-		})
-	})
-
-	Describe("All other metrics", func() {
-		var (
-			mysqlMetricMappings          map[string]metrics.MetricDefinition
-			galeraMetricMappings         map[string]metrics.MetricDefinition
-			leaderFollowerMetricMappings map[string]metrics.MetricDefinition
-			diskMetricMappings           map[string]metrics.MetricDefinition
-			brokerMetricMappings         map[string]metrics.MetricDefinition
-			cpuMetricMappings            map[string]metrics.MetricDefinition
-		)
-
-		BeforeEach(func() {
-			mysqlMetricMappings = map[string]metrics.MetricDefinition{
-				"mysql_metric_key": {Key: "/p.mysql/mysql_metric_name", Unit: "testUnit"},
-			}
-
-			galeraMetricMappings = map[string]metrics.MetricDefinition{
-				"galera_metric_key": {Key: "/p.mysql/galera_metric_name", Unit: "testUnit"},
-			}
-
-			leaderFollowerMetricMappings = map[string]metrics.MetricDefinition{
-				"leader_follower_metric_key": {Key: "/p.mysql/leader_follower_metric_name", Unit: "testUnit"},
-			}
-
-			diskMetricMappings = map[string]metrics.MetricDefinition{
-				"disk_metric_key": {Key: "/p.mysql/disk_metric_name", Unit: "testUnit"},
-			}
-
-			brokerMetricMappings = map[string]metrics.MetricDefinition{
-				"broker_metric_key": {Key: "/p.mysql/broker_metric_name", Unit: "testUnit"},
-			}
-
-			cpuMetricMappings = map[string]metrics.MetricDefinition{
-				"cpu_metric_key": {Key: "/p.mysql/cpu_metric_name", Unit: "testUnit"},
-			}
-
-			metricMappingConfig = metrics.MetricMappingConfig{
-				MysqlMetricMappings:          mysqlMetricMappings,
-				GaleraMetricMappings:         galeraMetricMappings,
-				LeaderFollowerMetricMappings: leaderFollowerMetricMappings,
-				DiskUsageMetricMappings:      diskMetricMappings,
-				BrokerMetricMappings:         brokerMetricMappings,
-				CPUMetricMappings:            cpuMetricMappings,
-			}
-			metricsComputer = metrics_computer.NewMetricsComputer(metricMappingConfig)
-		})
-
-		Describe("ComputeLeaderFollowerMetrics", func() {
-			It("Calls through to ComputeMetricsFromMapping", func() {
-				values = map[string]string{"leader_follower_metric_key": "123.0"}
-				computedMetrics = metricsComputer.ComputeLeaderFollowerMetrics(values)
-
-				Expect(len(computedMetrics)).To(Equal(1))
-				Expect(computedMetrics[0].Key).To(Equal("/p.mysql/leader_follower_metric_name"))
-				Expect(computedMetrics[0].Unit).To(Equal("testUnit"))
-				Expect(computedMetrics[0].Value).To(Equal(123.0))
-				Expect(computedMetrics[0].Error).To(BeNil())
-			})
-		})
-
-		Describe("ComputeGlobalMetrics", func() {
-			It("Calls through to ComputeMetricsFromMapping", func() {
-				values = map[string]string{"mysql_metric_key": "123.0"}
-				computedMetrics = metricsComputer.ComputeGlobalMetrics(values)
-
-				Expect(len(computedMetrics)).To(Equal(1))
-				Expect(computedMetrics[0].Key).To(Equal("/p.mysql/mysql_metric_name"))
-				Expect(computedMetrics[0].Unit).To(Equal("testUnit"))
-				Expect(computedMetrics[0].Value).To(Equal(123.0))
-				Expect(computedMetrics[0].Error).To(BeNil())
-			})
-		})
-
-		Describe("ComputeDiskMetrics", func() {
-			It("Calls through to ComputeMetricsFromMapping", func() {
-				values = map[string]string{"disk_metric_key": "123.0"}
-				computedMetrics = metricsComputer.ComputeDiskMetrics(values)
-
-				Expect(len(computedMetrics)).To(Equal(1))
-				Expect(computedMetrics[0].Key).To(Equal("/p.mysql/disk_metric_name"))
-				Expect(computedMetrics[0].Unit).To(Equal("testUnit"))
-				Expect(computedMetrics[0].Value).To(Equal(123.0))
-				Expect(computedMetrics[0].Error).To(BeNil())
-			})
-		})
-
-		Describe("ComputeGaleraMetrics", func() {
-			It("Calls through to ComputeMetricsFromMapping", func() {
-				values = map[string]string{"galera_metric_key": "123.0"}
-				computedMetrics = metricsComputer.ComputeGaleraMetrics(values)
-
-				Expect(len(computedMetrics)).To(Equal(1))
-				Expect(computedMetrics[0].Key).To(Equal("/p.mysql/galera_metric_name"))
-				Expect(computedMetrics[0].Unit).To(Equal("testUnit"))
-				Expect(computedMetrics[0].Value).To(Equal(123.0))
-				Expect(computedMetrics[0].Error).To(BeNil())
+		Describe("counters", func() {
+			BeforeEach(func() {
+				metricMappings = map[string]metrics.MetricDefinition{
+					"com_select": {Key: "performance/com_select", Unit: "query", Kind: metrics.CounterKind},
+				}
 			})
-		})
 
-		Describe("ComputeBrokerMetrics", func() {
-			It("Calls through to ComputeMetricsFromMapping", func() {
-				values = map[string]string{"broker_metric_key": "123.0"}
-				computedMetrics = metricsComputer.ComputeBrokerMetrics(values)
+			It("produces delta and rate metrics when their values are present", func() {
+				values = map[string]string{
+					"com_select":       "400",
+					"com_select_delta": "300",
+					"com_select_rate":  "10.00",
+				}
+				computedMetrics = metricsComputer.ComputeMetricsFromMapping(values, metricMappings)
 
-				Expect(len(computedMetrics)).To(Equal(1))
-				Expect(computedMetrics[0].Key).To(Equal("/p.mysql/broker_metric_name"))
-				Expect(computedMetrics[0].Unit).To(Equal("testUnit"))
-				Expect(computedMetrics[0].Value).To(Equal(123.0))
-				Expect(computedMetrics[0].Error).To(BeNil())
+				Expect(computedMetrics).To(ConsistOf(
+					&metrics.Metric{Key: "performance/com_select", Unit: "query", RawValue: "400", Value: 400},
+					&metrics.Metric{Key: "performance/com_select_delta", Unit: "query", RawValue: "300", Value: 300},
+					&metrics.Metric{Key: "performance/com_select_rate", Unit: "query_per_second", RawValue: "10.00", Value: 10},
+				))
 			})
-		})
 
-		Describe("ComputeCPUMetrics", func() {
-			It("Calls through to ComputeMetricsFromMapping", func() {
-				values = map[string]string{"cpu_metric_key": "123.0"}
-				computedMetrics = metricsComputer.ComputeCPUMetrics(values)
+			It("produces only the total on the first sample", func() {
+				values = map[string]string{"com_select": "400"}
+				computedMetrics = metricsComputer.ComputeMetricsFromMapping(values, metricMappings)
 
-				Expect(len(computedMetrics)).To(Equal(1))
-				Expect(computedMetrics[0].Key).To(Equal("/p.mysql/cpu_metric_name"))
-				Expect(computedMetrics[0].Unit).To(Equal("testUnit"))
-				Expect(computedMetrics[0].Value).To(Equal(123.0))
-				Expect(computedMetrics[0].Error).To(BeNil())
+				Expect(computedMetrics).To(ConsistOf(
+					&metrics.Metric{Key: "performance/com_select", Unit: "query", RawValue: "400", Value: 400},
+				))
 			})
 		})
 	})
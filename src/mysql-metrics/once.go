@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+)
+
+// runOnce runs a single cycle of every target and returns the exit code of the
+// -once mode, which fails when a cycle failed. With an output format, the
+// metrics of every target are printed to stdout once all cycles completed.
+func runOnce(targets []*target, outputs []*metrics.OutputWriter, format string, sampleInterval time.Duration, logger lager.Logger) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		exitCode int
+	)
+	for _, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := t.once(ctx, sampleInterval); err != nil {
+				t.logger.Error("error processing metrics", err)
+				mu.Lock()
+				exitCode = 1
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, t := range targets {
+		t.close()
+	}
+
+	if format != "" {
+		var collected []*metrics.Metric
+		for _, output := range outputs {
+			collected = append(collected, output.Metrics()...)
+		}
+		if err := metrics.WriteOutput(os.Stdout, format, collected); err != nil {
+			logger.Error("failed to print metrics", err)
+			return 1
+		}
+	}
+
+	return exitCode
+}
@@ -0,0 +1,88 @@
+// Package status serves the health and status of mysql-metrics over HTTP.
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+)
+
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 . Source
+type Source interface {
+	Status() metrics.Status
+}
+
+// NewHandler serves /healthz, which fails once the source is unhealthy, and
+// /status, which describes the source as JSON.
+func NewHandler(source Source) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		status := source.Status()
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "unhealthy: the last %d cycles failed: %s\n", status.ConsecutiveFailures, status.LastError)
+			return
+		}
+
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(source.Status())
+	})
+
+	return mux
+}
+
+// NewTargetsHandler serves the same endpoints as NewHandler for several
+// targets, keyed by target name. /healthz fails once any target is unhealthy
+// and /status describes every target.
+func NewTargetsHandler(sources map[string]Source) http.Handler {
+	names := slices.Sorted(maps.Keys(sources))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		var unhealthy []string
+		for _, name := range names {
+			status := sources[name].Status()
+			if !status.Healthy {
+				unhealthy = append(unhealthy, fmt.Sprintf("%s: the last %d cycles failed: %s", name, status.ConsecutiveFailures, status.LastError))
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if len(unhealthy) > 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintf(w, "unhealthy: %s\n", strings.Join(unhealthy, "; "))
+			return
+		}
+
+		fmt.Fprintln(w, "ok")
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		statuses := make(map[string]metrics.Status, len(sources))
+		for name, source := range sources {
+			statuses[name] = source.Status()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(statuses)
+	})
+
+	return mux
+}
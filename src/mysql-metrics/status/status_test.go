@@ -0,0 +1,158 @@
+package status_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/status"
+	"github.com/cloudfoundry/mysql-metrics/status/statusfakes"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		fakeSource *statusfakes.FakeSource
+		server     *httptest.Server
+	)
+
+	BeforeEach(func() {
+		fakeSource = &statusfakes.FakeSource{}
+		server = httptest.NewServer(status.NewHandler(fakeSource))
+		DeferCleanup(server.Close)
+	})
+
+	get := func(path string) (*http.Response, string) {
+		response, err := http.Get(server.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return response, string(body)
+	}
+
+	Describe("/healthz", func() {
+		It("succeeds while the source is healthy", func() {
+			fakeSource.StatusReturns(metrics.Status{Healthy: true})
+
+			response, body := get("/healthz")
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(body).To(Equal("ok\n"))
+		})
+
+		It("fails once the source is unhealthy", func() {
+			fakeSource.StatusReturns(metrics.Status{ConsecutiveFailures: 3, LastError: "connection refused"})
+
+			response, body := get("/healthz")
+			Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+			Expect(body).To(Equal("unhealthy: the last 3 cycles failed: connection refused\n"))
+		})
+	})
+
+	Describe("/status", func() {
+		It("describes the source as JSON", func() {
+			lastCycle := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			fakeSource.StatusReturns(metrics.Status{
+				Healthy:           true,
+				LastCycle:         lastCycle,
+				LastCycleDuration: 12.5,
+				DatabaseAvailable: true,
+				EnabledCollectors: []string{"cpu", "mysql"},
+				Collectors: map[string]metrics.CollectorStatus{
+					"cpu":   {},
+					"mysql": {Errors: 2, LastError: "access denied"},
+				},
+				Values: map[string]float64{"available": 1},
+			})
+
+			response, body := get("/status")
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(response.Header.Get("Content-Type")).To(Equal("application/json"))
+
+			var decoded map[string]any
+			Expect(json.Unmarshal([]byte(body), &decoded)).To(Succeed())
+			Expect(decoded).To(Equal(map[string]any{
+				"healthy":                   true,
+				"consecutive_failed_cycles": 0.0,
+				"last_cycle":                "2024-05-01T12:00:00Z",
+				"last_cycle_duration_ms":    12.5,
+				"database_available":        true,
+				"enabled_collectors":        []any{"cpu", "mysql"},
+				"collectors": map[string]any{
+					"cpu":   map[string]any{"errors": 0.0, "timed_out": false},
+					"mysql": map[string]any{"errors": 2.0, "last_error": "access denied", "timed_out": false},
+				},
+				"values": map[string]any{"available": 1.0},
+			}))
+		})
+	})
+
+	It("only serves GET requests", func() {
+		response, err := http.Post(server.URL+"/status", "text/plain", nil)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
+
+var _ = Describe("TargetsHandler", func() {
+	var (
+		primary *statusfakes.FakeSource
+		replica *statusfakes.FakeSource
+		server  *httptest.Server
+	)
+
+	BeforeEach(func() {
+		primary = &statusfakes.FakeSource{}
+		primary.StatusReturns(metrics.Status{Healthy: true})
+		replica = &statusfakes.FakeSource{}
+		replica.StatusReturns(metrics.Status{Healthy: true})
+
+		server = httptest.NewServer(status.NewTargetsHandler(map[string]status.Source{
+			"primary": primary,
+			"replica": replica,
+		}))
+		DeferCleanup(server.Close)
+	})
+
+	get := func(path string) (*http.Response, string) {
+		response, err := http.Get(server.URL + path)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return response, string(body)
+	}
+
+	It("is healthy while every target is healthy", func() {
+		response, body := get("/healthz")
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(body).To(Equal("ok\n"))
+	})
+
+	It("fails once any target is unhealthy and names it", func() {
+		replica.StatusReturns(metrics.Status{ConsecutiveFailures: 3, LastError: "connection refused"})
+
+		response, body := get("/healthz")
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(body).To(Equal("unhealthy: replica: the last 3 cycles failed: connection refused\n"))
+	})
+
+	It("describes every target by name", func() {
+		replica.StatusReturns(metrics.Status{DatabaseAvailable: true})
+
+		_, body := get("/status")
+
+		var decoded map[string]metrics.Status
+		Expect(json.Unmarshal([]byte(body), &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKeyWithValue("primary", HaveField("Healthy", BeTrue())))
+		Expect(decoded).To(HaveKeyWithValue("replica", HaveField("DatabaseAvailable", BeTrue())))
+	})
+})
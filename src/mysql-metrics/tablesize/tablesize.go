@@ -0,0 +1,200 @@
+// Package tablesize summarises the table sizes reported by
+// information_schema.TABLES per schema and per table.
+package tablesize
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+)
+
+// Table is the size of a single table. FileSize is only known when it was read
+// from information_schema.INNODB_TABLESPACES.
+type Table struct {
+	Schema      string
+	Name        string
+	DataLength  uint64
+	IndexLength uint64
+	DataFree    uint64
+	Rows        uint64
+	FileSize    uint64
+}
+
+// ParseTable converts a row of information_schema.TABLES returned by the
+// database client into a Table. Columns that are NULL, as they are for tables
+// whose statistics are unavailable, are treated as zero.
+func ParseTable(row map[string]string) (Table, error) {
+	table := Table{
+		Schema: row["table_schema"],
+		Name:   row["table_name"],
+	}
+
+	var errs []error
+	for _, column := range []struct {
+		name  string
+		field *uint64
+	}{
+		{"data_length", &table.DataLength},
+		{"index_length", &table.IndexLength},
+		{"data_free", &table.DataFree},
+		{"table_rows", &table.Rows},
+	} {
+		rawValue, ok := row[column.name]
+		if !ok || rawValue == "NULL" {
+			continue
+		}
+
+		value, err := strconv.ParseUint(rawValue, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("table %s.%s: invalid %s %q", table.Schema, table.Name, column.name, rawValue))
+			continue
+		}
+		*column.field = value
+	}
+
+	return table, errors.Join(errs...)
+}
+
+// TotalLength is the space used by the table's data and indexes.
+func (t Table) TotalLength() uint64 {
+	return t.DataLength + t.IndexLength
+}
+
+// Values returns the table as the string map consumed by the metrics computer.
+func (t Table) Values() map[string]string {
+	values := map[string]string{
+		"schema":       t.Schema,
+		"table":        t.Name,
+		"data_length":  strconv.FormatUint(t.DataLength, 10),
+		"index_length": strconv.FormatUint(t.IndexLength, 10),
+		"total_length": strconv.FormatUint(t.TotalLength(), 10),
+		"data_free":    strconv.FormatUint(t.DataFree, 10),
+		"table_rows":   strconv.FormatUint(t.Rows, 10),
+	}
+	if t.FileSize > 0 {
+		values["file_size"] = strconv.FormatUint(t.FileSize, 10)
+	}
+	return values
+}
+
+// Schema is the sum of the sizes of every table in a schema.
+type Schema struct {
+	Name        string
+	Tables      int
+	DataLength  uint64
+	IndexLength uint64
+	DataFree    uint64
+	Rows        uint64
+	FileSize    uint64
+}
+
+// Values returns the schema as the string map consumed by the metrics
+// computer.
+func (s Schema) Values() map[string]string {
+	values := map[string]string{
+		"schema":       s.Name,
+		"tables":       strconv.Itoa(s.Tables),
+		"data_length":  strconv.FormatUint(s.DataLength, 10),
+		"index_length": strconv.FormatUint(s.IndexLength, 10),
+		"total_length": strconv.FormatUint(s.DataLength+s.IndexLength, 10),
+		"data_free":    strconv.FormatUint(s.DataFree, 10),
+		"table_rows":   strconv.FormatUint(s.Rows, 10),
+	}
+	if s.FileSize > 0 {
+		values["file_size"] = strconv.FormatUint(s.FileSize, 10)
+	}
+	return values
+}
+
+// AddFileSizes sets the on-disk size of every table from the rows of
+// information_schema.INNODB_TABLESPACES, whose names have the form
+// "schema/table".
+func AddFileSizes(tables []Table, tablespaces []map[string]string) error {
+	fileSizes := make(map[string]uint64, len(tablespaces))
+
+	var errs []error
+	for _, tablespace := range tablespaces {
+		fileSize, err := strconv.ParseUint(tablespace["file_size"], 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tablespace %s: invalid file_size %q", tablespace["name"], tablespace["file_size"]))
+			continue
+		}
+		fileSizes[tablespace["name"]] = fileSize
+	}
+
+	for i := range tables {
+		tables[i].FileSize = fileSizes[tables[i].Schema+"/"+tables[i].Name]
+	}
+
+	return errors.Join(errs...)
+}
+
+// Filter returns the tables whose schema matches one of the include patterns,
+// or any schema when there are none, and none of the exclude patterns.
+// Patterns use path.Match syntax.
+func Filter(tables []Table, include, exclude []string) []Table {
+	var filtered []Table
+	for _, table := range tables {
+		if len(include) > 0 && !matchesAny(table.Schema, include) {
+			continue
+		}
+		if matchesAny(table.Schema, exclude) {
+			continue
+		}
+		filtered = append(filtered, table)
+	}
+	return filtered
+}
+
+func matchesAny(schema string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, schema); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Summarize sums the tables of every schema, ordered by schema name.
+func Summarize(tables []Table) []Schema {
+	bySchema := make(map[string]*Schema)
+	for _, table := range tables {
+		schema, ok := bySchema[table.Schema]
+		if !ok {
+			schema = &Schema{Name: table.Schema}
+			bySchema[table.Schema] = schema
+		}
+
+		schema.Tables++
+		schema.DataLength += table.DataLength
+		schema.IndexLength += table.IndexLength
+		schema.DataFree += table.DataFree
+		schema.Rows += table.Rows
+		schema.FileSize += table.FileSize
+	}
+
+	schemas := make([]Schema, 0, len(bySchema))
+	for _, schema := range bySchema {
+		schemas = append(schemas, *schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Name < schemas[j].Name
+	})
+	return schemas
+}
+
+// Largest returns at most n tables ordered by the space used by their data
+// and indexes.
+func Largest(tables []Table, n int) []Table {
+	largest := append([]Table(nil), tables...)
+	sort.SliceStable(largest, func(i, j int) bool {
+		return largest[i].TotalLength() > largest[j].TotalLength()
+	})
+
+	if len(largest) > n {
+		largest = largest[:n]
+	}
+	return largest
+}
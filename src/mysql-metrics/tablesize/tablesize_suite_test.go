@@ -0,0 +1,13 @@
+package tablesize_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTablesize(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Tablesize Suite")
+}
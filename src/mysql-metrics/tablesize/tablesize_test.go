@@ -0,0 +1,135 @@
+package tablesize_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/mysql-metrics/tablesize"
+)
+
+var _ = Describe("tablesize", func() {
+	var tables []tablesize.Table
+
+	BeforeEach(func() {
+		tables = []tablesize.Table{
+			{Schema: "app", Name: "users", DataLength: 1000, IndexLength: 500, DataFree: 10, Rows: 20},
+			{Schema: "app", Name: "events", DataLength: 9000, IndexLength: 1000, DataFree: 0, Rows: 300},
+			{Schema: "cf_0123", Name: "widgets", DataLength: 100, IndexLength: 0, DataFree: 5, Rows: 1},
+			{Schema: "mysql", Name: "user", DataLength: 16384, IndexLength: 0, Rows: 4},
+		}
+	})
+
+	Describe("ParseTable", func() {
+		It("parses a row of information_schema.TABLES", func() {
+			table, err := tablesize.ParseTable(map[string]string{
+				"table_schema": "app",
+				"table_name":   "users",
+				"data_length":  "1000",
+				"index_length": "500",
+				"data_free":    "10",
+				"table_rows":   "NULL",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(table).To(Equal(tablesize.Table{Schema: "app", Name: "users", DataLength: 1000, IndexLength: 500, DataFree: 10}))
+		})
+
+		It("reports columns that are not a number", func() {
+			_, err := tablesize.ParseTable(map[string]string{
+				"table_schema": "app",
+				"table_name":   "users",
+				"data_length":  "lots",
+			})
+			Expect(err).To(MatchError(`table app.users: invalid data_length "lots"`))
+		})
+	})
+
+	Describe("AddFileSizes", func() {
+		It("sets the file size of the tables that have a tablespace", func() {
+			err := tablesize.AddFileSizes(tables, []map[string]string{
+				{"name": "app/users", "file_size": "114688"},
+				{"name": "innodb_system", "file_size": "12582912"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tables[0].FileSize).To(Equal(uint64(114688)))
+			Expect(tables[1].FileSize).To(BeZero())
+		})
+
+		It("reports tablespaces with an invalid file size", func() {
+			err := tablesize.AddFileSizes(tables, []map[string]string{{"name": "app/users", "file_size": "NULL"}})
+			Expect(err).To(MatchError(`tablespace app/users: invalid file_size "NULL"`))
+		})
+	})
+
+	Describe("Filter", func() {
+		schemas := func(tables []tablesize.Table) []string {
+			var names []string
+			for _, table := range tables {
+				names = append(names, table.Schema+"."+table.Name)
+			}
+			return names
+		}
+
+		It("keeps every table without patterns", func() {
+			Expect(tablesize.Filter(tables, nil, nil)).To(Equal(tables))
+		})
+
+		It("keeps only the schemas matching an include pattern", func() {
+			Expect(schemas(tablesize.Filter(tables, []string{"cf_*", "app"}, nil))).To(Equal([]string{"app.users", "app.events", "cf_0123.widgets"}))
+		})
+
+		It("drops the schemas matching an exclude pattern", func() {
+			Expect(schemas(tablesize.Filter(tables, nil, []string{"mysql", "cf_*"}))).To(Equal([]string{"app.users", "app.events"}))
+		})
+	})
+
+	Describe("Summarize", func() {
+		It("sums the tables of every schema", func() {
+			Expect(tablesize.Summarize(tables)).To(Equal([]tablesize.Schema{
+				{Name: "app", Tables: 2, DataLength: 10000, IndexLength: 1500, DataFree: 10, Rows: 320},
+				{Name: "cf_0123", Tables: 1, DataLength: 100, DataFree: 5, Rows: 1},
+				{Name: "mysql", Tables: 1, DataLength: 16384, Rows: 4},
+			}))
+		})
+	})
+
+	Describe("Largest", func() {
+		It("returns the largest tables by data and index length", func() {
+			largest := tablesize.Largest(tables, 2)
+			Expect(largest).To(Equal([]tablesize.Table{tables[3], tables[1]}))
+		})
+
+		It("does not modify the tables", func() {
+			tablesize.Largest(tables, 2)
+			Expect(tables[0].Name).To(Equal("users"))
+		})
+	})
+
+	Describe("Values", func() {
+		It("converts a table to metric values", func() {
+			tables[0].FileSize = 114688
+			Expect(tables[0].Values()).To(Equal(map[string]string{
+				"schema":       "app",
+				"table":        "users",
+				"data_length":  "1000",
+				"index_length": "500",
+				"total_length": "1500",
+				"data_free":    "10",
+				"table_rows":   "20",
+				"file_size":    "114688",
+			}))
+		})
+
+		It("converts a schema to metric values", func() {
+			schema := tablesize.Schema{Name: "app", Tables: 2, DataLength: 10000, IndexLength: 1500, DataFree: 10, Rows: 320}
+			Expect(schema.Values()).To(Equal(map[string]string{
+				"schema":       "app",
+				"tables":       "2",
+				"data_length":  "10000",
+				"index_length": "1500",
+				"total_length": "11500",
+				"data_free":    "10",
+				"table_rows":   "320",
+			}))
+		})
+	})
+})
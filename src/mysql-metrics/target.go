@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+
+	"github.com/cloudfoundry/mysql-metrics/alert"
+	"github.com/cloudfoundry/mysql-metrics/config"
+	"github.com/cloudfoundry/mysql-metrics/cpu"
+	"github.com/cloudfoundry/mysql-metrics/database_client"
+	"github.com/cloudfoundry/mysql-metrics/disk"
+	"github.com/cloudfoundry/mysql-metrics/diskstat"
+	"github.com/cloudfoundry/mysql-metrics/emit"
+	"github.com/cloudfoundry/mysql-metrics/gather"
+	"github.com/cloudfoundry/mysql-metrics/mem"
+	"github.com/cloudfoundry/mysql-metrics/metrics"
+	"github.com/cloudfoundry/mysql-metrics/metrics_computer"
+)
+
+// target collects and emits the metrics of one MySQL server. Every target
+// has its own connection, counter state and emitter, so that a server that
+// cannot be reached does not hold up the others.
+type target struct {
+	config    *config.Config
+	dbClient  *database_client.DbClient
+	gatherer  *gather.Gatherer
+	registry  *metrics.Registry
+	processor metrics.Processor
+	emitter   emit.Emitter
+	logger    lager.Logger
+}
+
+// writerFactory returns the writer the metrics of the target configured by
+// cfg are written with.
+type writerFactory func(cfg *config.Config, logger metrics.Logger) metrics.Writer
+
+func newTarget(cfg *config.Config, metricMappingConfig *metrics.MetricMappingConfig, newWriter writerFactory, alertSinks []alert.Sink, metricsLogger lager.Logger) (*target, error) {
+	logger := metricsLogger
+	if cfg.TargetName != "" {
+		logger = metricsLogger.Session("target", lager.Data{"target": cfg.TargetName})
+	}
+
+	conn, err := database_client.Open(cfg)
+	if err != nil {
+		logger.Error("database connection failed to initialize", err)
+		return nil, err
+	}
+	dbClient := database_client.NewDatabaseClient(conn, cfg)
+	stater := disk.NewInfo(syscall.Statfs)
+	procStatFile, err := os.Open("/proc/stat")
+	if err != nil {
+		logger.Error("failed to open /proc/stat", err)
+		return nil, err
+	}
+	cpustater := cpu.New(procStatFile)
+	memstater := mem.New("/proc")
+	monitor, err := diskstat.NewVolumeMonitor()
+	if err != nil {
+		logger.Error("failed to initialize volume monitor", err)
+		return nil, err
+	}
+	gatherer := gather.NewGatherer(dbClient, stater, &cpustater, memstater, monitor, metricMappingConfig.Counters(), time.Now)
+	registry, err := metrics.NewDefaultRegistry(gatherer, metricMappingConfig, cfg)
+	if err != nil {
+		logger.Error("failed to register collectors", err)
+		return nil, err
+	}
+
+	loggerWrapper := lagerLoggerWrapper{logger}
+	metricsComputer := metrics_computer.NewMetricsComputer()
+	metricsWriter := newWriter(cfg, loggerWrapper)
+	processor := metrics.NewProcessor(gatherer, registry, metricsComputer, metricsWriter, cfg, metricMappingConfig, alert.NewEngine(alertSinks...))
+	metricsInterval := time.Duration(cfg.MetricsFrequency) * time.Second
+
+	return &target{
+		config:    cfg,
+		dbClient:  dbClient,
+		gatherer:  gatherer,
+		registry:  registry,
+		processor: processor,
+		emitter:   emit.NewEmitter(processor, metricsInterval, time.After, loggerWrapper),
+		logger:    logger,
+	}, nil
+}
+
+// once runs a single cycle. With a sample interval, another cycle is run that
+// long before it, so that the metrics computed from the difference between two
+// samples, such as rates and CPU utilization, have a value.
+func (t *target) once(ctx context.Context, sampleInterval time.Duration) error {
+	if sampleInterval > 0 {
+		if err := t.process(ctx); err != nil {
+			t.logger.Error("error processing first sample", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(sampleInterval):
+		}
+	}
+
+	return t.process(ctx)
+}
+
+// process runs a cycle, giving it as long as the emitter would.
+func (t *target) process(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(t.config.MetricsFrequency)*time.Second)
+	defer cancel()
+
+	return t.processor.Process(ctx)
+}
+
+// reload applies newConfig to the target. Counter state is only discarded
+// when newConfig points at another server.
+func (t *target) reload(newConfig *config.Config, metricMappingConfig *metrics.MetricMappingConfig) error {
+	registry := t.registry
+	if !t.config.SameCollectors(newConfig) {
+		var err error
+		registry, err = metrics.NewDefaultRegistry(t.gatherer, metricMappingConfig, newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	var conn *sql.DB
+	if !t.config.SameConnection(newConfig) {
+		var err error
+		conn, err = database_client.Open(newConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	t.dbClient.SetConfig(newConfig)
+	if conn != nil {
+		previous := t.dbClient.SwapConnection(conn)
+		if err := previous.Close(); err != nil {
+			t.logger.Error("failed to close previous database connection", err)
+		}
+	}
+	if !t.config.SameServer(newConfig) {
+		t.gatherer.ResetDatabaseCounters()
+	}
+
+	t.processor.Reload(newConfig, registry)
+
+	t.config = newConfig
+	t.registry = registry
+	return nil
+}
+
+func (t *target) close() {
+	if err := t.dbClient.SwapConnection(nil).Close(); err != nil {
+		t.logger.Error("failed to close database connection", err)
+	}
+}
+
+// reloader applies a changed config file to the targets of the running
+// process.
+type reloader struct {
+	config              *config.Config
+	metricMappingConfig *metrics.MetricMappingConfig
+	targets             []*target
+	senders             *senders
+	logger              lager.Logger
+}
+
+func (r *reloader) reload(filepath string) {
+	logger := r.logger.Session("reload", lager.Data{"config": filepath})
+
+	newConfig, err := config.Reload(filepath, r.config)
+	if err != nil {
+		logger.Error("config file was not reloaded", err)
+		return
+	}
+
+	// Reload fails when targets are added or removed, so the targets of
+	// newConfig are in the same order as those running.
+	for i, targetConfig := range newConfig.TargetConfigs() {
+		if err := r.targets[i].reload(targetConfig, r.metricMappingConfig); err != nil {
+			logger.Error("target was not reloaded", err, lager.Data{"target": targetConfig.TargetName})
+		}
+	}
+
+	r.senders.reload(newConfig)
+	r.config = newConfig
+	logger.Info("config file reloaded")
+}